@@ -0,0 +1,7 @@
+package main
+
+import "go-fitness/internal/cmd"
+
+func main() {
+	cmd.Execute()
+}
@@ -0,0 +1,89 @@
+package apperr
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Error is what a service returns for a failure the client should see, Code is stable so clients can branch on it
+// and it is also the message id of the lang files
+type Error struct {
+	Code   string
+	Status int
+	Fields []FieldError
+}
+
+// FieldError is one invalid field of a request, Code is the message id of the field message
+type FieldError struct {
+	Field string
+	Code  string
+	Param string
+}
+
+func (e *Error) Error() string {
+	return e.Code
+}
+
+// Is matches any *Error with the same code, so errors.Is(err, apperr.NotFound("video_not_found")) works
+func (e *Error) Is(target error) bool {
+	var t *Error
+	if !errors.As(target, &t) {
+		return false
+	}
+
+	return t.Code == e.Code
+}
+
+func New(status int, code string) *Error {
+	return &Error{
+		Code:   code,
+		Status: status,
+	}
+}
+
+func BadRequest(code string) *Error {
+	return New(http.StatusBadRequest, code)
+}
+
+func Unauthorized(code string) *Error {
+	return New(http.StatusUnauthorized, code)
+}
+
+func Forbidden(code string) *Error {
+	return New(http.StatusForbidden, code)
+}
+
+func NotFound(code string) *Error {
+	return New(http.StatusNotFound, code)
+}
+
+func Conflict(code string) *Error {
+	return New(http.StatusConflict, code)
+}
+
+func TooLarge(code string) *Error {
+	return New(http.StatusRequestEntityTooLarge, code)
+}
+
+func Internal(code string) *Error {
+	return New(http.StatusInternalServerError, code)
+}
+
+// Invalid is the error of a request that failed validation, one field error per invalid field
+func Invalid(fields ...FieldError) *Error {
+	return &Error{
+		Code:   "invalid_request",
+		Status: http.StatusBadRequest,
+		Fields: fields,
+	}
+}
+
+// From returns the *Error in the chain of err, anything else is an internal_server_error
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	return Internal("internal_server_error")
+}
@@ -1,10 +1,12 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
 	"go-fitness/external/logger/sl"
+	"io/fs"
 	"log/slog"
 	"os"
 	"time"
@@ -18,18 +20,35 @@ type (
 		ENVState   `yaml:"env_state"`
 		DB         `yaml:"db"`
 		Video      `yaml:"video_service"`
-		JWT        string `yaml:"jwt_secret" env:"JWT_SECRET"`
+		Retention  `yaml:"retention"`
+		GC         `yaml:"gc"`
+		Scheduler  `yaml:"scheduler"`
+		Playback   `yaml:"playback"`
+		Exp        `yaml:"exp"`
+		Outbox     `yaml:"outbox"`
+		Tracing    `yaml:"tracing"`
+		AccessLog  `yaml:"access_log"`
+		Log        `yaml:"log"`
+		Health     `yaml:"health"`
+		Lang       `yaml:"lang"`
+		Webhooks   []Webhook `yaml:"webhooks"`
+		JWT        string    `yaml:"jwt_secret" env:"JWT_SECRET" redact:"true"`
 	}
 
 	DB struct {
+		// Driver is mysql or sqlite, sqlite runs the service without a database server
+		Driver          string        `yaml:"driver" env:"DB_DRIVER" env-default:"mysql"`
+		SqlitePath      string        `yaml:"sqlite_path" env:"SQLITE_PATH" env-default:"storage/fitness.db"`
 		MaxOpenConns    int           `yaml:"max_open_conns" env:"MAX_OPEN_CONNS" env-default:"25"`
 		MaxIdleConns    int           `yaml:"max_idle_conns"  env:"MAX_IDLE_CONNS" env-default:"25"`
 		ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"MAX_lifetime_CONNS" env-default:"3m"`
 		MysqlUser       string        `env:"MYSQL_USER" env-default:"root"`
-		MysqlPassword   string        `env:"MYSQL_PASSWORD" env-default:"root"`
+		MysqlPassword   string        `env:"MYSQL_PASSWORD" env-default:"root" redact:"true"`
 		MysqlHost       string        `env:"MYSQL_HOST" env-default:"localhost"`
 		MysqlPort       string        `env:"MYSQL_PORT" env-default:"3306"`
 		MysqlDBName     string        `env:"MYSQL_DBNAME" env-default:"rust"`
+		// MigrateOnStart applies the pending migrations before the server starts
+		MigrateOnStart bool `yaml:"migrate_on_start" env:"DB_MIGRATE_ON_START" env-default:"false"`
 	}
 
 	ENVState struct {
@@ -43,7 +62,7 @@ type (
 		Host    string `yaml:"address" env:"PUSHER_HOST" env-default:"localhost"`
 		Port    string `yaml:"port" env:"PUSHER_PORT" env-default:"8080"`
 		Cluster string `yaml:"cluster" env:"PUSHER_CLUSTER" env-default:"ap1"`
-		Secret  string `yaml:"secret" env:"PUSHER_SECRET"`
+		Secret  string `yaml:"secret" env:"PUSHER_SECRET" redact:"true"`
 		Key     string `yaml:"key" env:"PUSHER_KEY"`
 		Secure  bool   `yaml:"secure" env:"PUSHER_SECURE" env-default:"false"`
 	}
@@ -59,19 +78,135 @@ type (
 		VideoPath                 string   `yaml:"video_path" env:"VIDEO_PATH" env-default:"videos"`
 		TranscodeVideoWorkerCount int      `yaml:"transcode_worker_count" env:"TRANSCODE_WORKER_COUNT" env-default:"1"`
 		Resolutions               []string `yaml:"resolutions" env:"RESOLUTIONS" env-default:"360,480,720,1080"`
+		// ReplacePolicy decides what happens to a video its owner replaced: keep, trash or delete
+		ReplacePolicy string `yaml:"replace_policy" env:"VIDEO_REPLACE_POLICY" env-default:"trash"`
 
 		//Resolutions []string `yaml:"resolutions" env:"RESOLUTIONS" env-default:"360"`
 	}
+
+	Playback struct {
+		// FlushInterval is how often buffered playback positions are written to the database
+		FlushInterval time.Duration `yaml:"flush_interval" env:"PLAYBACK_FLUSH_INTERVAL" env-default:"15s"`
+		// CompleteRatio is the share of the duration after which a video counts as watched
+		CompleteRatio float64 `yaml:"complete_ratio" env:"PLAYBACK_COMPLETE_RATIO" env-default:"0.9"`
+		ContinueLimit int     `yaml:"continue_limit" env:"PLAYBACK_CONTINUE_LIMIT" env-default:"20"`
+	}
+
+	// Exp holds the rules for the experience points awarded for completed workouts
+	Exp struct {
+		WorkoutCompleted int `yaml:"workout_completed" env:"EXP_WORKOUT_COMPLETED" env-default:"100"`
+		// FirstCompletionBonus is added the first time a user completes a workout
+		FirstCompletionBonus int `yaml:"first_completion_bonus" env:"EXP_FIRST_COMPLETION_BONUS" env-default:"50"`
+		// RepeatCooldown is how long after a completion the same workout awards nothing again
+		RepeatCooldown time.Duration `yaml:"repeat_cooldown" env:"EXP_REPEAT_COOLDOWN" env-default:"12h"`
+		// DailyLimit caps the rewarded completions per user per day, 0 means no limit
+		DailyLimit int `yaml:"daily_limit" env:"EXP_DAILY_LIMIT" env-default:"5"`
+	}
+
+	// Outbox controls the background delivery of domain events
+	Outbox struct {
+		PollInterval time.Duration `yaml:"poll_interval" env:"OUTBOX_POLL_INTERVAL" env-default:"2s"`
+		BatchSize    int           `yaml:"batch_size" env:"OUTBOX_BATCH_SIZE" env-default:"50"`
+		// Lease is how long a claimed event stays hidden from other dispatchers
+		Lease        time.Duration `yaml:"lease" env:"OUTBOX_LEASE" env-default:"1m"`
+		MaxAttempts  int           `yaml:"max_attempts" env:"OUTBOX_MAX_ATTEMPTS" env-default:"10"`
+		RetryBackoff time.Duration `yaml:"retry_backoff" env:"OUTBOX_RETRY_BACKOFF" env-default:"5s"`
+		MaxBackoff   time.Duration `yaml:"max_backoff" env:"OUTBOX_MAX_BACKOFF" env-default:"1h"`
+	}
+
+	// Webhook is a subscription of an outside service to domain events, Name identifies it in the delivery log
+	Webhook struct {
+		Name   string   `yaml:"name"`
+		URL    string   `yaml:"url"`
+		Secret string   `yaml:"secret" redact:"true"`
+		Events []string `yaml:"events"`
+		// Timeout of one delivery, 10s when empty
+		Timeout time.Duration `yaml:"timeout"`
+	}
+
+	// Tracing exports OpenTelemetry spans, Exporter is none, stdout or otlp
+	Tracing struct {
+		Exporter string `yaml:"exporter" env:"TRACING_EXPORTER" env-default:"none"`
+		// Endpoint is the url of the OTLP/HTTP collector, http:// sends without TLS
+		Endpoint    string  `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT" env-default:"http://localhost:4318"`
+		ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME" env-default:"go-fitness"`
+		SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" env-default:"1"`
+	}
+
+	// AccessLog logs one line per request, segments are fetched every few seconds per viewer
+	// so only SegmentSampleRatio of the successful ones are logged
+	AccessLog struct {
+		Enabled            bool    `yaml:"enabled" env:"ACCESS_LOG_ENABLED" env-default:"true"`
+		SegmentSampleRatio float64 `yaml:"segment_sample_ratio" env:"ACCESS_LOG_SEGMENT_SAMPLE_RATIO" env-default:"0.01"`
+	}
+
+	// Log adds to the attribute keys and value patterns masked in every log line, see slogredact for the defaults
+	Log struct {
+		RedactKeys     []string `yaml:"redact_keys" env:"LOG_REDACT_KEYS"`
+		RedactPatterns []string `yaml:"redact_patterns"`
+	}
+
+	// Health tunes the checks behind /readyz
+	Health struct {
+		// Timeout bounds every check, a check still running after it fails
+		Timeout time.Duration `yaml:"timeout" env:"HEALTH_TIMEOUT" env-default:"3s"`
+		// MinFreeDiskMB is the free space of the storage path under which the service is not ready
+		MinFreeDiskMB uint64 `yaml:"min_free_disk_mb" env:"HEALTH_MIN_FREE_DISK_MB" env-default:"1024"`
+		// QueueSaturation is the share of the transcode queue in use from which the service is not ready
+		QueueSaturation float64 `yaml:"queue_saturation" env:"HEALTH_QUEUE_SATURATION" env-default:"0.9"`
+	}
+
+	// Lang is where the active.<language>.json message files are read from
+	Lang struct {
+		Dir string `yaml:"dir" env:"LANG_DIR" env-default:"lang"`
+	}
+
+	Retention struct {
+		GracePeriod time.Duration `yaml:"grace_period" env:"RETENTION_GRACE_PERIOD" env-default:"720h"`
+	}
+
+	// GC controls the removal of video folders and rows nothing uses anymore
+	GC struct {
+		// GracePeriod protects folders and rows changed more recently, so uploads in progress are never touched
+		GracePeriod time.Duration `yaml:"grace_period" env:"GC_GRACE_PERIOD" env-default:"24h"`
+		// DryRun makes the scheduled runs only report what they would remove
+		DryRun bool `yaml:"dry_run" env:"GC_DRY_RUN" env-default:"true"`
+	}
+
+	// Scheduler runs the maintenance jobs, Jobs maps a job name to its cron expression
+	// and jobs left out of it only run when triggered by an admin
+	Scheduler struct {
+		Enabled bool              `yaml:"enabled" env:"SCHEDULER_ENABLED" env-default:"true"`
+		Tick    time.Duration     `yaml:"tick" env:"SCHEDULER_TICK" env-default:"30s"`
+		Jobs    map[string]string `yaml:"jobs"`
+	}
 )
 
+// NewConfig loads the config and fails with every problem Validate finds, so fx stops before anything starts
 func NewConfig() (*Config, error) {
-	const op string = "config.NewConfig"
+	cfg, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Load reads .env when there is one and the file at CONFIG_PATH, without validating the result
+func Load() (*Config, error) {
+	const op string = "config.Load"
 
 	log := slog.With(
 		sl.String("op", op),
 	)
 
-	if err := godotenv.Load(".env"); err != nil {
+	// .env is optional, the variables may come from the environment itself.
+	// It never overrides a variable that is already set, so CONFIG_PATH from --config wins
+	if err := godotenv.Load(".env"); err != nil && !errors.Is(err, fs.ErrNotExist) {
 		log.Error("error loading .env file", sl.Err(err))
 		return nil, fmt.Errorf("error loading .env file: %v", err)
 	}
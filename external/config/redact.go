@@ -0,0 +1,43 @@
+package config
+
+import "reflect"
+
+const Redacted = "[REDACTED]"
+
+// Redacted returns a copy of the config with every field tagged redact:"true" masked, for printing
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redact(reflect.ValueOf(&redacted).Elem())
+
+	return &redacted
+}
+
+func redact(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			if field.Tag.Get("redact") == "true" && v.Field(i).Kind() == reflect.String {
+				if v.Field(i).String() != "" {
+					v.Field(i).SetString(Redacted)
+				}
+				continue
+			}
+
+			redact(v.Field(i))
+		}
+	case reflect.Slice:
+		// the copy of the config still shares the backing array with the original
+		elems := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(elems, v)
+		v.Set(elems)
+
+		for i := 0; i < elems.Len(); i++ {
+			redact(elems.Index(i))
+		}
+	}
+}
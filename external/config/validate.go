@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"go-fitness/external/cron"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// validator collects every problem of the config so they are reported at once
+type validator struct {
+	problems []string
+}
+
+func (v *validator) check(ok bool, field string, format string, args ...any) {
+	if !ok {
+		v.problems = append(v.problems, field+": "+fmt.Sprintf(format, args...))
+	}
+}
+
+func (v *validator) positive(d time.Duration, field string) {
+	v.check(d > 0, field, "must be positive, got %s", d)
+}
+
+func (v *validator) ratio(r float64, field string) {
+	v.check(r >= 0 && r <= 1, field, "must be between 0 and 1, got %v", r)
+}
+
+func (v *validator) oneOf(value string, field string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.problems = append(v.problems, fmt.Sprintf("%s: must be one of %s, got %q", field, strings.Join(allowed, ", "), value))
+}
+
+func (v *validator) dir(path string, field string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		v.problems = append(v.problems, fmt.Sprintf("%s: %s does not exist", field, path))
+		return
+	}
+	v.check(info.IsDir(), field, "%s is not a directory", path)
+}
+
+func (v *validator) httpURL(raw string, field string) {
+	u, err := url.Parse(raw)
+	v.check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", field, "must be an http or https url, got %q", raw)
+}
+
+// Validate checks the required fields, value ranges and paths of the config and normalizes the resolution list.
+// The error lists every problem found, one per line
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	v.oneOf(c.Env, "env", c.ENVState.Local, c.ENVState.Dev, c.ENVState.Prod)
+	v.check(c.JWT != "", "jwt_secret", "is required (JWT_SECRET)")
+
+	v.check(c.HTTPServer.ApiPort != "", "http_server.api_port", "is required")
+	v.positive(c.HTTPServer.Timeout, "http_server.timeout")
+	v.positive(c.HTTPServer.IdleTimeout, "http_server.idle_timeout")
+	v.dir(c.HTTPServer.StoragePath, "http_server.storage_path")
+
+	v.oneOf(c.DB.Driver, "db.driver", "mysql", "sqlite")
+	switch c.DB.Driver {
+	case "mysql":
+		v.check(c.DB.MysqlHost != "", "db.mysql_host", "is required (MYSQL_HOST)")
+		v.check(c.DB.MysqlDBName != "", "db.mysql_dbname", "is required (MYSQL_DBNAME)")
+		_, err := strconv.Atoi(c.DB.MysqlPort)
+		v.check(err == nil, "db.mysql_port", "must be a number, got %q", c.DB.MysqlPort)
+		v.check(c.DB.MaxOpenConns > 0, "db.max_open_conns", "must be positive, got %d", c.DB.MaxOpenConns)
+		v.check(c.DB.MaxIdleConns >= 0, "db.max_idle_conns", "must not be negative, got %d", c.DB.MaxIdleConns)
+	case "sqlite":
+		if c.DB.SqlitePath != ":memory:" {
+			v.dir(filepath.Dir(c.DB.SqlitePath), "db.sqlite_path")
+		}
+	}
+
+	c.Video.Resolutions = c.validateResolutions(v)
+	v.check(c.Video.VideoPath != "", "video_service.video_path", "is required")
+	v.oneOf(c.Video.ReplacePolicy, "video_service.replace_policy", "keep", "trash", "delete")
+
+	v.positive(c.Playback.FlushInterval, "playback.flush_interval")
+	v.check(c.Playback.CompleteRatio > 0 && c.Playback.CompleteRatio <= 1, "playback.complete_ratio", "must be above 0 and at most 1, got %v", c.Playback.CompleteRatio)
+	v.check(c.Playback.ContinueLimit > 0, "playback.continue_limit", "must be positive, got %d", c.Playback.ContinueLimit)
+
+	v.check(c.Exp.WorkoutCompleted >= 0, "exp.workout_completed", "must not be negative")
+	v.check(c.Exp.FirstCompletionBonus >= 0, "exp.first_completion_bonus", "must not be negative")
+	v.check(c.Exp.RepeatCooldown >= 0, "exp.repeat_cooldown", "must not be negative")
+	v.check(c.Exp.DailyLimit >= 0, "exp.daily_limit", "must not be negative")
+
+	v.positive(c.Outbox.PollInterval, "outbox.poll_interval")
+	v.positive(c.Outbox.Lease, "outbox.lease")
+	v.positive(c.Outbox.RetryBackoff, "outbox.retry_backoff")
+	v.check(c.Outbox.BatchSize > 0, "outbox.batch_size", "must be positive, got %d", c.Outbox.BatchSize)
+	v.check(c.Outbox.MaxAttempts > 0, "outbox.max_attempts", "must be positive, got %d", c.Outbox.MaxAttempts)
+	v.check(c.Outbox.MaxBackoff >= c.Outbox.RetryBackoff, "outbox.max_backoff", "must not be below outbox.retry_backoff")
+
+	names := make(map[string]bool, len(c.Webhooks))
+	for i, hook := range c.Webhooks {
+		field := fmt.Sprintf("webhooks[%d]", i)
+		v.check(hook.Name != "", field+".name", "is required")
+		v.check(!names[hook.Name], field+".name", "%q is used by another webhook", hook.Name)
+		names[hook.Name] = true
+		v.httpURL(hook.URL, field+".url")
+		v.check(len(hook.Events) > 0, field+".events", "must list at least one event or *")
+		v.check(hook.Timeout >= 0, field+".timeout", "must not be negative")
+	}
+
+	v.oneOf(c.Tracing.Exporter, "tracing.exporter", "none", "stdout", "otlp")
+	if c.Tracing.Exporter == "otlp" {
+		v.httpURL(c.Tracing.Endpoint, "tracing.endpoint")
+	}
+	v.ratio(c.Tracing.SampleRatio, "tracing.sample_ratio")
+
+	v.ratio(c.AccessLog.SegmentSampleRatio, "access_log.segment_sample_ratio")
+
+	for _, pattern := range c.Log.RedactPatterns {
+		_, err := regexp.Compile(pattern)
+		v.check(err == nil, "log.redact_patterns", "%q: %v", pattern, err)
+	}
+
+	v.positive(c.Health.Timeout, "health.timeout")
+	v.check(c.Health.QueueSaturation > 0 && c.Health.QueueSaturation <= 1, "health.queue_saturation", "must be above 0 and at most 1, got %v", c.Health.QueueSaturation)
+
+	v.check(c.Retention.GracePeriod >= 0, "retention.grace_period", "must not be negative")
+	v.check(c.GC.GracePeriod >= 0, "gc.grace_period", "must not be negative")
+
+	v.positive(c.Scheduler.Tick, "scheduler.tick")
+	jobs := make([]string, 0, len(c.Scheduler.Jobs))
+	for name := range c.Scheduler.Jobs {
+		jobs = append(jobs, name)
+	}
+	sort.Strings(jobs)
+	for _, name := range jobs {
+		_, err := cron.Parse(c.Scheduler.Jobs[name])
+		v.check(err == nil, "scheduler.jobs."+name, "%v", err)
+	}
+
+	_, err := os.Stat(filepath.Join(c.Lang.Dir, "active.ro.json"))
+	v.check(err == nil, "lang.dir", "%q has no active.ro.json", c.Lang.Dir)
+
+	if len(v.problems) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("invalid config, %d problems:\n  %s", len(v.problems), strings.Join(v.problems, "\n  "))
+}
+
+// validateResolutions trims the heights, RESOLUTIONS="360, 720" is read with the spaces, and checks each is a height ffmpeg can scale to
+func (c *Config) validateResolutions(v *validator) []string {
+	v.check(len(c.Video.Resolutions) > 0, "video_service.resolutions", "must list at least one height")
+
+	resolutions := make([]string, 0, len(c.Video.Resolutions))
+	for _, res := range c.Video.Resolutions {
+		res = strings.TrimSpace(res)
+
+		height, err := strconv.Atoi(res)
+		v.check(err == nil && height >= 144 && height <= 4320 && height%2 == 0, "video_service.resolutions", "%q is not an even height between 144 and 4320", res)
+
+		resolutions = append(resolutions, res)
+	}
+
+	return resolutions
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"github.com/ilyakaznacheev/cleanenv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestConfig returns the defaults with the paths and the secret a valid config needs
+func newTestConfig(t *testing.T) *Config {
+	t.Helper()
+
+	var cfg Config
+	if err := cleanenv.ReadEnv(&cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	lang := t.TempDir()
+	if err := os.WriteFile(filepath.Join(lang, "active.ro.json"), []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Env = cfg.ENVState.Local
+	cfg.JWT = "secret"
+	cfg.HTTPServer.StoragePath = t.TempDir()
+	cfg.Lang.Dir = lang
+
+	return &cfg
+}
+
+func TestValidateAcceptsDefaults(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.Video.Resolutions = []string{"360", " 720"}
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Video.Resolutions[1] != "720" {
+		t.Fatalf("resolutions not trimmed: %q", cfg.Video.Resolutions)
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.Env = "staging"
+	cfg.JWT = ""
+	cfg.HTTPServer.StoragePath = filepath.Join(t.TempDir(), "missing")
+	cfg.Video.Resolutions = []string{"720p"}
+	cfg.Playback.CompleteRatio = 2
+	cfg.Scheduler.Jobs = map[string]string{"gc": "every hour"}
+	cfg.Webhooks = []Webhook{{Name: "backend", URL: "backend:8000"}}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	for _, field := range []string{
+		"env:",
+		"jwt_secret:",
+		"http_server.storage_path:",
+		"video_service.resolutions:",
+		"playback.complete_ratio:",
+		"scheduler.jobs.gc:",
+		"webhooks[0].url:",
+		"webhooks[0].events:",
+	} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("%s not reported in:\n%s", field, err)
+		}
+	}
+}
+
+func TestRedactedMasksTaggedFields(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.WSServer.Secret = "pusher"
+	cfg.Webhooks = []Webhook{{Name: "backend", Secret: "hook"}}
+
+	redacted := cfg.Redacted()
+
+	if redacted.JWT != Redacted || redacted.WSServer.Secret != Redacted || redacted.Webhooks[0].Secret != Redacted {
+		t.Fatalf("secrets left in %+v", redacted)
+	}
+
+	if cfg.JWT != "secret" || cfg.Webhooks[0].Secret != "hook" {
+		t.Fatal("the original config was changed")
+	}
+}
@@ -0,0 +1,99 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRejectsInvalidExpressions(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"1-x * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+		"@never",
+	}
+
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := Parse(expr); !errors.Is(err, ErrInvalidExpression) {
+				t.Fatalf("got %v, want ErrInvalidExpression", err)
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	at := func(value string) time.Time {
+		t.Helper()
+
+		parsed, err := time.Parse("2006-01-02 15:04", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return parsed
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from string
+		want string
+	}{
+		{name: "every minute", expr: "* * * * *", from: "2026-10-01 10:07", want: "2026-10-01 10:08"},
+		{name: "step from star", expr: "*/15 * * * *", from: "2026-10-01 10:07", want: "2026-10-01 10:15"},
+		{name: "step from star at the boundary", expr: "*/15 * * * *", from: "2026-10-01 10:45", want: "2026-10-01 11:00"},
+		{name: "step from a value", expr: "5/10 * * * *", from: "2026-10-01 10:07", want: "2026-10-01 10:15"},
+		{name: "step from a value rolls the hour", expr: "5/10 * * * *", from: "2026-10-01 10:55", want: "2026-10-01 11:05"},
+		{name: "range", expr: "0 9-17 * * *", from: "2026-10-01 17:30", want: "2026-10-02 09:00"},
+		{name: "range with step", expr: "0 8-18/5 * * *", from: "2026-10-01 13:01", want: "2026-10-01 18:00"},
+		{name: "list", expr: "0 0 1,15 * *", from: "2026-10-02 00:00", want: "2026-10-15 00:00"},
+		{name: "weekdays", expr: "30 6 * * 1-5", from: "2026-10-02 07:00", want: "2026-10-05 06:30"},
+		{name: "7 is sunday", expr: "0 0 * * 7", from: "2026-10-01 00:00", want: "2026-10-04 00:00"},
+		{name: "0 is sunday", expr: "0 0 * * 0", from: "2026-10-01 00:00", want: "2026-10-04 00:00"},
+		{name: "restricted days match either", expr: "0 0 13 * 5", from: "2026-10-01 00:00", want: "2026-10-02 00:00"},
+		{name: "star day of week keeps the day of month", expr: "0 0 13 * *", from: "2026-10-01 00:00", want: "2026-10-13 00:00"},
+		{name: "star day of month keeps the day of week", expr: "0 0 * * 5", from: "2026-10-03 00:00", want: "2026-10-09 00:00"},
+		{name: "short month is skipped", expr: "0 0 31 * *", from: "2026-04-01 00:00", want: "2026-05-31 00:00"},
+		{name: "year rollover", expr: "30 23 * * *", from: "2026-12-31 23:45", want: "2027-01-01 23:30"},
+		{name: "restricted month", expr: "0 0 1 3 *", from: "2026-10-01 00:00", want: "2027-03-01 00:00"},
+		{name: "leap day", expr: "0 0 29 2 *", from: "2026-03-01 00:00", want: "2028-02-29 00:00"},
+		{name: "descriptor", expr: "@daily", from: "2026-10-01 10:07", want: "2026-10-02 00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := schedule.Next(at(tt.from)); !got.Equal(at(tt.want)) {
+				t.Fatalf("got %s, want %s", got.Format("2006-01-02 15:04 Mon"), tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleNextIsZeroWhenItNeverFires(t *testing.T) {
+	schedule, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if next := schedule.Next(time.Now()); !next.IsZero() {
+		t.Fatalf("got %s, want the zero time", next)
+	}
+}
@@ -5,21 +5,30 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
-	"time"
+)
+
+const (
+	DefaultLimit int64 = 20
+	MaxLimit     int64 = 100
 )
 
 type Filter struct {
 	Search string `json:"search"`
 	Page   int64  `json:"page"`
 	Limit  int64  `json:"limit"`
+	Sort   string `json:"sort"`
 
 	Fields map[string]string `json:"filters"`
 }
 
-func GetContextWithFilters(r *http.Request) context.Context {
+type ctxKey struct{}
+
+// Parse reads search, page, limit, sort and filters[...] from the query string
+func Parse(r *http.Request) Filter {
 	var filter Filter
 
 	filter.Search = r.URL.Query().Get("search")
+	filter.Sort = r.URL.Query().Get("sort")
 
 	if page := r.URL.Query().Get("page"); page != "" {
 		p, _ := strconv.Atoi(page)
@@ -42,10 +51,37 @@ func GetContextWithFilters(r *http.Request) context.Context {
 		}
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
-	defer cancel()
+	return filter
+}
+
+// Normalized returns a copy with page and limit clamped to usable values
+func (f Filter) Normalized() Filter {
+	if f.Limit <= 0 {
+		f.Limit = DefaultLimit
+	}
+
+	if f.Limit > MaxLimit {
+		f.Limit = MaxLimit
+	}
+
+	if f.Page <= 0 {
+		f.Page = 1
+	}
+
+	return f
+}
+
+func GetContextWithFilters(r *http.Request) context.Context {
+	return WithFilter(r.Context(), Parse(r))
+}
+
+func WithFilter(ctx context.Context, filter Filter) context.Context {
+	return context.WithValue(ctx, ctxKey{}, filter)
+}
 
-	ctx = context.WithValue(ctx, "filter", filter)
+// FromContext returns the filter stored by GetContextWithFilters or an empty one
+func FromContext(ctx context.Context) Filter {
+	filter, _ := ctx.Value(ctxKey{}).(Filter)
 
-	return ctx
+	return filter
 }
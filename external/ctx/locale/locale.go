@@ -0,0 +1,51 @@
+package locale
+
+import (
+	"context"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// Fallback is the language used when neither the user nor the client asks for one the bundle has
+const Fallback = "ro"
+
+type ctxKey struct{}
+
+// state keeps what the localizer was built from so a later preference, like the user profile, can be put in front
+type state struct {
+	bundle    *i18n.Bundle
+	accept    string
+	localizer *i18n.Localizer
+}
+
+// New returns a context with a localizer for the Accept-Language header of the request
+func New(ctx context.Context, bundle *i18n.Bundle, accept string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &state{
+		bundle:    bundle,
+		accept:    accept,
+		localizer: i18n.NewLocalizer(bundle, accept, Fallback),
+	})
+}
+
+// Prefer puts lang before the Accept-Language of the request, an empty lang keeps the context as it is
+func Prefer(ctx context.Context, lang string) context.Context {
+	s, ok := ctx.Value(ctxKey{}).(*state)
+	if !ok || lang == "" {
+		return ctx
+	}
+
+	return context.WithValue(ctx, ctxKey{}, &state{
+		bundle:    s.bundle,
+		accept:    s.accept,
+		localizer: i18n.NewLocalizer(s.bundle, lang, s.accept, Fallback),
+	})
+}
+
+// Localizer returns the localizer of the request, the locale middleware sets it for every route
+func Localizer(ctx context.Context) *i18n.Localizer {
+	s, ok := ctx.Value(ctxKey{}).(*state)
+	if !ok {
+		return nil
+	}
+
+	return s.localizer
+}
@@ -4,12 +4,25 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	_ "github.com/mattn/go-sqlite3"
 	"go-fitness/external/config"
 	"go-fitness/external/logger/sl"
 	"go.uber.org/fx"
 	"log/slog"
 )
 
+// NewSqlDatabase opens the database of the configured driver
+func NewSqlDatabase(lc fx.Lifecycle, log *slog.Logger, cfg *config.Config) (*sql.DB, error) {
+	switch Dialect(cfg.DB.Driver) {
+	case MySQL:
+		return NewMysqlDatabase(lc, log, cfg)
+	case SQLite:
+		return NewSqliteDatabase(lc, log, cfg)
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", cfg.DB.Driver)
+	}
+}
+
 func NewMysqlDatabase(lc fx.Lifecycle, log *slog.Logger, cfg *config.Config) (*sql.DB, error) {
 	dsn := fmt.Sprintf(
 		"%s:%s@tcp(%s)/%s?charset=utf8mb4,utf8&parseTime=True&loc=Local",
@@ -47,3 +60,53 @@ func NewMysqlDatabase(lc fx.Lifecycle, log *slog.Logger, cfg *config.Config) (*s
 
 	return db, nil
 }
+
+// NewSqliteDatabase opens the SQLite file at db.sqlite_path, ":memory:" keeps the database in memory
+func NewSqliteDatabase(lc fx.Lifecycle, log *slog.Logger, cfg *config.Config) (*sql.DB, error) {
+	db, err := OpenSqlite(cfg.DB.SqlitePath)
+	if err != nil {
+		log.Error("failed to open database", sl.Err(err))
+		return nil, err
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			log.Info("Starting database connection", sl.String("sqlite_path", cfg.DB.SqlitePath))
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			log.Info("Closing database connection")
+			return db.Close()
+		},
+	})
+
+	return db, nil
+}
+
+// OpenSqlite opens an SQLite database with foreign keys on. SQLite allows one writer at a time,
+// so the pool keeps a single connection and nothing waits on a lock held by the same process
+func OpenSqlite(path string) (*sql.DB, error) {
+	dsn := "file:" + path + "?_foreign_keys=on&_busy_timeout=5000"
+	if path == ":memory:" {
+		dsn = "file::memory:?_foreign_keys=on"
+	} else {
+		dsn += "&_journal_mode=WAL"
+	}
+
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+	// an in-memory database lives as long as a connection to it
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+
+	if err = db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
@@ -4,17 +4,25 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"fmt"
 	_ "github.com/go-sql-driver/mysql"
+	"go-fitness/external/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"sync"
 )
 
 type SqlInterface interface {
-	DoInTransaction(do func(tx *sql.Tx) error) error
-	Begin() error
-	Commit() error
-	Rollback() error
-	GetTx() *sql.Tx
-	GetExecer() QueryExecer
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	DoInTransaction(ctx context.Context, do func(tx QueryExecer) error) error
+	GetExecer(ctx context.Context) QueryExecer
+	WithLock(ctx context.Context, name string, do func(ctx context.Context) error) (bool, error)
+	Dialect() Dialect
+}
+
+// Transactor is the unit of work of the services, repositories called with the context given to fn share its transaction
+type Transactor interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 type QueryExecer interface {
@@ -27,90 +35,141 @@ type QueryExecer interface {
 }
 
 type DataBase struct {
-	db *sql.DB
-	tx *sql.Tx
+	db      *sql.DB
+	dialect Dialect
+
+	// locks stand in for GET_LOCK on SQLite, where only this process uses the database
+	locksMu sync.Mutex
+	locks   map[string]*sync.Mutex
 }
 
-func NewMysql(
+func New(
 	db *sql.DB,
+	dialect Dialect,
 ) *DataBase {
 	return &DataBase{
-		db: db,
+		db:      db,
+		dialect: dialect,
+		locks:   make(map[string]*sync.Mutex),
 	}
 }
 
-func (u *DataBase) Begin() error {
-	var err error
-	u.tx, err = u.db.Begin()
-	return err
+func NewConfigured(db *sql.DB, cfg *config.Config) *DataBase {
+	return New(db, Dialect(cfg.DB.Driver))
+}
+
+func (u *DataBase) Dialect() Dialect {
+	return u.dialect
 }
 
-func (u *DataBase) DoInTransaction(do func(tx *sql.Tx) error) (err error) {
-	tx, err := u.db.Begin()
+// WithTx runs fn in a transaction that travels in the context it is given, every GetExecer(ctx) inside joins it.
+// A nested WithTx joins the outer transaction, which commits when the outermost fn returns nil and rolls back otherwise.
+// Once it is over the hooks registered with AfterCommit or OnRollback run
+func (u *DataBase) WithTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if txFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "db transaction",
+		trace.WithAttributes(attribute.String("db.system", string(u.dialect))),
+	)
+	defer span.End()
+
+	tx, err := u.db.BeginTx(ctx, nil)
 	if err != nil {
+		endSpan(span, err)
 		return err
 	}
 
+	work := &unitOfWork{tx: tx}
+
 	defer func() {
 		if p := recover(); p != nil {
-			if rollbackErr := tx.Rollback(); rollbackErr != nil {
-				err = rollbackErr
-				return
-			}
+			_ = tx.Rollback()
+			work.rolledBack()
 			panic(p)
 		} else if err != nil {
 			if rollbackErr := tx.Rollback(); rollbackErr != nil {
-				err = rollbackErr
-				return
+				err = errors.Join(err, rollbackErr)
 			}
+			work.rolledBack()
+		} else if err = tx.Commit(); err != nil {
+			work.rolledBack()
 		} else {
-			err = tx.Commit()
+			work.committed()
 		}
+
+		endSpan(span, err)
 	}()
 
-	err = do(tx)
-	return err
+	return fn(context.WithValue(ctx, txKey{}, work))
 }
 
-func (u *DataBase) Commit() error {
-	if u.tx == nil {
-		return nil
-	}
+// DoInTransaction runs do in the transaction of the context, starting one when there is none
+func (u *DataBase) DoInTransaction(ctx context.Context, do func(tx QueryExecer) error) error {
+	return u.WithTx(ctx, func(ctx context.Context) error {
+		return do(u.GetExecer(ctx))
+	})
+}
 
-	err := u.tx.Commit()
-	if err != nil {
-		return err
-	}
+func (u *DataBase) GetDB() *sql.DB {
+	return u.db
+}
 
-	u.tx = nil
-	return err
+// GetExecer returns the transaction of the context, or the pool when the context carries none
+func (u *DataBase) GetExecer(ctx context.Context) QueryExecer {
+	if tx := txFromContext(ctx); tx != nil {
+		return tracedExecer{QueryExecer: tx, dialect: u.dialect}
+	}
+	return tracedExecer{QueryExecer: u.db, dialect: u.dialect}
 }
 
-func (u *DataBase) Rollback() error {
-	if u.tx == nil {
-		return fmt.Errorf("transaction is not started: %w", errors.New("rollback"))
+// WithLock runs do while holding the named MySQL lock, so only one instance of the app runs it at a time.
+// It reports false without calling do when another connection holds the lock
+func (u *DataBase) WithLock(ctx context.Context, name string, do func(ctx context.Context) error) (locked bool, err error) {
+	if u.dialect == SQLite {
+		return u.withLocalLock(ctx, name, do)
 	}
 
-	err := u.tx.Rollback()
+	// GET_LOCK belongs to the connection, so the same one has to release it
+	conn, err := u.db.Conn(ctx)
 	if err != nil {
-		return err
+		return false, err
 	}
+	defer conn.Close()
 
-	u.tx = nil
-	return nil
-}
+	var acquired sql.NullInt64
+	if err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 0)", name).Scan(&acquired); err != nil {
+		return false, err
+	}
 
-func (u *DataBase) GetDB() *sql.DB {
-	return u.db
-}
+	if acquired.Int64 != 1 {
+		return false, nil
+	}
+
+	defer func() {
+		// the job context may be cancelled already, the lock still has to be released
+		if _, releaseErr := conn.ExecContext(context.WithoutCancel(ctx), "SELECT RELEASE_LOCK(?)", name); releaseErr != nil && err == nil {
+			err = releaseErr
+		}
+	}()
 
-func (u *DataBase) GetTx() *sql.Tx {
-	return u.tx
+	return true, do(ctx)
 }
 
-func (u *DataBase) GetExecer() QueryExecer {
-	if u.tx != nil {
-		return u.tx
+func (u *DataBase) withLocalLock(ctx context.Context, name string, do func(ctx context.Context) error) (bool, error) {
+	u.locksMu.Lock()
+	lock, ok := u.locks[name]
+	if !ok {
+		lock = &sync.Mutex{}
+		u.locks[name] = lock
 	}
-	return u.db
+	u.locksMu.Unlock()
+
+	if !lock.TryLock() {
+		return false, nil
+	}
+	defer lock.Unlock()
+
+	return true, do(ctx)
 }
@@ -0,0 +1,18 @@
+package db
+
+// Dialect is the SQL flavour of the configured driver, repositories branch on it where MySQL and SQLite differ
+type Dialect string
+
+const (
+	MySQL  Dialect = "mysql"
+	SQLite Dialect = "sqlite"
+)
+
+// ForUpdate is the row locking suffix of a SELECT, SQLite locks the whole database on write instead
+func (d Dialect) ForUpdate() string {
+	if d == SQLite {
+		return ""
+	}
+
+	return " FOR UPDATE"
+}
@@ -6,10 +6,11 @@ func NewDataBase() fx.Option {
 	return fx.Module(
 		"database",
 		fx.Provide(
-			NewMysqlDatabase,
+			NewSqlDatabase,
 			fx.Annotate(
-				NewMysql,
+				NewConfigured,
 				fx.As(new(SqlInterface)),
+				fx.As(new(Transactor)),
 			),
 		),
 	)
@@ -0,0 +1,173 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"go-fitness/external/ctx/filter"
+	"sort"
+	"strings"
+)
+
+// TrashedKey is the reserved filter for soft deletable specs: "with" or "only"
+const TrashedKey = "trashed"
+
+var (
+	ErrUnknownFilter = errors.New("unknown filter")
+	ErrUnknownSort   = errors.New("unknown sort")
+)
+
+// Spec describes what a list query of one entity may select, search, filter and sort by.
+// Filterable and Sortable map the public key used in the query string to a column name,
+// so nothing coming from the request is ever interpolated into SQL.
+// Values converts the value of a filter before it is bound, a value it rejects is an unknown filter
+type Spec struct {
+	Table       string
+	Columns     []string
+	Searchable  []string
+	Filterable  map[string]string
+	Values      map[string]func(string) (interface{}, bool)
+	Sortable    map[string]string
+	DefaultSort string
+	SoftDelete  bool
+}
+
+type Builder struct {
+	spec    Spec
+	where   []string
+	args    []interface{}
+	orderBy string
+	limit   int64
+	offset  int64
+	trashed string
+}
+
+func New(spec Spec) *Builder {
+	return &Builder{
+		spec:    spec,
+		orderBy: spec.DefaultSort,
+	}
+}
+
+// Where adds a raw condition, it must only ever contain placeholders for values
+func (b *Builder) Where(cond string, args ...interface{}) *Builder {
+	b.where = append(b.where, cond)
+	b.args = append(b.args, args...)
+
+	return b
+}
+
+// Apply adds search, filters, sorting and pagination from f, rejecting keys that are not whitelisted
+func (b *Builder) Apply(f filter.Filter) error {
+	if f.Search != "" && len(b.spec.Searchable) > 0 {
+		likes := make([]string, 0, len(b.spec.Searchable))
+		for _, column := range b.spec.Searchable {
+			likes = append(likes, column+" LIKE ? ESCAPE '!'")
+			b.args = append(b.args, "%"+escapeLike(f.Search)+"%")
+		}
+		b.where = append(b.where, "("+strings.Join(likes, " OR ")+")")
+	}
+
+	keys := make([]string, 0, len(f.Fields))
+	for key := range f.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := f.Fields[key]
+
+		if key == TrashedKey && b.spec.SoftDelete {
+			if value != "with" && value != "only" {
+				return fmt.Errorf("%w: %s=%s", ErrUnknownFilter, key, value)
+			}
+			b.trashed = value
+			continue
+		}
+
+		column, ok := b.spec.Filterable[key]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownFilter, key)
+		}
+
+		var arg interface{} = value
+		if convert, ok := b.spec.Values[key]; ok {
+			if arg, ok = convert(value); !ok {
+				return fmt.Errorf("%w: %s=%s", ErrUnknownFilter, key, value)
+			}
+		}
+
+		b.Where(column+" = ?", arg)
+	}
+
+	if f.Sort != "" {
+		direction := "ASC"
+		key := f.Sort
+
+		if strings.HasPrefix(key, "-") {
+			direction = "DESC"
+			key = strings.TrimPrefix(key, "-")
+		}
+
+		column, ok := b.spec.Sortable[key]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownSort, key)
+		}
+
+		b.orderBy = column + " " + direction
+	}
+
+	f = f.Normalized()
+
+	b.limit = f.Limit
+	b.offset = (f.Page - 1) * f.Limit
+
+	return nil
+}
+
+// Select returns the paged list query and its arguments
+func (b *Builder) Select() (string, []interface{}) {
+	query := "SELECT " + strings.Join(b.spec.Columns, ",") + " FROM " + b.spec.Table + b.whereClause()
+
+	if b.orderBy != "" {
+		query += " ORDER BY " + b.orderBy
+	}
+
+	args := append([]interface{}{}, b.args...)
+
+	if b.limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, b.limit, b.offset)
+	}
+
+	return query, args
+}
+
+// Count returns the query for the total number of rows matching the same conditions
+func (b *Builder) Count() (string, []interface{}) {
+	return "SELECT COUNT(*) FROM " + b.spec.Table + b.whereClause(), append([]interface{}{}, b.args...)
+}
+
+func (b *Builder) whereClause() string {
+	where := append([]string{}, b.where...)
+
+	if b.spec.SoftDelete {
+		switch b.trashed {
+		case "with":
+		case "only":
+			where = append(where, "deleted_at IS NOT NULL")
+		default:
+			where = append(where, "deleted_at IS NULL")
+		}
+	}
+
+	if len(where) == 0 {
+		return ""
+	}
+
+	return " WHERE " + strings.Join(where, " AND ")
+}
+
+// escapeLike escapes with ! rather than a backslash, which MySQL and SQLite read differently in string literals
+func escapeLike(s string) string {
+	return strings.NewReplacer(`!`, `!!`, `%`, `!%`, `_`, `!_`).Replace(s)
+}
@@ -0,0 +1,148 @@
+package query
+
+import (
+	"errors"
+	"go-fitness/external/ctx/filter"
+	"reflect"
+	"testing"
+)
+
+var spec = Spec{
+	Table:      "videos",
+	Columns:    []string{"id", "name"},
+	Searchable: []string{"name", "description"},
+	Filterable: map[string]string{"status": "status"},
+	Values: map[string]func(string) (interface{}, bool){
+		"status": func(value string) (interface{}, bool) {
+			if value == "processed" {
+				return 2, true
+			}
+			return nil, false
+		},
+	},
+	Sortable:    map[string]string{"name": "name", "created": "created_at"},
+	DefaultSort: "id DESC",
+	SoftDelete:  true,
+}
+
+func TestBuilderApply(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      Spec
+		filter    filter.Filter
+		wantErr   error
+		wantQuery string
+		wantArgs  []interface{}
+	}{
+		{
+			name:      "defaults",
+			spec:      spec,
+			wantQuery: "SELECT id,name FROM videos WHERE deleted_at IS NULL ORDER BY id DESC LIMIT ? OFFSET ?",
+			wantArgs:  []interface{}{filter.DefaultLimit, int64(0)},
+		},
+		{
+			name:    "unknown filter",
+			spec:    spec,
+			filter:  filter.Filter{Fields: map[string]string{"password": "x"}},
+			wantErr: ErrUnknownFilter,
+		},
+		{
+			name:    "unknown trashed value",
+			spec:    spec,
+			filter:  filter.Filter{Fields: map[string]string{TrashedKey: "all"}},
+			wantErr: ErrUnknownFilter,
+		},
+		{
+			name:    "trashed without soft delete",
+			spec:    Spec{Table: "tabs", Columns: []string{"id"}},
+			filter:  filter.Filter{Fields: map[string]string{TrashedKey: "with"}},
+			wantErr: ErrUnknownFilter,
+		},
+		{
+			name:    "unknown sort",
+			spec:    spec,
+			filter:  filter.Filter{Sort: "-password"},
+			wantErr: ErrUnknownSort,
+		},
+		{
+			name:      "ascending sort",
+			spec:      spec,
+			filter:    filter.Filter{Sort: "name", Page: 3, Limit: 10},
+			wantQuery: "SELECT id,name FROM videos WHERE deleted_at IS NULL ORDER BY name ASC LIMIT ? OFFSET ?",
+			wantArgs:  []interface{}{int64(10), int64(20)},
+		},
+		{
+			name:      "descending sort",
+			spec:      spec,
+			filter:    filter.Filter{Sort: "-created"},
+			wantQuery: "SELECT id,name FROM videos WHERE deleted_at IS NULL ORDER BY created_at DESC LIMIT ? OFFSET ?",
+			wantArgs:  []interface{}{filter.DefaultLimit, int64(0)},
+		},
+		{
+			name:      "with trashed",
+			spec:      spec,
+			filter:    filter.Filter{Fields: map[string]string{TrashedKey: "with"}},
+			wantQuery: "SELECT id,name FROM videos ORDER BY id DESC LIMIT ? OFFSET ?",
+			wantArgs:  []interface{}{filter.DefaultLimit, int64(0)},
+		},
+		{
+			name:      "only trashed",
+			spec:      spec,
+			filter:    filter.Filter{Fields: map[string]string{TrashedKey: "only", "status": "processed"}},
+			wantQuery: "SELECT id,name FROM videos WHERE status = ? AND deleted_at IS NOT NULL ORDER BY id DESC LIMIT ? OFFSET ?",
+			wantArgs:  []interface{}{2, filter.DefaultLimit, int64(0)},
+		},
+		{
+			name:    "rejected filter value",
+			spec:    spec,
+			filter:  filter.Filter{Fields: map[string]string{"status": "done"}},
+			wantErr: ErrUnknownFilter,
+		},
+		{
+			name:      "search escapes like wildcards",
+			spec:      spec,
+			filter:    filter.Filter{Search: "50%_off!"},
+			wantQuery: "SELECT id,name FROM videos WHERE (name LIKE ? ESCAPE '!' OR description LIKE ? ESCAPE '!') AND deleted_at IS NULL ORDER BY id DESC LIMIT ? OFFSET ?",
+			wantArgs:  []interface{}{"%50!%!_off!!%", "%50!%!_off!!%", filter.DefaultLimit, int64(0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(tt.spec)
+
+			err := b.Apply(tt.filter)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			query, args := b.Select()
+			if query != tt.wantQuery {
+				t.Fatalf("got query\n%s\nwant\n%s", query, tt.wantQuery)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Fatalf("got args %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestBuilderCountKeepsConditionsWithoutPaging(t *testing.T) {
+	b := New(spec)
+	if err := b.Apply(filter.Filter{Search: "abs", Page: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	query, args := b.Count()
+
+	want := "SELECT COUNT(*) FROM videos WHERE (name LIKE ? ESCAPE '!' OR description LIKE ? ESCAPE '!') AND deleted_at IS NULL"
+	if query != want || len(args) != 2 {
+		t.Fatalf("got %s %v", query, args)
+	}
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+)
+
+const tracerName = "go-fitness/external/db"
+
+// tracedExecer records a span for every query the repositories run, the plain variants have no context
+// to hang the span on and are passed through
+type tracedExecer struct {
+	QueryExecer
+	dialect Dialect
+}
+
+func (e tracedExecer) start(ctx context.Context, query string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, "db "+operation(query),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", string(e.dialect)),
+			attribute.String("db.statement", query),
+		),
+	)
+}
+
+func (e tracedExecer) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := e.start(ctx, query)
+	defer span.End()
+
+	rows, err := e.QueryExecer.QueryContext(ctx, query, args...)
+	endSpan(span, err)
+
+	return rows, err
+}
+
+// QueryRowContext ends its span once the query ran, the error of the row only surfaces on Scan
+func (e tracedExecer) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := e.start(ctx, query)
+	defer span.End()
+
+	row := e.QueryExecer.QueryRowContext(ctx, query, args...)
+	endSpan(span, row.Err())
+
+	return row
+}
+
+func (e tracedExecer) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := e.start(ctx, query)
+	defer span.End()
+
+	result, err := e.QueryExecer.ExecContext(ctx, query, args...)
+	endSpan(span, err)
+
+	return result, err
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil && err != sql.ErrNoRows {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// operation is the first keyword of the query, SELECT, INSERT and so on
+func operation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "query"
+	}
+
+	return strings.ToUpper(fields[0])
+}
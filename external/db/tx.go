@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+type txKey struct{}
+
+// unitOfWork is the transaction WithTx keeps in the context with what has to happen once it is over
+type unitOfWork struct {
+	tx *sql.Tx
+
+	mu          sync.Mutex
+	afterCommit []func()
+	onRollback  []func()
+}
+
+func (w *unitOfWork) committed() {
+	w.mu.Lock()
+	hooks := w.afterCommit
+	w.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook()
+	}
+}
+
+// rolledBack runs the compensations in reverse order, undoing the latest step first
+func (w *unitOfWork) rolledBack() {
+	w.mu.Lock()
+	hooks := w.onRollback
+	w.mu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i]()
+	}
+}
+
+func workFromContext(ctx context.Context) *unitOfWork {
+	work, _ := ctx.Value(txKey{}).(*unitOfWork)
+	return work
+}
+
+// txFromContext returns the transaction started by WithTx for the context, nil outside of one
+func txFromContext(ctx context.Context) *sql.Tx {
+	if work := workFromContext(ctx); work != nil {
+		return work.tx
+	}
+
+	return nil
+}
+
+// AfterCommit runs fn once the transaction of the context has committed, right away outside of a transaction.
+// Side effects nobody can take back, like queueing work on what was written, belong here
+func AfterCommit(ctx context.Context, fn func()) {
+	work := workFromContext(ctx)
+	if work == nil {
+		fn()
+		return
+	}
+
+	work.mu.Lock()
+	work.afterCommit = append(work.afterCommit, fn)
+	work.mu.Unlock()
+}
+
+// OnRollback runs fn when the transaction of the context rolls back, to undo what the database can not,
+// like files written along with a row. Outside of a transaction fn is never called
+func OnRollback(ctx context.Context, fn func()) {
+	work := workFromContext(ctx)
+	if work == nil {
+		return
+	}
+
+	work.mu.Lock()
+	work.onRollback = append(work.onRollback, fn)
+	work.mu.Unlock()
+}
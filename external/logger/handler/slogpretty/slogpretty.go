@@ -81,11 +81,12 @@ func (h *PrettyHandler) Handle(_ context.Context, r slog.Record) error {
 	return nil
 }
 
+// WithAttrs keeps the attrs of the parent, a logger built with several With calls prints all of them
 func (h *PrettyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	return &PrettyHandler{
 		Handler: h.Handler,
 		l:       h.l,
-		attrs:   attrs,
+		attrs:   append(append([]slog.Attr{}, h.attrs...), attrs...),
 	}
 }
 
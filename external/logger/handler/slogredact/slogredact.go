@@ -0,0 +1,183 @@
+// Package slogredact wraps a slog.Handler and masks secrets and personal data before they are written
+package slogredact
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const Redacted = "[REDACTED]"
+
+// DefaultKeys are the attribute keys whose value is always masked, compared without case
+var DefaultKeys = []string{
+	"password",
+	"secret",
+	"secret_key",
+	"jwt_secret",
+	"token",
+	"authorization",
+	"cookie",
+	"api_key",
+}
+
+// DefaultPatterns mask JWTs and email addresses wherever they show up in a string value
+var DefaultPatterns = []string{
+	`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`,
+	`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`,
+}
+
+type Options struct {
+	// Keys are added to DefaultKeys
+	Keys []string
+	// Patterns are regular expressions added to DefaultPatterns
+	Patterns []string
+}
+
+type RedactHandler struct {
+	next     slog.Handler
+	keys     map[string]struct{}
+	patterns []*regexp.Regexp
+}
+
+func (opts Options) NewRedactHandler(next slog.Handler) (*RedactHandler, error) {
+	h := &RedactHandler{
+		next: next,
+		keys: make(map[string]struct{}),
+	}
+
+	for _, key := range append(append([]string{}, DefaultKeys...), opts.Keys...) {
+		h.keys[strings.ToLower(key)] = struct{}{}
+	}
+
+	for _, pattern := range append(append([]string{}, DefaultPatterns...), opts.Patterns...) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("redact pattern %q: %w", pattern, err)
+		}
+		h.patterns = append(h.patterns, re)
+	}
+
+	return h, nil
+}
+
+func (h *RedactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *RedactHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, h.redactString(r.Message), r.PC)
+
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(h.redact(a))
+		return true
+	})
+
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *RedactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redact(a)
+	}
+
+	return &RedactHandler{
+		next:     h.next.WithAttrs(redacted),
+		keys:     h.keys,
+		patterns: h.patterns,
+	}
+}
+
+func (h *RedactHandler) WithGroup(name string) slog.Handler {
+	return &RedactHandler{
+		next:     h.next.WithGroup(name),
+		keys:     h.keys,
+		patterns: h.patterns,
+	}
+}
+
+func (h *RedactHandler) sensitive(key string) bool {
+	_, ok := h.keys[strings.ToLower(key)]
+	return ok
+}
+
+func (h *RedactHandler) redact(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+
+	if h.sensitive(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(h.redactString(a.Value.String()))
+	case slog.KindGroup:
+		group := a.Value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			redacted[i] = h.redact(ga)
+		}
+		a.Value = slog.GroupValue(redacted...)
+	case slog.KindAny:
+		a.Value = h.redactAny(a.Value.Any())
+	}
+
+	return a
+}
+
+// redactAny walks the json form of structs and maps, the same shape the json handler would write
+func (h *RedactHandler) redactAny(v any) slog.Value {
+	switch v := v.(type) {
+	case error:
+		return slog.StringValue(h.redactString(v.Error()))
+	case fmt.Stringer:
+		return slog.StringValue(h.redactString(v.String()))
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return slog.StringValue(h.redactString(fmt.Sprintf("%+v", v)))
+	}
+
+	var decoded any
+	if err = json.Unmarshal(b, &decoded); err != nil {
+		return slog.StringValue(h.redactString(string(b)))
+	}
+
+	return slog.AnyValue(h.redactJSON(decoded))
+}
+
+func (h *RedactHandler) redactJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if h.sensitive(key) {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = h.redactJSON(value)
+		}
+		return v
+	case []any:
+		for i, value := range v {
+			v[i] = h.redactJSON(value)
+		}
+		return v
+	case string:
+		return h.redactString(v)
+	default:
+		return v
+	}
+}
+
+func (h *RedactHandler) redactString(s string) string {
+	for _, re := range h.patterns {
+		s = re.ReplaceAllString(s, Redacted)
+	}
+
+	return s
+}
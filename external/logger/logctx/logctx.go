@@ -0,0 +1,58 @@
+// Package logctx carries log attributes in the context, so every line logged for one request shares its request id
+package logctx
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+type attrsKey struct{}
+
+// attrs is shared by every context derived from the one it was stored in, so what an inner middleware adds,
+// like the user of the request, is seen by the access log that wraps it
+type attrs struct {
+	mu    sync.Mutex
+	attrs []slog.Attr
+}
+
+// With adds attrs to the context, in place when it already carries some
+func With(ctx context.Context, add ...slog.Attr) context.Context {
+	if a, ok := ctx.Value(attrsKey{}).(*attrs); ok {
+		a.mu.Lock()
+		a.attrs = append(a.attrs, add...)
+		a.mu.Unlock()
+
+		return ctx
+	}
+
+	return context.WithValue(ctx, attrsKey{}, &attrs{attrs: add})
+}
+
+// Attrs returns a copy of the attributes of the context
+func Attrs(ctx context.Context) []slog.Attr {
+	a, ok := ctx.Value(attrsKey{}).(*attrs)
+	if !ok {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return append([]slog.Attr(nil), a.attrs...)
+}
+
+// Logger returns log with the attributes of the context
+func Logger(ctx context.Context, log *slog.Logger) *slog.Logger {
+	add := Attrs(ctx)
+	if len(add) == 0 {
+		return log
+	}
+
+	args := make([]any, len(add))
+	for i, attr := range add {
+		args[i] = attr
+	}
+
+	return log.With(args...)
+}
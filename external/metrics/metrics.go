@@ -0,0 +1,113 @@
+// Package metrics holds the Prometheus collectors of the service and the handler that exposes them on /metrics
+package metrics
+
+import (
+	"database/sql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/fx"
+	"net/http"
+)
+
+const namespace = "fitness"
+
+// Metrics is shared by the middleware, handlers and services that record something,
+// every collector is registered on its own registry rather than the global one
+type Metrics struct {
+	registry *prometheus.Registry
+
+	HTTPRequests       *prometheus.CounterVec
+	HTTPDuration       *prometheus.HistogramVec
+	BytesServed        *prometheus.CounterVec
+	TranscodeQueue     prometheus.Gauge
+	TranscodesInFlight prometheus.Gauge
+	TranscodeDuration  *prometheus.HistogramVec
+	FFmpegFailures     *prometheus.CounterVec
+	UploadBytes        prometheus.Histogram
+}
+
+func New(db *sql.DB) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		HTTPRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route pattern and status code.",
+		}, []string{"method", "route", "status"}),
+
+		HTTPDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method and route pattern.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+
+		BytesServed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "video_bytes_served_total",
+			Help:      "Bytes of video served by kind, segment or playlist.",
+		}, []string{"kind"}),
+
+		TranscodeQueue: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "transcode_queue_depth",
+			Help:      "Transcode tasks waiting for a worker.",
+		}),
+
+		TranscodesInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "transcodes_in_flight",
+			Help:      "Transcode tasks being processed.",
+		}),
+
+		TranscodeDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "transcode_duration_seconds",
+			Help:      "Time ffmpeg takes to transcode one resolution of a video.",
+			Buckets:   []float64{5, 15, 30, 60, 120, 300, 600, 1200, 2400},
+		}, []string{"resolution"}),
+
+		FFmpegFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "ffmpeg_failures_total",
+			Help:      "Failed ffmpeg and ffprobe runs by step.",
+		}, []string{"step"}),
+
+		UploadBytes: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upload_size_bytes",
+			Help:      "Size of the uploaded source videos.",
+			Buckets:   prometheus.ExponentialBuckets(1<<20, 4, 8),
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewDBStatsCollector(db, namespace),
+		m.HTTPRequests,
+		m.HTTPDuration,
+		m.BytesServed,
+		m.TranscodeQueue,
+		m.TranscodesInFlight,
+		m.TranscodeDuration,
+		m.FFmpegFailures,
+		m.UploadBytes,
+	)
+
+	return m
+}
+
+// Handler serves the registry in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func NewMetrics() fx.Option {
+	return fx.Module(
+		"metrics",
+		fx.Provide(New),
+	)
+}
@@ -0,0 +1,75 @@
+package response
+
+import (
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go-fitness/external/apperr"
+	"go-fitness/external/ctx/locale"
+	"net/http"
+	"strings"
+)
+
+// ErrorBody is the machine readable part of an error response, clients branch on Code and not on the message
+type ErrorBody struct {
+	Code   string      `json:"code"`
+	Fields []FieldBody `json:"fields,omitempty"`
+}
+
+type FieldBody struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error renders err with its status and code and a message in the language of the request.
+// An error that is not an *apperr.Error is answered as an internal_server_error so nothing internal reaches the client
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	e := apperr.From(err)
+	localizer := locale.Localizer(r.Context())
+
+	body := &ErrorBody{Code: e.Code}
+	messages := make([]string, 0, len(e.Fields))
+
+	for _, field := range e.Fields {
+		message := localize(localizer, field.Code, map[string]string{
+			"Field": field.Field,
+			"Param": field.Param,
+		})
+
+		body.Fields = append(body.Fields, FieldBody{
+			Field:   field.Field,
+			Code:    field.Code,
+			Message: message,
+		})
+		messages = append(messages, message)
+	}
+
+	// the field messages say more than "invalid request", clients showing only the message keep seeing them
+	message := strings.Join(messages, ", ")
+	if message == "" {
+		message = localize(localizer, e.Code, nil)
+	}
+
+	Respond(w, Response{
+		Status:  e.Status,
+		Message: message,
+		Error:   body,
+	})
+}
+
+// localize falls back to the code itself when the request has no localizer or the lang files miss it
+func localize(localizer *i18n.Localizer, messageID string, data map[string]string) string {
+	if localizer == nil {
+		return messageID
+	}
+
+	message, err := localizer.Localize(&i18n.LocalizeConfig{
+		MessageID:    messageID,
+		TemplateData: data,
+	})
+	// a message missing in the language of the request comes back in Romanian along with the error
+	if err != nil && message == "" {
+		return messageID
+	}
+
+	return message
+}
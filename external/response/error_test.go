@@ -0,0 +1,74 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go-fitness/external/apperr"
+	"go-fitness/external/ctx/locale"
+	"golang.org/x/text/language"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newRequest(t *testing.T) *http.Request {
+	t.Helper()
+
+	bundle := i18n.NewBundle(language.Romanian)
+	bundle.MustAddMessages(language.English,
+		&i18n.Message{ID: "video_already_exists", Other: "The video already exists"},
+		&i18n.Message{ID: "required_field", Other: "The {{.Field}} field is required"},
+	)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	return r.WithContext(locale.New(r.Context(), bundle, "en"))
+}
+
+type body struct {
+	Message string    `json:"message"`
+	Error   ErrorBody `json:"error"`
+}
+
+func render(t *testing.T, err error) (int, body) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	Error(w, newRequest(t), err)
+
+	var b body
+	if err := json.Unmarshal(w.Body.Bytes(), &b); err != nil {
+		t.Fatal(err)
+	}
+
+	return w.Code, b
+}
+
+func TestErrorRendersCodeStatusAndLocalizedMessage(t *testing.T) {
+	status, b := render(t, fmt.Errorf("store: %w", apperr.Conflict("video_already_exists")))
+
+	if status != http.StatusConflict || b.Error.Code != "video_already_exists" || b.Message != "The video already exists" {
+		t.Fatalf("got %d %+v", status, b)
+	}
+}
+
+func TestErrorRendersFieldErrors(t *testing.T) {
+	status, b := render(t, apperr.Invalid(apperr.FieldError{Field: "Name", Code: "required_field"}))
+
+	if status != http.StatusBadRequest || b.Error.Code != "invalid_request" || len(b.Error.Fields) != 1 {
+		t.Fatalf("got %d %+v", status, b)
+	}
+	if field := b.Error.Fields[0]; field.Field != "Name" || field.Message != "The Name field is required" {
+		t.Fatalf("got field %+v", field)
+	}
+}
+
+func TestErrorHidesUntypedErrors(t *testing.T) {
+	status, b := render(t, errors.New("dial tcp 10.0.0.1:3306: connection refused"))
+
+	if status != http.StatusInternalServerError || b.Error.Code != "internal_server_error" || b.Message != "internal_server_error" {
+		t.Fatalf("got %d %+v", status, b)
+	}
+}
@@ -2,6 +2,7 @@ package response
 
 import (
 	"encoding/json"
+	"go-fitness/external/logger/sl"
 	"log/slog"
 	"net/http"
 )
@@ -10,6 +11,14 @@ type Response struct {
 	Status  int         `json:"status"`
 	Message string      `json:"message,omitempty"`
 	Data    interface{} `json:"data"`
+	Error   *ErrorBody  `json:"error,omitempty"`
+}
+
+type Paginated struct {
+	Items interface{} `json:"items"`
+	Total int64       `json:"total"`
+	Page  int64       `json:"page"`
+	Limit int64       `json:"limit"`
 }
 
 func Respond(w http.ResponseWriter, rd Response) {
@@ -19,14 +28,16 @@ func Respond(w http.ResponseWriter, rd Response) {
 	var response struct {
 		Message string      `json:"message,omitempty"`
 		Data    interface{} `json:"data"`
+		Error   *ErrorBody  `json:"error,omitempty"`
 	}
 
 	response.Message = rd.Message
 	response.Data = rd.Data
+	response.Error = rd.Error
 
 	responseJson, err := json.Marshal(response)
 	if err != nil {
-		slog.Error("failed to marshal response", err)
+		slog.Error("failed to marshal response", sl.Err(err))
 	}
 
 	_, err = w.Write(responseJson)
@@ -0,0 +1,39 @@
+package tracing
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const tracerName = "go-fitness/external/tracing"
+
+// Run runs cmd in a span named after the binary, so every ffmpeg and ffprobe call shows up under the request or task that started it
+func Run(ctx context.Context, cmd *exec.Cmd) error {
+	name := filepath.Base(cmd.Path)
+
+	_, span := otel.Tracer(tracerName).Start(ctx, "exec "+name,
+		trace.WithAttributes(
+			attribute.String("process.executable.name", name),
+			attribute.String("process.command_line", strings.Join(cmd.Args, " ")),
+		),
+	)
+	defer span.End()
+
+	err := cmd.Run()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	if cmd.ProcessState != nil {
+		span.SetAttributes(attribute.Int("process.exit.code", cmd.ProcessState.ExitCode()))
+	}
+
+	return err
+}
@@ -0,0 +1,100 @@
+// Package tracing installs the OpenTelemetry tracer provider the http middleware, repositories and ffmpeg runs record spans with
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go-fitness/external/config"
+	"go-fitness/external/logger/sl"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.uber.org/fx"
+	"log/slog"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Setup makes the configured exporter the global tracer provider, with none the global no-op provider stays
+// and the spans cost next to nothing
+func Setup(lc fx.Lifecycle, log *slog.Logger, cfg *config.Config) error {
+	const op = "tracing.Setup"
+
+	log = log.With(
+		sl.String("op", op),
+		sl.String("exporter", cfg.Tracing.Exporter),
+	)
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if cfg.Tracing.Exporter == ExporterNone || cfg.Tracing.Exporter == "" {
+		return nil
+	}
+
+	exporter, err := newExporter(cfg.Tracing)
+	if err != nil {
+		log.Error("failed to create trace exporter", sl.Err(err))
+		return err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.Tracing.ServiceName),
+		semconv.DeploymentEnvironment(cfg.Env),
+	))
+	if err != nil {
+		log.Error("failed to create trace resource", sl.Err(err))
+		return err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		// a sampled upload request keeps its transcode, the decision follows the parent span
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.Tracing.SampleRatio))),
+	)
+
+	otel.SetTracerProvider(provider)
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			log.Info("Exporting traces", sl.String("endpoint", cfg.Tracing.Endpoint))
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			log.Info("Flushing traces")
+			return provider.Shutdown(ctx)
+		},
+	})
+
+	return nil
+}
+
+func newExporter(cfg config.Tracing) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		return otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+}
+
+func NewTracing() fx.Option {
+	return fx.Module(
+		"tracing",
+		fx.Invoke(Setup),
+	)
+}
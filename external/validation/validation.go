@@ -1,78 +1,36 @@
 package validation
 
 import (
-	"fmt"
 	"github.com/go-playground/validator/v10"
-	"github.com/nicksnyder/go-i18n/v2/i18n"
-	"strings"
+	"go-fitness/external/apperr"
 )
 
-func ValidationError(localizer *i18n.Localizer, errs validator.ValidationErrors) error {
-	var errMsgs []string
+// codes are the message ids of the validation tags, a tag that is not listed is invalid_field
+var codes = map[string]string{
+	"required": "required_field",
+	"url":      "invalid_url",
+	"string":   "must_be_string",
+	"float64":  "must_be_float",
+	"int":      "must_be_int",
+	"min":      "min_value",
+	"max":      "max_value",
+}
+
+// Error turns the validator errors into an invalid_request with one field error per failed field
+func Error(errs validator.ValidationErrors) *apperr.Error {
+	fields := make([]apperr.FieldError, 0, len(errs))
 	for _, err := range errs {
-		var msg string
-		switch err.ActualTag() {
-		case "required":
-			msg = localizer.MustLocalize(&i18n.LocalizeConfig{
-				MessageID: "required_field",
-				TemplateData: map[string]string{
-					"Field": err.Field(),
-				},
-			})
-		case "url":
-			msg = localizer.MustLocalize(&i18n.LocalizeConfig{
-				MessageID: "invalid_url",
-				TemplateData: map[string]string{
-					"Field": err.Field(),
-				},
-			})
-		case "string":
-			msg = localizer.MustLocalize(&i18n.LocalizeConfig{
-				MessageID: "must_be_string",
-				TemplateData: map[string]string{
-					"Field": err.Field(),
-				},
-			})
-		case "float64":
-			msg = localizer.MustLocalize(&i18n.LocalizeConfig{
-				MessageID: "must_be_float",
-				TemplateData: map[string]string{
-					"Field": err.Field(),
-				},
-			})
-		case "int":
-			msg = localizer.MustLocalize(&i18n.LocalizeConfig{
-				MessageID: "must_be_int",
-				TemplateData: map[string]string{
-					"Field": err.Field(),
-				},
-			})
-		case "min":
-			msg = localizer.MustLocalize(&i18n.LocalizeConfig{
-				MessageID: "min_value",
-				TemplateData: map[string]string{
-					"Field": err.Field(),
-					"Param": err.Param(),
-				},
-			})
-		case "max":
-			msg = localizer.MustLocalize(&i18n.LocalizeConfig{
-				MessageID: "max_value",
-				TemplateData: map[string]string{
-					"Field": err.Field(),
-					"Param": err.Param(),
-				},
-			})
-		default:
-			msg = localizer.MustLocalize(&i18n.LocalizeConfig{
-				MessageID: "invalid_field",
-				TemplateData: map[string]string{
-					"Field": err.Field(),
-				},
-			})
+		code, ok := codes[err.ActualTag()]
+		if !ok {
+			code = "invalid_field"
 		}
-		errMsgs = append(errMsgs, msg)
+
+		fields = append(fields, apperr.FieldError{
+			Field: err.Field(),
+			Code:  code,
+			Param: err.Param(),
+		})
 	}
 
-	return fmt.Errorf(strings.Join(errMsgs, ", "))
+	return apperr.Invalid(fields...)
 }
@@ -5,8 +5,12 @@ import (
 	"github.com/patrickmn/go-cache"
 	"go-fitness/external/config"
 	"go-fitness/external/db"
+	"go-fitness/external/metrics"
+	"go-fitness/external/tracing"
+	"go-fitness/internal/api/event"
 	"go-fitness/internal/api/http/handler"
 	"go-fitness/internal/api/http/middleware"
+	"go-fitness/internal/api/migrations"
 	"go-fitness/internal/api/repository"
 	"go-fitness/internal/api/service"
 	"go.uber.org/fx"
@@ -14,12 +18,27 @@ import (
 
 func NewApp() *fx.App {
 	return fx.New(
+		Options(),
+		// invoked first so the schema is up to date before the workers and the server start
+		fx.Invoke(migrations.RunOnStart),
+		service.NewWorkers(),
+		fx.Invoke(RunServer),
+	)
+}
+
+// Options wires everything the app is made of without starting the http server or the background workers,
+// so the cli commands reuse the same services
+func Options() fx.Option {
+	return fx.Options(
 		fx.Options(
 			repository.NewRepository(),
 			service.NewService(),
 			handler.NewHandler(),
 			middleware.NewMiddleware(),
 			db.NewDataBase(),
+			event.NewEvent(),
+			metrics.NewMetrics(),
+			tracing.NewTracing(),
 		),
 		fx.Provide(
 			config.NewConfig,
@@ -29,10 +48,8 @@ func NewApp() *fx.App {
 			NewRouter,
 			NewConfiguredServer,
 			NewBundle,
-			NewLocalizer,
+			migrations.NewMigrator,
 		),
-		fx.Invoke(RunServer),
-		//fx.Invoke(cmd.NewCmd),
 	)
 }
 
@@ -9,3 +9,12 @@ type VideoData struct {
 	FileID     *string
 	UploadPath string
 }
+
+// PreparedUpload is an upload written to its folder and probed, it becomes a video once its row is stored
+type PreparedUpload struct {
+	Hash       string
+	UploadPath string
+	DstPath    string
+	Poster     string
+	Duration   float64
+}
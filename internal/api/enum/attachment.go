@@ -0,0 +1,43 @@
+package enum
+
+// AttachmentOwner is the kind of content a video can be attached to
+type AttachmentOwner string
+
+const (
+	AttachmentOwnerWorkout AttachmentOwner = "workout"
+	AttachmentOwnerGoal    AttachmentOwner = "goal"
+	AttachmentOwnerTab     AttachmentOwner = "tab"
+	AttachmentOwnerPortal  AttachmentOwner = "portal"
+)
+
+func (o AttachmentOwner) Valid() bool {
+	switch o {
+	case AttachmentOwnerWorkout, AttachmentOwnerGoal, AttachmentOwnerTab, AttachmentOwnerPortal:
+		return true
+	}
+
+	return false
+}
+
+// RequiresMain tells whether the owner can not exist without a main video
+func (o AttachmentOwner) RequiresMain() bool {
+	return o == AttachmentOwnerWorkout || o == AttachmentOwnerPortal
+}
+
+// AttachmentRole is what an attached video is used for by its owner
+type AttachmentRole string
+
+const (
+	AttachmentRoleMain    AttachmentRole = "main"
+	AttachmentRoleTrailer AttachmentRole = "trailer"
+	AttachmentRolePreview AttachmentRole = "preview"
+)
+
+func (r AttachmentRole) Valid() bool {
+	switch r {
+	case AttachmentRoleMain, AttachmentRoleTrailer, AttachmentRolePreview:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,9 @@
+package enum
+
+// CompletionSource tells how a workout was marked as completed
+type CompletionSource string
+
+const (
+	CompletionSourcePlayback CompletionSource = "playback"
+	CompletionSourceManual   CompletionSource = "manual"
+)
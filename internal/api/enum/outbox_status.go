@@ -0,0 +1,10 @@
+package enum
+
+// OutboxStatus is the delivery state of a domain event stored in the outbox
+type OutboxStatus string
+
+const (
+	OutboxStatusPending   OutboxStatus = "pending"
+	OutboxStatusDelivered OutboxStatus = "delivered"
+	OutboxStatusFailed    OutboxStatus = "failed"
+)
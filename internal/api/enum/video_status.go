@@ -11,5 +11,16 @@ const (
 )
 
 func (s VideoStatus) String() string {
-	return [...]string{"unknown", "processing", "processed", "failed"}[s]
+	return [...]string{"unknown", "processing", "processed", "failed", "disabled"}[s]
+}
+
+// ParseVideoStatus returns the status with the given name
+func ParseVideoStatus(name string) (VideoStatus, bool) {
+	for s := VideoStatusUnknown; s <= VideoStatusDisabled; s++ {
+		if s.String() == name {
+			return s, true
+		}
+	}
+
+	return VideoStatusUnknown, false
 }
@@ -0,0 +1,24 @@
+package event
+
+import "strings"
+
+const (
+	userChannelPrefix  = "private-user-"
+	adminChannelPrefix = "private-admin-"
+
+	// AdminTranscodeChannel receives the status changes of transcoded videos
+	AdminTranscodeChannel = adminChannelPrefix + "transcode"
+)
+
+// UserChannel is the private channel only the user with the uuid may subscribe to
+func UserChannel(userUUID string) string {
+	return userChannelPrefix + userUUID
+}
+
+func IsUserChannel(channel, userUUID string) bool {
+	return userUUID != "" && channel == UserChannel(userUUID)
+}
+
+func IsAdminChannel(channel string) bool {
+	return strings.HasPrefix(channel, adminChannelPrefix) && len(channel) > len(adminChannelPrefix)
+}
@@ -0,0 +1,61 @@
+package event
+
+import (
+	"encoding/json"
+	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/types"
+	"time"
+)
+
+// Names of the domain events written to the outbox
+const (
+	VideoUploaded    = "video.uploaded"
+	VideoProcessing  = "video.processing"
+	VideoProcessed   = "video.processed"
+	VideoFailed      = "video.failed"
+	WorkoutCompleted = "workout.completed"
+	VideoDeleted     = "video.deleted"
+	ExpAwarded       = "exp.awarded"
+)
+
+type VideoPayload struct {
+	VideoID int64  `json:"video_id"`
+	UUID    string `json:"uuid"`
+	Status  string `json:"status"`
+	// Permanent tells a video.deleted consumer whether the files are gone or only trashed
+	Permanent bool `json:"permanent,omitempty"`
+}
+
+type WorkoutCompletedPayload struct {
+	UserID       int64  `json:"user_id"`
+	UserUUID     string `json:"user_uuid"`
+	WorkoutID    int64  `json:"workout_id"`
+	CompletionID int64  `json:"completion_id"`
+	Source       string `json:"source"`
+}
+
+type ExpAwardedPayload struct {
+	UserID   int64  `json:"user_id"`
+	UserUUID string `json:"user_uuid"`
+	Amount   int    `json:"amount"`
+	Total    int64  `json:"total"`
+	Reason   string `json:"reason"`
+}
+
+// NewOutboxEvent builds a pending outbox row for the named event
+func NewOutboxEvent(name string, payload interface{}) (types.OutboxEvent, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return types.OutboxEvent{}, err
+	}
+
+	now := time.Now()
+
+	return types.OutboxEvent{
+		Name:          name,
+		Payload:       raw,
+		Status:        enum.OutboxStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}, nil
+}
@@ -1,6 +1,7 @@
 package event
 
 import (
+	"fmt"
 	"github.com/pusher/pusher-http-go/v5"
 	"go-fitness/external/logger/sl"
 	"log/slog"
@@ -15,6 +16,16 @@ type WSInterface interface {
 	TriggerEvent(e Event) error
 }
 
+// ChannelAuthorizer signs a subscription request to a private channel
+type ChannelAuthorizer interface {
+	AuthorizePrivateChannel(params []byte) ([]byte, error)
+}
+
+// Pinger checks that Pusher is reachable
+type Pinger interface {
+	Ping() error
+}
+
 type Event interface {
 	Channel() string
 	EventType() string
@@ -43,10 +54,22 @@ func (w *WebSocketClient) TriggerEvent(e Event) error {
 
 	if err := w.conn.Trigger(e.Channel(), e.EventType(), e.Data()); err != nil {
 		log.Error("failed to trigger event", sl.Err(err))
-		return nil
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	log.Info("event triggered")
 
 	return nil
 }
+
+// AuthorizePrivateChannel signs the socket_id and channel_name form of a subscription with the app secret,
+// the caller is responsible for checking that the channel may be joined
+func (w *WebSocketClient) AuthorizePrivateChannel(params []byte) ([]byte, error) {
+	return w.conn.AuthorizePrivateChannel(params)
+}
+
+// Ping asks the Pusher api for the channels of the app, which fails when it is unreachable or the credentials are wrong
+func (w *WebSocketClient) Ping() error {
+	_, err := w.conn.Channels(pusher.ChannelsParams{})
+	return err
+}
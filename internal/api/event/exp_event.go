@@ -1,14 +1,24 @@
 package event
 
+// ExpEvent tells a user how much exp was just awarded and the new total
 type ExpEvent struct {
+	userUUID string
+	amount   int
+	total    int64
+	reason   string
 }
 
-func NewNotificationEvent() *ExpEvent {
-	return &ExpEvent{}
+func NewExpEvent(userUUID string, amount int, total int64, reason string) *ExpEvent {
+	return &ExpEvent{
+		userUUID: userUUID,
+		amount:   amount,
+		total:    total,
+		reason:   reason,
+	}
 }
 
 func (e *ExpEvent) Channel() string {
-	return "exp"
+	return UserChannel(e.userUUID)
 }
 
 func (e *ExpEvent) EventType() string {
@@ -17,6 +27,8 @@ func (e *ExpEvent) EventType() string {
 
 func (e *ExpEvent) Data() map[string]interface{} {
 	return map[string]interface{}{
-		"message": "",
+		"amount": e.amount,
+		"total":  e.total,
+		"reason": e.reason,
 	}
 }
@@ -0,0 +1,44 @@
+package event
+
+import (
+	"github.com/pusher/pusher-http-go/v5"
+	"go-fitness/external/config"
+	"go.uber.org/fx"
+	"net"
+)
+
+func NewPusherClient(cfg *config.Config) pusher.Client {
+	return pusher.Client{
+		AppID:   cfg.WSServer.AppID,
+		Key:     cfg.WSServer.Key,
+		Secret:  cfg.WSServer.Secret,
+		Cluster: cfg.WSServer.Cluster,
+		Host:    net.JoinHostPort(cfg.WSServer.Host, cfg.WSServer.Port),
+		Secure:  cfg.WSServer.Secure,
+	}
+}
+
+func NewEvent() fx.Option {
+	return fx.Module(
+		"event",
+		fx.Provide(
+			NewPusherClient,
+			fx.Annotate(
+				NewPusherEvent,
+				fx.As(new(WSInterface)),
+				fx.As(new(ChannelAuthorizer)),
+				fx.As(new(Pinger)),
+			),
+			fx.Annotate(
+				NewPusherSink,
+				fx.As(new(Sink)),
+				fx.ResultTags(`group:"event_sinks"`),
+			),
+			fx.Annotate(
+				NewLogSink,
+				fx.As(new(Sink)),
+				fx.ResultTags(`group:"event_sinks"`),
+			),
+		),
+	)
+}
@@ -0,0 +1,97 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-fitness/external/logger/sl"
+	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/types"
+	"log/slog"
+)
+
+// Sink is a destination the outbox dispatcher delivers domain events to
+type Sink interface {
+	Name() string
+	Accepts(name string) bool
+	Deliver(ctx context.Context, e types.OutboxEvent) error
+}
+
+// PusherSink turns domain events into the websocket events clients and admins listen to
+type PusherSink struct {
+	ws WSInterface
+}
+
+func NewPusherSink(ws WSInterface) *PusherSink {
+	return &PusherSink{
+		ws: ws,
+	}
+}
+
+func (s *PusherSink) Name() string {
+	return "pusher"
+}
+
+func (s *PusherSink) Accepts(name string) bool {
+	switch name {
+	case ExpAwarded, VideoProcessed, VideoFailed:
+		return true
+	}
+
+	return false
+}
+
+func (s *PusherSink) Deliver(_ context.Context, e types.OutboxEvent) error {
+	switch e.Name {
+	case ExpAwarded:
+		var payload ExpAwardedPayload
+		if err := json.Unmarshal(e.Payload, &payload); err != nil {
+			return err
+		}
+
+		return s.ws.TriggerEvent(NewExpEvent(payload.UserUUID, payload.Amount, payload.Total, payload.Reason))
+	case VideoProcessed, VideoFailed:
+		var payload VideoPayload
+		if err := json.Unmarshal(e.Payload, &payload); err != nil {
+			return err
+		}
+
+		status := enum.VideoStatusProcessed
+		if e.Name == VideoFailed {
+			status = enum.VideoStatusFailed
+		}
+
+		return s.ws.TriggerEvent(NewTranscodeEvent(payload.VideoID, status))
+	}
+
+	return fmt.Errorf("pusher sink does not handle %s", e.Name)
+}
+
+// LogSink writes every domain event to the log
+type LogSink struct {
+	log *slog.Logger
+}
+
+func NewLogSink(log *slog.Logger) *LogSink {
+	return &LogSink{
+		log: log,
+	}
+}
+
+func (s *LogSink) Name() string {
+	return "log"
+}
+
+func (s *LogSink) Accepts(string) bool {
+	return true
+}
+
+func (s *LogSink) Deliver(_ context.Context, e types.OutboxEvent) error {
+	s.log.Info("domain event",
+		sl.Int64("event_id", e.ID),
+		sl.String("name", e.Name),
+		sl.String("payload", string(e.Payload)),
+	)
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package event
+
+import "go-fitness/internal/api/enum"
+
+// TranscodeEvent tells admins that a video finished or failed transcoding
+type TranscodeEvent struct {
+	videoID int64
+	status  enum.VideoStatus
+}
+
+func NewTranscodeEvent(videoID int64, status enum.VideoStatus) *TranscodeEvent {
+	return &TranscodeEvent{
+		videoID: videoID,
+		status:  status,
+	}
+}
+
+func (e *TranscodeEvent) Channel() string {
+	return AdminTranscodeChannel
+}
+
+func (e *TranscodeEvent) EventType() string {
+	return "transcode-status"
+}
+
+func (e *TranscodeEvent) Data() map[string]interface{} {
+	return map[string]interface{}{
+		"video_id": e.videoID,
+		"status":   e.status.String(),
+	}
+}
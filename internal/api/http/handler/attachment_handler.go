@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go-fitness/external/apperr"
+	"go-fitness/external/ctx/locale"
+	"go-fitness/external/logger/logctx"
+	"go-fitness/external/logger/sl"
+	"go-fitness/external/response"
+	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/types"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type AttachmentHandler struct {
+	log               *slog.Logger
+	attachmentService AttachmentService
+}
+
+type AttachmentService interface {
+	List(context.Context, enum.AttachmentOwner, int64) ([]types.VideoAttachment, error)
+	Detach(context.Context, enum.AttachmentOwner, int64, enum.AttachmentRole) error
+}
+
+func NewAttachmentHandler(
+	log *slog.Logger,
+	attachmentService AttachmentService,
+) *AttachmentHandler {
+	return &AttachmentHandler{
+		log:               log,
+		attachmentService: attachmentService,
+	}
+}
+
+// List returns the videos attached to a workout, goal, tab or portal video with their roles
+func (h *AttachmentHandler) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "AttachmentHandler.List"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		ownerID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("invalid owner id", sl.Err(err))
+			response.Error(w, r, apperr.BadRequest("invalid_request"))
+			return
+		}
+
+		attachments, err := h.attachmentService.List(r.Context(), enum.AttachmentOwner(chi.URLParam(r, "owner")), ownerID)
+		if err != nil {
+			log.Error("failed to get attachments", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status: http.StatusOK,
+			Data:   attachments,
+		})
+	}
+}
+
+// Detach removes the video of the owner in the role and releases it
+func (h *AttachmentHandler) Detach() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "AttachmentHandler.Detach"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		ownerID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("invalid owner id", sl.Err(err))
+			response.Error(w, r, apperr.BadRequest("invalid_request"))
+			return
+		}
+
+		if err = h.attachmentService.Detach(
+			r.Context(),
+			enum.AttachmentOwner(chi.URLParam(r, "owner")),
+			ownerID,
+			enum.AttachmentRole(chi.URLParam(r, "role")),
+		); err != nil {
+			log.Error("failed to detach video", sl.Err(err))
+
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusOK,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "video_detached_successfully"}),
+		})
+	}
+}
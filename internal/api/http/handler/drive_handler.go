@@ -4,6 +4,8 @@ package handler
 
 import (
 	"context"
+	"go-fitness/external/apperr"
+	"go-fitness/external/logger/logctx"
 	"go-fitness/external/logger/sl"
 	"go-fitness/external/response"
 	"log/slog"
@@ -36,7 +38,7 @@ func (h *DriveHandler) InitiateAuth() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op string = "handler.DriveHandler.InitiateAuth"
 
-		log := h.log.With(
+		log := logctx.Logger(r.Context(), h.log).With(
 			sl.String("op", op),
 		)
 
@@ -54,7 +56,7 @@ func (h *DriveHandler) HandleAuthCallback() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op string = "handler.DriveHandler.HandleAuthCallback"
 
-		log := h.log.With(
+		log := logctx.Logger(r.Context(), h.log).With(
 			sl.String("op", op),
 		)
 
@@ -65,14 +67,14 @@ func (h *DriveHandler) HandleAuthCallback() http.HandlerFunc {
 
 		if code == "" {
 			log.Error("No code in the request")
-			http.Error(w, "No code in the request", http.StatusBadRequest)
+			response.Error(w, r, apperr.BadRequest("invalid_request"))
 			return
 		}
 
 		err := h.driveManager.ExchangeCodeForToken(ctx, code)
 		if err != nil {
 			log.Error("Unable to exchange code for token", sl.Err(err))
-			http.Error(w, "Unable to exchange code for token", http.StatusInternalServerError)
+			response.Error(w, r, apperr.Internal("internal_server_error"))
 			return
 		}
 
@@ -85,7 +87,7 @@ func (h *DriveHandler) ProcessParse() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op string = "handler.DriveHandler.ProcessParse"
 
-		log := h.log.With(
+		log := logctx.Logger(r.Context(), h.log).With(
 			sl.String("op", op),
 		)
 
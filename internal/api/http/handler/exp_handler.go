@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go-fitness/external/apperr"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/ctx/locale"
+	"go-fitness/external/logger/logctx"
+	"go-fitness/external/logger/sl"
+	"go-fitness/external/response"
+	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/types"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type ExpHandler struct {
+	log        *slog.Logger
+	expService ExpService
+}
+
+type ExpService interface {
+	CompleteWorkout(context.Context, types.User, int64, enum.CompletionSource) (int, error)
+	GetTotal(context.Context, types.User) (int64, error)
+	Ledger(context.Context, types.User) ([]types.ExpEntry, int64, error)
+}
+
+func NewExpHandler(
+	log *slog.Logger,
+	expService ExpService,
+) *ExpHandler {
+	return &ExpHandler{
+		log:        log,
+		expService: expService,
+	}
+}
+
+// CompleteWorkout marks the workout as done by the authenticated user and awards exp
+func (h *ExpHandler) CompleteWorkout() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "ExpHandler.CompleteWorkout"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		user, ok := r.Context().Value("user").(types.User)
+		if !ok {
+			log.Error("user not found in context")
+			response.Error(w, r, apperr.Unauthorized("internal_server_error"))
+			return
+		}
+
+		workoutID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("invalid workout id", sl.Err(err))
+			response.Error(w, r, apperr.BadRequest("invalid_request"))
+			return
+		}
+
+		amount, err := h.expService.CompleteWorkout(r.Context(), user, workoutID, enum.CompletionSourceManual)
+		if err != nil {
+			log.Error("failed to complete workout", sl.Err(err))
+
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusOK,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "workout_completed_successfully"}),
+			Data:    map[string]int{"exp": amount},
+		})
+	}
+}
+
+// Total returns the experience total of the authenticated user
+func (h *ExpHandler) Total() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "ExpHandler.Total"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		user, ok := r.Context().Value("user").(types.User)
+		if !ok {
+			log.Error("user not found in context")
+			response.Error(w, r, apperr.Unauthorized("internal_server_error"))
+			return
+		}
+
+		total, err := h.expService.GetTotal(r.Context(), user)
+		if err != nil {
+			log.Error("failed to get exp total", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status: http.StatusOK,
+			Data:   map[string]int64{"total": total},
+		})
+	}
+}
+
+// Ledger returns a page of the exp entries of the authenticated user
+func (h *ExpHandler) Ledger() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "ExpHandler.Ledger"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		user, ok := r.Context().Value("user").(types.User)
+		if !ok {
+			log.Error("user not found in context")
+			response.Error(w, r, apperr.Unauthorized("internal_server_error"))
+			return
+		}
+
+		ctx := filter.GetContextWithFilters(r)
+
+		entries, total, err := h.expService.Ledger(ctx, user)
+		if err != nil {
+			log.Error("failed to get exp ledger", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		f := filter.FromContext(ctx).Normalized()
+
+		response.Respond(w, response.Response{
+			Status: http.StatusOK,
+			Data: response.Paginated{
+				Items: entries,
+				Total: total,
+				Page:  f.Page,
+				Limit: f.Limit,
+			},
+		})
+	}
+}
@@ -1,8 +1,11 @@
 package fileupload
 
 import (
+	"errors"
+	"go-fitness/external/apperr"
 	"go-fitness/external/logger/sl"
 	"go-fitness/internal/api/data"
+	"go-fitness/internal/api/enum"
 	"log/slog"
 	"mime/multipart"
 	"net/http"
@@ -17,13 +20,24 @@ func ParseAndExtractFile(r *http.Request, log *slog.Logger) (*data.VideoData, er
 
 	if err := r.ParseMultipartForm(8 << 30); err != nil {
 		log.Error("failed_to_parse_multipart_form", sl.Err(err))
-		return nil, err
+
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) || errors.Is(err, multipart.ErrMessageTooLarge) {
+			return nil, apperr.TooLarge("file_too_large")
+		}
+
+		return nil, apperr.BadRequest("failed_to_parse_multipart_form")
 	}
 
 	file, header, err := r.FormFile("file")
 	if err != nil {
 		log.Error("failed_to_get_form_file", sl.Err(err))
-		return nil, err
+
+		if errors.Is(err, http.ErrMissingFile) {
+			return nil, apperr.BadRequest("no_file_provided")
+		}
+
+		return nil, apperr.BadRequest("failed_to_get_form_file")
 	}
 	defer func(file multipart.File) {
 		if err := file.Close(); err != nil {
@@ -38,3 +52,12 @@ func ParseAndExtractFile(r *http.Request, log *slog.Logger) (*data.VideoData, er
 
 	return videoData, nil
 }
+
+// Role reads the optional role form value of an upload, main when it is not given
+func Role(r *http.Request) enum.AttachmentRole {
+	if role := r.FormValue("role"); role != "" {
+		return enum.AttachmentRole(role)
+	}
+
+	return enum.AttachmentRoleMain
+}
@@ -5,13 +5,23 @@ import (
 )
 
 type Handlers struct {
-	Video   *VideoHandler
-	Workout *WorkoutHandler
-	Drive   *DriveHandler
-	Goal    *GoalHandler
-	Tab     *TabHandler
-	Poster  *PosterHandler
-	Portal  *PortalHandler
+	Video      *VideoHandler
+	Workout    *WorkoutHandler
+	Drive      *DriveHandler
+	Goal       *GoalHandler
+	Tab        *TabHandler
+	Poster     *PosterHandler
+	Portal     *PortalHandler
+	Program    *ProgramHandler
+	Playback   *PlaybackHandler
+	Exp        *ExpHandler
+	Pusher     *PusherHandler
+	Outbox     *OutboxHandler
+	Webhook    *WebhookHandler
+	Attachment *AttachmentHandler
+	GC         *GCHandler
+	Scheduler  *SchedulerHandler
+	Health     *HealthHandler
 }
 
 func NewHandlers(
@@ -22,15 +32,35 @@ func NewHandlers(
 	tab *TabHandler,
 	poster *PosterHandler,
 	portal *PortalHandler,
+	program *ProgramHandler,
+	playback *PlaybackHandler,
+	exp *ExpHandler,
+	pusher *PusherHandler,
+	outbox *OutboxHandler,
+	webhook *WebhookHandler,
+	attachment *AttachmentHandler,
+	gc *GCHandler,
+	scheduler *SchedulerHandler,
+	health *HealthHandler,
 ) *Handlers {
 	return &Handlers{
-		Video:   video,
-		Workout: workout,
-		Drive:   drive,
-		Goal:    goal,
-		Tab:     tab,
-		Poster:  poster,
-		Portal:  portal,
+		Video:      video,
+		Workout:    workout,
+		Drive:      drive,
+		Goal:       goal,
+		Tab:        tab,
+		Poster:     poster,
+		Portal:     portal,
+		Program:    program,
+		Playback:   playback,
+		Exp:        exp,
+		Pusher:     pusher,
+		Outbox:     outbox,
+		Webhook:    webhook,
+		Attachment: attachment,
+		GC:         gc,
+		Scheduler:  scheduler,
+		Health:     health,
 	}
 }
 
@@ -46,6 +76,16 @@ func NewHandler() fx.Option {
 			NewGoalHandler,
 			NewTabHandler,
 			NewPosterHandler,
+			NewProgramHandler,
+			NewPlaybackHandler,
+			NewExpHandler,
+			NewPusherHandler,
+			NewOutboxHandler,
+			NewWebhookHandler,
+			NewAttachmentHandler,
+			NewGCHandler,
+			NewSchedulerHandler,
+			NewHealthHandler,
 			NewHandlers,
 		),
 	)
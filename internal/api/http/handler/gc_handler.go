@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"context"
+	"go-fitness/external/apperr"
+	"go-fitness/external/logger/logctx"
+	"go-fitness/external/logger/sl"
+	"go-fitness/external/response"
+	"go-fitness/internal/api/types"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type GCHandler struct {
+	log       *slog.Logger
+	gcService GCService
+}
+
+type GCService interface {
+	Run(context.Context, bool) (types.GCReport, error)
+	LastReport() (types.GCReport, error)
+}
+
+func NewGCHandler(
+	log *slog.Logger,
+	gcService GCService,
+) *GCHandler {
+	return &GCHandler{
+		log:       log,
+		gcService: gcService,
+	}
+}
+
+// Report returns the report of the latest garbage collection run
+func (h *GCHandler) Report() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "GCHandler.Report"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		report, err := h.gcService.LastReport()
+		if err != nil {
+			log.Warn("no gc report", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status: http.StatusOK,
+			Data:   report,
+		})
+	}
+}
+
+// Run collects garbage right away and returns the report, ?dry_run=false is needed to remove anything
+func (h *GCHandler) Run() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "GCHandler.Run"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		dryRun := true
+		if value := r.URL.Query().Get("dry_run"); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				log.Warn("invalid dry_run", sl.Err(err))
+				response.Error(w, r, apperr.BadRequest("invalid_request"))
+				return
+			}
+
+			dryRun = parsed
+		}
+
+		report, err := h.gcService.Run(context.WithoutCancel(r.Context()), dryRun)
+		if err != nil {
+			log.Error("failed to run gc", sl.Err(err))
+
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status: http.StatusOK,
+			Data:   report,
+		})
+	}
+}
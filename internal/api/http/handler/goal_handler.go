@@ -5,10 +5,16 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go-fitness/external/apperr"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/ctx/locale"
+	"go-fitness/external/logger/logctx"
 	"go-fitness/external/logger/sl"
 	"go-fitness/external/response"
 	"go-fitness/internal/api/data"
+	"go-fitness/internal/api/enum"
 	"go-fitness/internal/api/http/handler/fileupload"
+	"go-fitness/internal/api/types"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -18,23 +24,23 @@ type GoalHandler struct {
 	log         *slog.Logger
 	goalService GoalUploadService
 	validation  *validator.Validate
-	localizer   *i18n.Localizer
 }
 
 type GoalUploadService interface {
-	Upload(ctx context.Context, goalID int, videData *data.VideoData) error
+	Upload(ctx context.Context, goalID int, role enum.AttachmentRole, videoData *data.VideoData) error
+	SoftDelete(ctx context.Context, id int) error
+	Restore(ctx context.Context, id int) error
+	List(ctx context.Context) ([]types.Goal, int64, error)
 }
 
 func NewGoalHandler(
 	log *slog.Logger,
 	goalService GoalUploadService,
 	validator *validator.Validate,
-	localizer *i18n.Localizer,
 ) *GoalHandler {
 	return &GoalHandler{
 		log:         log,
 		validation:  validator,
-		localizer:   localizer,
 		goalService: goalService,
 	}
 }
@@ -43,7 +49,7 @@ func (h *GoalHandler) VideoUpload() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "GoalHandler.VideoUpload"
 
-		log := h.log.With(
+		log := logctx.Logger(r.Context(), h.log).With(
 			sl.String("op", op),
 		)
 
@@ -53,29 +59,117 @@ func (h *GoalHandler) VideoUpload() http.HandlerFunc {
 
 		videoData, err := fileupload.ParseAndExtractFile(r, log)
 		if err != nil {
-			response.Respond(w, response.Response{
-				Status:  http.StatusRequestEntityTooLarge,
-				Message: h.localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "internal_server_error"}),
-				Data:    err.Error(),
-			})
+			response.Error(w, r, err)
 			return
 		}
 
-		goalID, _ := strconv.Atoi(chi.URLParam(r, "id"))
+		goalID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Error("invalid goal id", sl.Err(err))
+			response.Error(w, r, apperr.BadRequest("invalid_request"))
+			return
+		}
 
-		if err = h.goalService.Upload(ctx, goalID, videoData); err != nil {
+		if err = h.goalService.Upload(ctx, goalID, fileupload.Role(r), videoData); err != nil {
 			log.Error("failed_to_process_upload", sl.Err(err))
-			response.Respond(w, response.Response{
-				Status:  http.StatusConflict,
-				Message: h.localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: err.Error()}),
-			})
+			response.Error(w, r, err)
 			return
 		}
 
 		response.Respond(w, response.Response{
 			Status:  http.StatusOK,
-			Message: h.localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "goal_updated_successfully"}),
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "goal_updated_successfully"}),
 		})
 		return
 	}
 }
+
+// Delete soft deletes the goal so it is hidden from clients
+func (h *GoalHandler) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "GoalHandler.Delete"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		goalID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Error("invalid goal id", sl.Err(err))
+			response.Error(w, r, apperr.BadRequest("invalid_request"))
+			return
+		}
+
+		if err = h.goalService.SoftDelete(r.Context(), goalID); err != nil {
+			log.Error("failed to delete goal", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusOK,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "goal_deleted_successfully"}),
+		})
+	}
+}
+
+// Restore makes a soft deleted goal visible again
+func (h *GoalHandler) Restore() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "GoalHandler.Restore"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		goalID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Error("invalid goal id", sl.Err(err))
+			response.Error(w, r, apperr.BadRequest("invalid_request"))
+			return
+		}
+
+		if err = h.goalService.Restore(r.Context(), goalID); err != nil {
+			log.Error("failed to restore goal", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusOK,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "goal_restored_successfully"}),
+		})
+	}
+}
+
+// List returns a page of goals narrowed by search, filters[...], sort, page and limit
+func (h *GoalHandler) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "GoalHandler.List"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		ctx := filter.GetContextWithFilters(r)
+
+		goals, total, err := h.goalService.List(ctx)
+		if err != nil {
+			log.Error("failed to get goals", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		f := filter.FromContext(ctx).Normalized()
+
+		response.Respond(w, response.Response{
+			Status: http.StatusOK,
+			Data: response.Paginated{
+				Items: goals,
+				Total: total,
+				Page:  f.Page,
+				Limit: f.Limit,
+			},
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+	"go-fitness/external/response"
+	"go-fitness/internal/api/types"
+	"log/slog"
+	"net/http"
+)
+
+type HealthHandler struct {
+	log           *slog.Logger
+	healthService HealthService
+}
+
+type HealthService interface {
+	Live() types.HealthReport
+	Ready(context.Context) types.HealthReport
+}
+
+func NewHealthHandler(
+	log *slog.Logger,
+	healthService HealthService,
+) *HealthHandler {
+	return &HealthHandler{
+		log:           log,
+		healthService: healthService,
+	}
+}
+
+// Livez answers as long as the process serves requests
+func (h *HealthHandler) Livez() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response.Respond(w, response.Response{
+			Status: http.StatusOK,
+			Data:   h.healthService.Live(),
+		})
+	}
+}
+
+// Readyz reports every dependency check with its timing, 503 when a critical one failed
+func (h *HealthHandler) Readyz() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := h.healthService.Ready(r.Context())
+
+		status := http.StatusOK
+		if report.Status == types.HealthFail {
+			status = http.StatusServiceUnavailable
+		}
+
+		response.Respond(w, response.Response{
+			Status: status,
+			Data:   report,
+		})
+	}
+}
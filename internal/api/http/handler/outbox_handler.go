@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go-fitness/external/apperr"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/ctx/locale"
+	"go-fitness/external/logger/logctx"
+	"go-fitness/external/logger/sl"
+	"go-fitness/external/response"
+	"go-fitness/internal/api/types"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type OutboxHandler struct {
+	log           *slog.Logger
+	outboxService OutboxService
+}
+
+type OutboxService interface {
+	List(context.Context) ([]types.OutboxEvent, int64, error)
+	Retry(context.Context, int64) error
+}
+
+func NewOutboxHandler(
+	log *slog.Logger,
+	outboxService OutboxService,
+) *OutboxHandler {
+	return &OutboxHandler{
+		log:           log,
+		outboxService: outboxService,
+	}
+}
+
+// List returns a page of domain events with their delivery status, filterable by name and status
+func (h *OutboxHandler) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "OutboxHandler.List"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		ctx := filter.GetContextWithFilters(r)
+
+		events, total, err := h.outboxService.List(ctx)
+		if err != nil {
+			log.Error("failed to get outbox events", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		f := filter.FromContext(ctx).Normalized()
+
+		response.Respond(w, response.Response{
+			Status: http.StatusOK,
+			Data: response.Paginated{
+				Items: events,
+				Total: total,
+				Page:  f.Page,
+				Limit: f.Limit,
+			},
+		})
+	}
+}
+
+// Retry queues a failed event for another round of deliveries
+func (h *OutboxHandler) Retry() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "OutboxHandler.Retry"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("invalid event id", sl.Err(err))
+			response.Error(w, r, apperr.BadRequest("invalid_request"))
+			return
+		}
+
+		if err = h.outboxService.Retry(r.Context(), id); err != nil {
+			log.Error("failed to retry event", sl.Err(err))
+
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusOK,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "event_retry_scheduled"}),
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go-fitness/external/apperr"
+	"go-fitness/external/ctx/locale"
+	"go-fitness/external/logger/logctx"
+	"go-fitness/external/logger/sl"
+	"go-fitness/external/response"
+	"go-fitness/external/validation"
+	"go-fitness/internal/api/http/request"
+	"go-fitness/internal/api/types"
+	"log/slog"
+	"net/http"
+)
+
+type PlaybackHandler struct {
+	log             *slog.Logger
+	playbackService PlaybackService
+	validation      *validator.Validate
+}
+
+type PlaybackService interface {
+	SavePosition(context.Context, types.User, string, float64) (types.VideoPosition, error)
+	GetPosition(context.Context, types.User, string) (types.VideoPosition, error)
+	ContinueWatching(context.Context, types.User) ([]types.ContinueWatching, error)
+}
+
+func NewPlaybackHandler(
+	log *slog.Logger,
+	playbackService PlaybackService,
+	validator *validator.Validate,
+) *PlaybackHandler {
+	return &PlaybackHandler{
+		log:             log,
+		playbackService: playbackService,
+		validation:      validator,
+	}
+}
+
+// SavePosition stores the playback position of the authenticated user in the video
+func (h *PlaybackHandler) SavePosition() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "PlaybackHandler.SavePosition"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		user, ok := r.Context().Value("user").(types.User)
+		if !ok {
+			log.Error("user not found in context")
+			response.Error(w, r, apperr.Unauthorized("internal_server_error"))
+			return
+		}
+
+		var req request.VideoSavePositionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			response.Error(w, r, apperr.BadRequest("invalid_request"))
+			return
+		}
+
+		var validateErr validator.ValidationErrors
+		if err := h.validation.Struct(req); err != nil {
+			errors.As(err, &validateErr)
+			log.Error("invalid request", sl.Err(validateErr))
+			response.Error(w, r, validation.Error(validateErr))
+			return
+		}
+
+		position, err := h.playbackService.SavePosition(r.Context(), user, chi.URLParam(r, "uuid"), req.Position)
+		if err != nil {
+			log.Error("failed to save position", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusOK,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "position_saved_successfully"}),
+			Data:    position,
+		})
+	}
+}
+
+// GetPosition returns where the authenticated user stopped in the video
+func (h *PlaybackHandler) GetPosition() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "PlaybackHandler.GetPosition"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		user, ok := r.Context().Value("user").(types.User)
+		if !ok {
+			log.Error("user not found in context")
+			response.Error(w, r, apperr.Unauthorized("internal_server_error"))
+			return
+		}
+
+		position, err := h.playbackService.GetPosition(r.Context(), user, chi.URLParam(r, "uuid"))
+		if err != nil {
+			log.Error("failed to get position", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status: http.StatusOK,
+			Data:   position,
+		})
+	}
+}
+
+// ContinueWatching lists the unfinished videos of the authenticated user, most recent first
+func (h *PlaybackHandler) ContinueWatching() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "PlaybackHandler.ContinueWatching"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		user, ok := r.Context().Value("user").(types.User)
+		if !ok {
+			log.Error("user not found in context")
+			response.Error(w, r, apperr.Unauthorized("internal_server_error"))
+			return
+		}
+
+		items, err := h.playbackService.ContinueWatching(r.Context(), user)
+		if err != nil {
+			log.Error("failed to get continue watching", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status: http.StatusOK,
+			Data:   items,
+		})
+	}
+}
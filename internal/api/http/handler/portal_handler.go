@@ -2,12 +2,17 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"github.com/go-playground/validator/v10"
-	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/logger/logctx"
 	"go-fitness/external/logger/sl"
 	"go-fitness/external/response"
+	"go-fitness/external/validation"
 	"go-fitness/internal/api/data"
 	"go-fitness/internal/api/http/handler/fileupload"
+	"go-fitness/internal/api/http/request"
+	"go-fitness/internal/api/types"
 	"log/slog"
 	"net/http"
 )
@@ -16,23 +21,21 @@ type PortalHandler struct {
 	log           *slog.Logger
 	portalService PortalService
 	validation    *validator.Validate
-	localizer     *i18n.Localizer
 }
 
 type PortalService interface {
-	Upload(context.Context, *data.VideoData) error
+	Upload(context.Context, string, *data.VideoData) error
+	List(context.Context) ([]types.Portal, int64, error)
 }
 
 func NewPortalHandler(
 	log *slog.Logger,
 	portalService PortalService,
 	validator *validator.Validate,
-	localizer *i18n.Localizer,
 ) *PortalHandler {
 	return &PortalHandler{
 		log:           log,
 		validation:    validator,
-		localizer:     localizer,
 		portalService: portalService,
 	}
 }
@@ -41,7 +44,7 @@ func (h *PortalHandler) VideoUpload() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "PortalHandler.VideoUpload"
 
-		log := h.log.With(
+		log := logctx.Logger(r.Context(), h.log).With(
 			sl.String("op", op),
 		)
 
@@ -51,22 +54,27 @@ func (h *PortalHandler) VideoUpload() http.HandlerFunc {
 
 		videoData, err := fileupload.ParseAndExtractFile(r, log)
 		if err != nil {
-			response.Respond(w, response.Response{
-				Status:  http.StatusRequestEntityTooLarge,
-				Message: h.localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "internal_server_error"}),
-				Data:    err.Error(),
-			})
+			response.Error(w, r, err)
+			return
+		}
+
+		req := request.PortalRequest{
+			Name: r.FormValue("name"),
+		}
+
+		var validateErr validator.ValidationErrors
+		if err = h.validation.Struct(req); err != nil {
+			errors.As(err, &validateErr)
+			log.Error("invalid request", sl.Err(validateErr))
+			response.Error(w, r, validation.Error(validateErr))
 			return
 		}
 
 		log.Info("uploading video")
 
-		if err = h.portalService.Upload(ctx, videoData); err != nil {
+		if err = h.portalService.Upload(ctx, req.Name, videoData); err != nil {
 			log.Error("failed_to_process_upload", sl.Err(err))
-			response.Respond(w, response.Response{
-				Status:  http.StatusConflict,
-				Message: h.localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: err.Error()}),
-			})
+			response.Error(w, r, err)
 			return
 		}
 
@@ -77,3 +85,35 @@ func (h *PortalHandler) VideoUpload() http.HandlerFunc {
 		return
 	}
 }
+
+// List returns a page of portals narrowed by search, filters[...], sort, page and limit
+func (h *PortalHandler) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "PortalHandler.List"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		ctx := filter.GetContextWithFilters(r)
+
+		portals, total, err := h.portalService.List(ctx)
+		if err != nil {
+			log.Error("failed to get portals", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		f := filter.FromContext(ctx).Normalized()
+
+		response.Respond(w, response.Response{
+			Status: http.StatusOK,
+			Data: response.Paginated{
+				Items: portals,
+				Total: total,
+				Page:  f.Page,
+				Limit: f.Limit,
+			},
+		})
+	}
+}
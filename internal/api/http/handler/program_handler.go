@@ -0,0 +1,381 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go-fitness/external/apperr"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/ctx/locale"
+	"go-fitness/external/logger/logctx"
+	"go-fitness/external/logger/sl"
+	"go-fitness/external/response"
+	"go-fitness/external/validation"
+	"go-fitness/internal/api/data"
+	"go-fitness/internal/api/http/request"
+	"go-fitness/internal/api/types"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type ProgramHandler struct {
+	log            *slog.Logger
+	programService ProgramService
+	validation     *validator.Validate
+}
+
+type ProgramService interface {
+	List(ctx context.Context) ([]types.Program, int64, error)
+	Store(ctx context.Context, data data.ProgramData) (int64, error)
+	Update(ctx context.Context, data data.ProgramData) error
+	GetTree(ctx context.Context, programID int64) (types.ProgramTree, error)
+	AddMonth(ctx context.Context, programID int64, month int) error
+	DeleteMonth(ctx context.Context, programID int64, month int) error
+	AttachWorkout(ctx context.Context, programID int64, month int, workoutID int64) error
+	DetachWorkout(ctx context.Context, programID int64, month int, workoutID int64) error
+	ReorderWorkouts(ctx context.Context, programID int64, month int, workoutIDs []int64) error
+}
+
+func NewProgramHandler(
+	log *slog.Logger,
+	programService ProgramService,
+	validator *validator.Validate,
+) *ProgramHandler {
+	return &ProgramHandler{
+		log:            log,
+		programService: programService,
+		validation:     validator,
+	}
+}
+
+// List returns a page of programs narrowed by search, filters[...], sort, page and limit
+func (h *ProgramHandler) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "ProgramHandler.List"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		ctx := filter.GetContextWithFilters(r)
+
+		programs, total, err := h.programService.List(ctx)
+		if err != nil {
+			log.Error("failed to get programs", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		f := filter.FromContext(ctx).Normalized()
+
+		response.Respond(w, response.Response{
+			Status: http.StatusOK,
+			Data: response.Paginated{
+				Items: programs,
+				Total: total,
+				Page:  f.Page,
+				Limit: f.Limit,
+			},
+		})
+	}
+}
+
+func (h *ProgramHandler) Store() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "ProgramHandler.Store"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		var req request.ProgramRequest
+		if !h.decode(w, r, log, &req) {
+			return
+		}
+
+		programID, err := h.programService.Store(r.Context(), data.ProgramData{
+			ProgramName: req.Name,
+			GoalID:      req.GoalID,
+			LevelID:     req.LevelID,
+			PeriodID:    req.PeriodID,
+		})
+		if err != nil {
+			log.Error("failed to store program", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusCreated,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "program_stored_successfully"}),
+			Data:    map[string]int64{"id": programID},
+		})
+	}
+}
+
+func (h *ProgramHandler) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "ProgramHandler.Update"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		programID, ok := h.programID(w, r, log)
+		if !ok {
+			return
+		}
+
+		var req request.ProgramRequest
+		if !h.decode(w, r, log, &req) {
+			return
+		}
+
+		if err := h.programService.Update(r.Context(), data.ProgramData{
+			ProgramID:   programID,
+			ProgramName: req.Name,
+			GoalID:      req.GoalID,
+			LevelID:     req.LevelID,
+			PeriodID:    req.PeriodID,
+		}); err != nil {
+			log.Error("failed to update program", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusOK,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "program_updated_successfully"}),
+		})
+	}
+}
+
+// GetTree returns the program with its months and workouts in one response
+func (h *ProgramHandler) GetTree() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "ProgramHandler.GetTree"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		programID, ok := h.programID(w, r, log)
+		if !ok {
+			return
+		}
+
+		tree, err := h.programService.GetTree(r.Context(), programID)
+		if err != nil {
+			log.Error("failed to get program", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status: http.StatusOK,
+			Data:   tree,
+		})
+	}
+}
+
+func (h *ProgramHandler) AddMonth() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "ProgramHandler.AddMonth"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		programID, ok := h.programID(w, r, log)
+		if !ok {
+			return
+		}
+
+		var req request.ProgramMonthRequest
+		if !h.decode(w, r, log, &req) {
+			return
+		}
+
+		if err := h.programService.AddMonth(r.Context(), programID, req.Month); err != nil {
+			log.Error("failed to add program month", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusCreated,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "program_updated_successfully"}),
+		})
+	}
+}
+
+func (h *ProgramHandler) DeleteMonth() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "ProgramHandler.DeleteMonth"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		programID, month, ok := h.programMonth(w, r, log)
+		if !ok {
+			return
+		}
+
+		if err := h.programService.DeleteMonth(r.Context(), programID, month); err != nil {
+			log.Error("failed to delete program month", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusOK,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "program_updated_successfully"}),
+		})
+	}
+}
+
+func (h *ProgramHandler) AttachWorkout() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "ProgramHandler.AttachWorkout"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		programID, month, ok := h.programMonth(w, r, log)
+		if !ok {
+			return
+		}
+
+		var req request.ProgramMonthWorkoutRequest
+		if !h.decode(w, r, log, &req) {
+			return
+		}
+
+		if err := h.programService.AttachWorkout(r.Context(), programID, month, req.WorkoutID); err != nil {
+			log.Error("failed to attach workout", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusOK,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "program_updated_successfully"}),
+		})
+	}
+}
+
+func (h *ProgramHandler) DetachWorkout() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "ProgramHandler.DetachWorkout"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		programID, month, ok := h.programMonth(w, r, log)
+		if !ok {
+			return
+		}
+
+		workoutID, err := strconv.ParseInt(chi.URLParam(r, "workoutID"), 10, 64)
+		if err != nil {
+			log.Error("invalid workout id", sl.Err(err))
+			response.Error(w, r, apperr.BadRequest("invalid_request"))
+			return
+		}
+
+		if err = h.programService.DetachWorkout(r.Context(), programID, month, workoutID); err != nil {
+			log.Error("failed to detach workout", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusOK,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "program_updated_successfully"}),
+		})
+	}
+}
+
+func (h *ProgramHandler) ReorderWorkouts() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "ProgramHandler.ReorderWorkouts"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		programID, month, ok := h.programMonth(w, r, log)
+		if !ok {
+			return
+		}
+
+		var req request.WorkoutReorderRequest
+		if !h.decode(w, r, log, &req) {
+			return
+		}
+
+		if err := h.programService.ReorderWorkouts(r.Context(), programID, month, req.IDs); err != nil {
+			log.Error("failed to reorder workouts", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusOK,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "workouts_reordered_successfully"}),
+		})
+	}
+}
+
+// decode reads the JSON body into req and validates it, writing the error response when it fails
+func (h *ProgramHandler) decode(w http.ResponseWriter, r *http.Request, log *slog.Logger, req interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		log.Error("failed to decode request", sl.Err(err))
+		response.Error(w, r, apperr.BadRequest("invalid_request"))
+		return false
+	}
+
+	var validateErr validator.ValidationErrors
+	if err := h.validation.Struct(req); err != nil {
+		errors.As(err, &validateErr)
+		log.Error("invalid request", sl.Err(validateErr))
+		response.Error(w, r, validation.Error(validateErr))
+		return false
+	}
+
+	return true
+}
+
+func (h *ProgramHandler) programID(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	programID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		log.Error("invalid program id", sl.Err(err))
+		response.Error(w, r, apperr.BadRequest("invalid_request"))
+		return 0, false
+	}
+
+	return programID, true
+}
+
+func (h *ProgramHandler) programMonth(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, int, bool) {
+	programID, ok := h.programID(w, r, log)
+	if !ok {
+		return 0, 0, false
+	}
+
+	month, err := strconv.Atoi(chi.URLParam(r, "month"))
+	if err != nil {
+		log.Error("invalid month", sl.Err(err))
+		response.Error(w, r, apperr.BadRequest("invalid_request"))
+		return 0, 0, false
+	}
+
+	return programID, month, true
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"go-fitness/external/apperr"
+	"go-fitness/external/logger/logctx"
+	"go-fitness/external/logger/sl"
+	"go-fitness/external/response"
+	"go-fitness/internal/api/event"
+	"go-fitness/internal/api/types"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+)
+
+type PusherHandler struct {
+	log        *slog.Logger
+	authorizer event.ChannelAuthorizer
+}
+
+func NewPusherHandler(
+	log *slog.Logger,
+	authorizer event.ChannelAuthorizer,
+) *PusherHandler {
+	return &PusherHandler{
+		log:        log,
+		authorizer: authorizer,
+	}
+}
+
+// ClientAuth signs subscriptions of the authenticated user to their own private-user channel
+func (h *PusherHandler) ClientAuth() http.HandlerFunc {
+	return h.authorize("PusherHandler.ClientAuth", func(channel string, user types.User) bool {
+		return event.IsUserChannel(channel, user.UUID)
+	})
+}
+
+// AdminAuth signs subscriptions of admins to private-admin channels and to their own user channel
+func (h *PusherHandler) AdminAuth() http.HandlerFunc {
+	return h.authorize("PusherHandler.AdminAuth", func(channel string, user types.User) bool {
+		return event.IsAdminChannel(channel) || event.IsUserChannel(channel, user.UUID)
+	})
+}
+
+func (h *PusherHandler) authorize(op string, allowed func(string, types.User) bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		user, ok := r.Context().Value("user").(types.User)
+		if !ok {
+			log.Error("user not found in context")
+			response.Error(w, r, apperr.Unauthorized("internal_server_error"))
+			return
+		}
+
+		params, err := io.ReadAll(r.Body)
+		if err != nil {
+			log.Error("failed to read request", sl.Err(err))
+			response.Error(w, r, apperr.BadRequest("invalid_request"))
+			return
+		}
+
+		form, err := url.ParseQuery(string(params))
+		if err != nil || form.Get("socket_id") == "" || form.Get("channel_name") == "" {
+			log.Warn("invalid subscription request", sl.Err(err))
+			response.Error(w, r, apperr.BadRequest("invalid_request"))
+			return
+		}
+
+		channel := form.Get("channel_name")
+
+		log = log.With(
+			sl.Int64("user_id", user.ID),
+			sl.String("channel", channel),
+		)
+
+		if !allowed(channel, user) {
+			log.Warn("subscription forbidden")
+			response.Error(w, r, apperr.Forbidden("channel_forbidden"))
+			return
+		}
+
+		signature, err := h.authorizer.AuthorizePrivateChannel(params)
+		if err != nil {
+			log.Error("failed to authorize channel", sl.Err(err))
+			response.Error(w, r, apperr.Forbidden("channel_forbidden"))
+			return
+		}
+
+		// pusher clients expect the bare signature, not the usual response envelope
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if _, err = w.Write(signature); err != nil {
+			log.Error("failed to write response", sl.Err(err))
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/ctx/locale"
+	"go-fitness/external/logger/logctx"
+	"go-fitness/external/logger/sl"
+	"go-fitness/external/response"
+	"go-fitness/internal/api/types"
+	"log/slog"
+	"net/http"
+)
+
+type SchedulerHandler struct {
+	log              *slog.Logger
+	schedulerService SchedulerService
+}
+
+type SchedulerService interface {
+	List(context.Context) ([]types.Job, error)
+	Runs(context.Context) ([]types.JobRun, int64, error)
+	Trigger(context.Context, string) error
+}
+
+func NewSchedulerHandler(
+	log *slog.Logger,
+	schedulerService SchedulerService,
+) *SchedulerHandler {
+	return &SchedulerHandler{
+		log:              log,
+		schedulerService: schedulerService,
+	}
+}
+
+// List returns the registered jobs with their schedule, next run and latest run
+func (h *SchedulerHandler) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "SchedulerHandler.List"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		jobs, err := h.schedulerService.List(r.Context())
+		if err != nil {
+			log.Error("failed to get jobs", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status: http.StatusOK,
+			Data:   jobs,
+		})
+	}
+}
+
+// Runs returns a page of the run history, filterable by job, trigger and status
+func (h *SchedulerHandler) Runs() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "SchedulerHandler.Runs"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		ctx := filter.GetContextWithFilters(r)
+
+		runs, total, err := h.schedulerService.Runs(ctx)
+		if err != nil {
+			log.Error("failed to get job runs", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		f := filter.FromContext(ctx).Normalized()
+
+		response.Respond(w, response.Response{
+			Status: http.StatusOK,
+			Data: response.Paginated{
+				Items: runs,
+				Total: total,
+				Page:  f.Page,
+				Limit: f.Limit,
+			},
+		})
+	}
+}
+
+// Trigger starts the job in the background, its outcome shows up in the run history
+func (h *SchedulerHandler) Trigger() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "SchedulerHandler.Trigger"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		if err := h.schedulerService.Trigger(r.Context(), chi.URLParam(r, "name")); err != nil {
+			log.Warn("failed to trigger job", sl.Err(err))
+
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusAccepted,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "job_triggered"}),
+		})
+	}
+}
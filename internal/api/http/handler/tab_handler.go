@@ -6,12 +6,18 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go-fitness/external/apperr"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/ctx/locale"
+	"go-fitness/external/logger/logctx"
 	"go-fitness/external/logger/sl"
 	"go-fitness/external/response"
 	"go-fitness/external/validation"
 	"go-fitness/internal/api/data"
+	"go-fitness/internal/api/enum"
 	"go-fitness/internal/api/http/handler/fileupload"
 	"go-fitness/internal/api/http/request"
+	"go-fitness/internal/api/types"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -21,24 +27,24 @@ type TabHandler struct {
 	log        *slog.Logger
 	tabService TabService
 	validation *validator.Validate
-	localizer  *i18n.Localizer
 }
 
 type TabService interface {
 	Store(context.Context, data.TabData) error
-	UpdateVideo(context.Context, int, *data.VideoData) error
+	UpdateVideo(context.Context, int, enum.AttachmentRole, *data.VideoData) error
+	SoftDelete(context.Context, int) error
+	Restore(context.Context, int) error
+	List(context.Context) ([]types.Tab, int64, error)
 }
 
 func NewTabHandler(
 	log *slog.Logger,
 	tabService TabService,
-	localizer *i18n.Localizer,
 	validator *validator.Validate,
 ) *TabHandler {
 	return &TabHandler{
 		log:        log,
 		validation: validator,
-		localizer:  localizer,
 		tabService: tabService,
 	}
 }
@@ -47,7 +53,7 @@ func (h *TabHandler) Store() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "TabHandler.StoreGoal"
 
-		log := h.log.With(
+		log := logctx.Logger(r.Context(), h.log).With(
 			sl.String("op", op),
 		)
 
@@ -57,11 +63,7 @@ func (h *TabHandler) Store() http.HandlerFunc {
 
 		videoData, err := fileupload.ParseAndExtractFile(r, log)
 		if err != nil {
-			response.Respond(w, response.Response{
-				Status:  http.StatusRequestEntityTooLarge,
-				Message: h.localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "internal_server_error"}),
-				Data:    err.Error(),
-			})
+			response.Error(w, r, err)
 			return
 		}
 
@@ -74,10 +76,7 @@ func (h *TabHandler) Store() http.HandlerFunc {
 		if err = h.validation.Struct(req); err != nil {
 			errors.As(err, &validateErr)
 			log.Error("invalid request", sl.Err(validateErr))
-			response.Respond(w, response.Response{
-				Status:  http.StatusBadRequest,
-				Message: validation.ValidationError(h.localizer, validateErr).Error(),
-			})
+			response.Error(w, r, validation.Error(validateErr))
 			return
 		}
 
@@ -89,16 +88,13 @@ func (h *TabHandler) Store() http.HandlerFunc {
 			VideoData:   videoData,
 		}); err != nil {
 			log.Error("failed_to_process_upload", sl.Err(err))
-			response.Respond(w, response.Response{
-				Status:  http.StatusConflict,
-				Message: h.localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: err.Error()}),
-			})
+			response.Error(w, r, err)
 			return
 		}
 
 		response.Respond(w, response.Response{
 			Status:  http.StatusOK,
-			Message: h.localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "tab_stored_successfully"}),
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "tab_stored_successfully"}),
 		})
 		return
 	}
@@ -108,7 +104,7 @@ func (h *TabHandler) UpdateVideo() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "TabHandler.UpdateGoal"
 
-		log := h.log.With(
+		log := logctx.Logger(r.Context(), h.log).With(
 			sl.String("op", op),
 		)
 
@@ -116,28 +112,116 @@ func (h *TabHandler) UpdateVideo() http.HandlerFunc {
 
 		videoData, err := fileupload.ParseAndExtractFile(r, log)
 		if err != nil {
-			response.Respond(w, response.Response{
-				Status:  http.StatusRequestEntityTooLarge,
-				Message: h.localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "internal_server_error"}),
-				Data:    err.Error(),
-			})
+			response.Error(w, r, err)
 			return
 		}
 
-		tabID, _ := strconv.Atoi(chi.URLParam(r, "id"))
+		tabID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Error("invalid tab id", sl.Err(err))
+			response.Error(w, r, apperr.BadRequest("invalid_request"))
+			return
+		}
 
-		if err = h.tabService.UpdateVideo(ctx, tabID, videoData); err != nil {
+		if err = h.tabService.UpdateVideo(ctx, tabID, fileupload.Role(r), videoData); err != nil {
 			log.Error("failed_to_process_upload", sl.Err(err))
-			response.Respond(w, response.Response{
-				Status:  http.StatusConflict,
-				Message: h.localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: err.Error()}),
-			})
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusOK,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "tab_updated_successfully"}),
+		})
+	}
+}
+
+// Delete soft deletes the tab so it is hidden from clients
+func (h *TabHandler) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "TabHandler.Delete"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		tabID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Error("invalid tab id", sl.Err(err))
+			response.Error(w, r, apperr.BadRequest("invalid_request"))
+			return
+		}
+
+		if err = h.tabService.SoftDelete(r.Context(), tabID); err != nil {
+			log.Error("failed to delete tab", sl.Err(err))
+			response.Error(w, r, err)
 			return
 		}
 
 		response.Respond(w, response.Response{
 			Status:  http.StatusOK,
-			Message: h.localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "tab_updated_successfully"}),
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "tab_deleted_successfully"}),
+		})
+	}
+}
+
+// Restore makes a soft deleted tab visible again
+func (h *TabHandler) Restore() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "TabHandler.Restore"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		tabID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Error("invalid tab id", sl.Err(err))
+			response.Error(w, r, apperr.BadRequest("invalid_request"))
+			return
+		}
+
+		if err = h.tabService.Restore(r.Context(), tabID); err != nil {
+			log.Error("failed to restore tab", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusOK,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "tab_restored_successfully"}),
+		})
+	}
+}
+
+// List returns a page of tabs narrowed by search, filters[...], sort, page and limit
+func (h *TabHandler) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "TabHandler.List"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		ctx := filter.GetContextWithFilters(r)
+
+		tabs, total, err := h.tabService.List(ctx)
+		if err != nil {
+			log.Error("failed to get tabs", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		f := filter.FromContext(ctx).Normalized()
+
+		response.Respond(w, response.Response{
+			Status: http.StatusOK,
+			Data: response.Paginated{
+				Items: tabs,
+				Total: total,
+				Page:  f.Page,
+				Limit: f.Limit,
+			},
 		})
 	}
 }
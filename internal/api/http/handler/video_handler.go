@@ -6,8 +6,14 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go-fitness/external/apperr"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/ctx/locale"
+	"go-fitness/external/logger/logctx"
 	"go-fitness/external/logger/sl"
+	"go-fitness/external/metrics"
 	"go-fitness/external/response"
+	"go-fitness/internal/api/types"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -20,51 +26,29 @@ type VideoHandler struct {
 	log          *slog.Logger
 	videoService VideoService
 	validation   *validator.Validate
-	localizer    *i18n.Localizer
+	metrics      *metrics.Metrics
 }
 
 type VideoService interface {
 	ProcessGetVideoPlayListByUUID(context.Context, string) ([]byte, error)
 	ProcessGetVideoTS(context.Context, string) ([]byte, error)
-	ProcessGetVideoM3U8(string) ([]byte, error)
-	DeleteAllVideoFilesIfDoestExistInTable(context.Context)
+	ProcessGetVideoM3U8(context.Context, string) ([]byte, error)
+	SoftDelete(context.Context, string) error
+	Restore(context.Context, string) error
+	List(context.Context) ([]types.Video, int64, error)
 }
 
 func NewVideoHandler(
 	log *slog.Logger,
 	videoService VideoService,
-	localizer *i18n.Localizer,
 	validator *validator.Validate,
+	metrics *metrics.Metrics,
 ) *VideoHandler {
 	return &VideoHandler{
 		log:          log,
 		videoService: videoService,
 		validation:   validator,
-		localizer:    localizer,
-	}
-}
-
-func (h *VideoHandler) DeleteVideoFilesIfDoesntExistInTable() http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		const op = "VideoHandler.DeleteAllVideos"
-
-		log := h.log.With(
-			sl.String("op", op),
-		)
-
-		log.Info("deleting all videos")
-
-		go func() {
-			ctx := context.WithoutCancel(r.Context())
-			h.videoService.DeleteAllVideoFilesIfDoestExistInTable(ctx)
-		}()
-
-		response.Respond(w, response.Response{
-			Status:  http.StatusOK,
-			Message: "ok",
-			Data:    "ok",
-		})
-		return
+		metrics:      metrics,
 	}
 }
 
@@ -73,7 +57,7 @@ func (h *VideoHandler) GetVideo() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op string = "VideoHandler.GetVideo"
 
-		log := h.log.With(
+		log := logctx.Logger(r.Context(), h.log).With(
 			sl.String("op", op),
 		)
 
@@ -83,21 +67,23 @@ func (h *VideoHandler) GetVideo() http.HandlerFunc {
 		var (
 			video []byte
 			err   error
+			kind  = "playlist"
 		)
 
 		switch {
 		case strings.Contains(r.URL.Path, ".ts"):
+			kind = "segment"
 			video, err = h.videoService.ProcessGetVideoTS(ctx, r.URL.Path)
 			w.Header().Set("Content-Type", "video/mp2ts")
 		case strings.Contains(r.URL.Path, ".m3u8"):
-			video, err = h.videoService.ProcessGetVideoM3U8(r.URL.Path)
+			video, err = h.videoService.ProcessGetVideoM3U8(ctx, r.URL.Path)
 			w.Header().Set("Content-Type", "application/x-mpegURL")
 			w.Header().Set("Cache-Control", "no-cache")
 		default:
 			videoUUID := chi.URLParam(r, "uuid")
 			if videoUUID == "" {
 				log.Error("uuid is required")
-				http.Error(w, "UUID is required", http.StatusBadRequest)
+				response.Error(w, r, apperr.BadRequest("invalid_request"))
 				return
 			}
 
@@ -108,7 +94,7 @@ func (h *VideoHandler) GetVideo() http.HandlerFunc {
 
 		if err != nil {
 			log.Error("failed to get video", sl.Err(err))
-			http.Error(w, "internal server error", http.StatusInternalServerError)
+			response.Error(w, r, err)
 			return
 		}
 
@@ -120,7 +106,10 @@ func (h *VideoHandler) GetVideo() http.HandlerFunc {
 			log.Warn("context canceled before writing video")
 			return
 		default:
-			if _, err := w.Write(video); err != nil {
+			n, err := w.Write(video)
+			h.metrics.BytesServed.WithLabelValues(kind).Add(float64(n))
+
+			if err != nil {
 				if errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) {
 					log.Warn("Client disconnected before response could be sent", sl.Err(err))
 					return
@@ -131,3 +120,79 @@ func (h *VideoHandler) GetVideo() http.HandlerFunc {
 		}
 	}
 }
+
+// Delete soft deletes the video so it is no longer served to clients
+func (h *VideoHandler) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "VideoHandler.Delete"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		if err := h.videoService.SoftDelete(r.Context(), chi.URLParam(r, "uuid")); err != nil {
+			log.Error("failed to delete video", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusOK,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "video_deleted_successfully"}),
+		})
+	}
+}
+
+// Restore makes a soft deleted video visible again
+func (h *VideoHandler) Restore() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "VideoHandler.Restore"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		if err := h.videoService.Restore(r.Context(), chi.URLParam(r, "uuid")); err != nil {
+			log.Error("failed to restore video", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusOK,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "video_restored_successfully"}),
+		})
+	}
+}
+
+// List returns a page of videos narrowed by search, filters[...], sort, page and limit
+func (h *VideoHandler) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "VideoHandler.List"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		ctx := filter.GetContextWithFilters(r)
+
+		videos, total, err := h.videoService.List(ctx)
+		if err != nil {
+			log.Error("failed to get videos", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		f := filter.FromContext(ctx).Normalized()
+
+		response.Respond(w, response.Response{
+			Status: http.StatusOK,
+			Data: response.Paginated{
+				Items: videos,
+				Total: total,
+				Page:  f.Page,
+				Limit: f.Limit,
+			},
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/logger/logctx"
+	"go-fitness/external/logger/sl"
+	"go-fitness/external/response"
+	"go-fitness/internal/api/types"
+	"log/slog"
+	"net/http"
+)
+
+type WebhookHandler struct {
+	log            *slog.Logger
+	webhookService WebhookService
+}
+
+type WebhookService interface {
+	Webhooks() []types.Webhook
+	Deliveries(context.Context) ([]types.WebhookDelivery, int64, error)
+}
+
+func NewWebhookHandler(
+	log *slog.Logger,
+	webhookService WebhookService,
+) *WebhookHandler {
+	return &WebhookHandler{
+		log:            log,
+		webhookService: webhookService,
+	}
+}
+
+// List returns the configured webhook subscriptions
+func (h *WebhookHandler) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response.Respond(w, response.Response{
+			Status: http.StatusOK,
+			Data:   h.webhookService.Webhooks(),
+		})
+	}
+}
+
+// Deliveries returns a page of the delivery log, filterable by event_id, webhook, event and status_code
+func (h *WebhookHandler) Deliveries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "WebhookHandler.Deliveries"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		ctx := filter.GetContextWithFilters(r)
+
+		deliveries, total, err := h.webhookService.Deliveries(ctx)
+		if err != nil {
+			log.Error("failed to get webhook deliveries", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		f := filter.FromContext(ctx).Normalized()
+
+		response.Respond(w, response.Response{
+			Status: http.StatusOK,
+			Data: response.Paginated{
+				Items: deliveries,
+				Total: total,
+				Page:  f.Page,
+				Limit: f.Limit,
+			},
+		})
+	}
+}
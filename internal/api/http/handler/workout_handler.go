@@ -2,41 +2,54 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go-fitness/external/apperr"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/ctx/locale"
+	"go-fitness/external/logger/logctx"
 	"go-fitness/external/logger/sl"
 	"go-fitness/external/response"
 	"go-fitness/external/validation"
 	"go-fitness/internal/api/data"
+	"go-fitness/internal/api/enum"
 	"go-fitness/internal/api/http/handler/fileupload"
 	"go-fitness/internal/api/http/request"
+	"go-fitness/internal/api/types"
 	"log/slog"
 	"net/http"
+	"strconv"
 )
 
 type WorkoutHandler struct {
 	log            *slog.Logger
 	workoutService WorkoutService
 	validation     *validator.Validate
-	localizer      *i18n.Localizer
 }
 
 type WorkoutService interface {
 	ProcessWorkout(ctx context.Context, data data.WorkoutData) error
+	SoftDelete(ctx context.Context, id int64) error
+	Restore(ctx context.Context, id int64) error
+	List(ctx context.Context) ([]types.Workout, int64, error)
+	Get(ctx context.Context, id int64) (types.Workout, error)
+	Update(ctx context.Context, id int64, data data.WorkoutData) error
+	ReplaceVideo(ctx context.Context, id int64, role enum.AttachmentRole, videoData *data.VideoData) error
+	Reorder(ctx context.Context, ids []int64) error
 }
 
 func NewWorkoutHandler(
 	log *slog.Logger,
 	workoutService WorkoutService,
-	localizer *i18n.Localizer,
 	validator *validator.Validate,
 ) *WorkoutHandler {
 	return &WorkoutHandler{
 		log:            log,
 		workoutService: workoutService,
 		validation:     validator,
-		localizer:      localizer,
 	}
 }
 
@@ -46,18 +59,14 @@ func (h *WorkoutHandler) StoreWorkout() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "WorkoutHandler.StoreWorkout"
 
-		log := h.log.With(
+		log := logctx.Logger(r.Context(), h.log).With(
 			sl.String("op", op),
 		)
 
 		ctx := r.Context()
 		videoData, err := fileupload.ParseAndExtractFile(r, log)
 		if err != nil {
-			response.Respond(w, response.Response{
-				Status:  http.StatusRequestEntityTooLarge,
-				Message: h.localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "internal_server_error"}),
-				Data:    err.Error(),
-			})
+			response.Error(w, r, err)
 			return
 		}
 
@@ -70,10 +79,7 @@ func (h *WorkoutHandler) StoreWorkout() http.HandlerFunc {
 		if err := h.validation.Struct(req); err != nil {
 			errors.As(err, &validateErr)
 			log.Error("invalid request", sl.Err(validateErr))
-			response.Respond(w, response.Response{
-				Status:  http.StatusBadRequest,
-				Message: validation.ValidationError(h.localizer, validateErr).Error(),
-			})
+			response.Error(w, r, validation.Error(validateErr))
 			return
 		}
 
@@ -87,17 +93,252 @@ func (h *WorkoutHandler) StoreWorkout() http.HandlerFunc {
 
 		if err = h.workoutService.ProcessWorkout(ctx, workoutData); err != nil {
 			log.Error("failed_to_process_upload", sl.Err(err))
-			response.Respond(w, response.Response{
-				Status:  http.StatusConflict,
-				Message: h.localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: err.Error()}),
-			})
+			response.Error(w, r, err)
 			return
 		}
 
 		response.Respond(w, response.Response{
 			Status:  http.StatusOK,
-			Message: h.localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "workout_created_successfully"}),
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "workout_created_successfully"}),
 		})
 		return
 	}
 }
+
+// Delete soft deletes the workout so it is hidden from clients
+func (h *WorkoutHandler) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "WorkoutHandler.Delete"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		workoutID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("invalid workout id", sl.Err(err))
+			response.Error(w, r, apperr.BadRequest("invalid_request"))
+			return
+		}
+
+		if err = h.workoutService.SoftDelete(r.Context(), workoutID); err != nil {
+			log.Error("failed to delete workout", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusOK,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "workout_deleted_successfully"}),
+		})
+	}
+}
+
+// Restore makes a soft deleted workout visible again
+func (h *WorkoutHandler) Restore() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "WorkoutHandler.Restore"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		workoutID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("invalid workout id", sl.Err(err))
+			response.Error(w, r, apperr.BadRequest("invalid_request"))
+			return
+		}
+
+		if err = h.workoutService.Restore(r.Context(), workoutID); err != nil {
+			log.Error("failed to restore workout", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusOK,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "workout_restored_successfully"}),
+		})
+	}
+}
+
+// List returns a page of workouts narrowed by search, filters[...], sort, page and limit
+func (h *WorkoutHandler) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "WorkoutHandler.List"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		ctx := filter.GetContextWithFilters(r)
+
+		workouts, total, err := h.workoutService.List(ctx)
+		if err != nil {
+			log.Error("failed to get workouts", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		f := filter.FromContext(ctx).Normalized()
+
+		response.Respond(w, response.Response{
+			Status: http.StatusOK,
+			Data: response.Paginated{
+				Items: workouts,
+				Total: total,
+				Page:  f.Page,
+				Limit: f.Limit,
+			},
+		})
+	}
+}
+
+// Get returns a single workout with the uuid of its video
+func (h *WorkoutHandler) Get() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "WorkoutHandler.Get"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		workoutID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("invalid workout id", sl.Err(err))
+			response.Error(w, r, apperr.BadRequest("invalid_request"))
+			return
+		}
+
+		workout, err := h.workoutService.Get(r.Context(), workoutID)
+		if err != nil {
+			log.Error("failed to get workout", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status: http.StatusOK,
+			Data:   workout,
+		})
+	}
+}
+
+// Update changes the workout name and description
+func (h *WorkoutHandler) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "WorkoutHandler.Update"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		workoutID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("invalid workout id", sl.Err(err))
+			response.Error(w, r, apperr.BadRequest("invalid_request"))
+			return
+		}
+
+		req := request.VideoUpdateRequest{
+			Name:        r.FormValue("name"),
+			Description: r.FormValue("description"),
+		}
+
+		var validateErr validator.ValidationErrors
+		if err = h.validation.Struct(req); err != nil {
+			errors.As(err, &validateErr)
+			log.Error("invalid request", sl.Err(validateErr))
+			response.Error(w, r, validation.Error(validateErr))
+			return
+		}
+
+		if err = h.workoutService.Update(r.Context(), workoutID, data.WorkoutData{
+			Name:        req.Name,
+			Description: req.Description,
+		}); err != nil {
+			log.Error("failed to update workout", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusOK,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "workout_updated_successfully"}),
+		})
+	}
+}
+
+// ReplaceVideo uploads a new video for the workout in the role given by the form, main by default
+func (h *WorkoutHandler) ReplaceVideo() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "WorkoutHandler.ReplaceVideo"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		workoutID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("invalid workout id", sl.Err(err))
+			response.Error(w, r, apperr.BadRequest("invalid_request"))
+			return
+		}
+
+		videoData, err := fileupload.ParseAndExtractFile(r, log)
+		if err != nil {
+			response.Error(w, r, err)
+			return
+		}
+
+		if err = h.workoutService.ReplaceVideo(r.Context(), workoutID, fileupload.Role(r), videoData); err != nil {
+			log.Error("failed to replace workout video", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusOK,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "workout_updated_successfully"}),
+		})
+	}
+}
+
+// Reorder stores the order of workouts given as a JSON list of ids
+func (h *WorkoutHandler) Reorder() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "WorkoutHandler.Reorder"
+
+		log := logctx.Logger(r.Context(), h.log).With(
+			sl.String("op", op),
+		)
+
+		var req request.WorkoutReorderRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			response.Error(w, r, apperr.BadRequest("invalid_request"))
+			return
+		}
+
+		var validateErr validator.ValidationErrors
+		if err := h.validation.Struct(req); err != nil {
+			errors.As(err, &validateErr)
+			log.Error("invalid request", sl.Err(validateErr))
+			response.Error(w, r, validation.Error(validateErr))
+			return
+		}
+
+		if err := h.workoutService.Reorder(r.Context(), req.IDs); err != nil {
+			log.Error("failed to reorder workouts", sl.Err(err))
+			response.Error(w, r, err)
+			return
+		}
+
+		response.Respond(w, response.Response{
+			Status:  http.StatusOK,
+			Message: locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "workouts_reordered_successfully"}),
+		})
+	}
+}
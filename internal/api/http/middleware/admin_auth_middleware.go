@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-playground/validator/v10"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/patrickmn/go-cache"
+	"go-fitness/external/apperr"
 	"go-fitness/external/config"
+	"go-fitness/external/ctx/locale"
+	"go-fitness/external/logger/logctx"
 	"go-fitness/external/logger/sl"
 	"go-fitness/external/response"
 	"go-fitness/internal/api/service"
@@ -52,9 +54,8 @@ func (m *AdminAuthMiddleware) New() func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			const op = "http.middleware.AuthMiddleware.New"
 
-			log := m.log.With(
+			log := logctx.Logger(r.Context(), m.log).With(
 				sl.String("op", op),
-				sl.String("request_id", middleware.GetReqID(r.Context())),
 			)
 
 			unauthorizedResponse := func(logMessage string, err error) {
@@ -63,10 +64,7 @@ func (m *AdminAuthMiddleware) New() func(next http.Handler) http.Handler {
 				} else {
 					log.Warn(logMessage)
 				}
-				response.Respond(w, response.Response{
-					Status:  http.StatusUnauthorized,
-					Message: "Unauthorized",
-				})
+				response.Error(w, r, apperr.Unauthorized("unauthorized"))
 			}
 
 			tokenHeader := r.Header.Get("Authorization")
@@ -83,7 +81,9 @@ func (m *AdminAuthMiddleware) New() func(next http.Handler) http.Handler {
 				return
 			}
 
-			ctx := context.WithValue(r.Context(), "user", user)
+			ctx := logctx.With(r.Context(), sl.String("user_uuid", user.UUID))
+			ctx = locale.Prefer(ctx, m.userService.GetLocale(ctx, user.ID))
+			ctx = context.WithValue(ctx, "user", user)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -94,11 +94,6 @@ func (m *AdminAuthMiddleware) validateTokenAndGetUser(
 	secretKey []byte,
 	log *slog.Logger,
 ) (types.User, error) {
-	log = log.With(
-		sl.String("token", tokenString),
-		sl.String("secret_key", string(secretKey)),
-	)
-
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			log.Error("unexpected signing method", sl.String("alg", fmt.Sprintf("%v", token.Header["alg"])))
@@ -113,18 +108,18 @@ func (m *AdminAuthMiddleware) validateTokenAndGetUser(
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok || !token.Valid {
-		log.Error("invalid token", sl.String("token", tokenString))
+		log.Error("invalid token")
 		return types.User{}, errors.New("invalid token")
 	}
 
 	exp, ok := claims["exp"].(float64)
 	if !ok {
-		log.Error("token does not contain exp", sl.String("token", tokenString))
+		log.Error("token does not contain exp")
 		return types.User{}, errors.New("token does not contain exp")
 	}
 
 	if time.Now().Unix() > int64(exp) {
-		log.Error("token has expired", sl.String("token", tokenString))
+		log.Error("token has expired")
 		return types.User{}, errors.New("token has expired")
 	}
 
@@ -133,7 +128,7 @@ func (m *AdminAuthMiddleware) validateTokenAndGetUser(
 
 	userUUID, ok := claims["user_uuid"].(string)
 	if !ok {
-		log.Error("token does not contain user UUID", sl.String("token", tokenString))
+		log.Error("token does not contain user UUID")
 		return types.User{}, errors.New("token does not contain user UUID")
 	}
 
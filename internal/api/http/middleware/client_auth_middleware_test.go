@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"context"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/patrickmn/go-cache"
+	"go-fitness/external/config"
+	"go-fitness/external/ctx/locale"
+	"go-fitness/external/db"
+	"go-fitness/internal/api/migrations"
+	"go-fitness/internal/api/repository"
+	"go-fitness/internal/api/service"
+	"golang.org/x/text/language"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientAuthMiddlewareProfileLanguageWinsOverHeader(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	conn, err := db.OpenSqlite(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	database := db.New(conn, db.SQLite)
+
+	migrator, err := migrations.NewMigrator(log, conn, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = conn.ExecContext(ctx, `
+		INSERT INTO users (uuid, name, email, email_verified_at, locale) VALUES
+			('with-locale', 'a', 'a@example.com', CURRENT_TIMESTAMP, 'en'),
+			('without-locale', 'b', 'b@example.com', CURRENT_TIMESTAMP, NULL)
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	bundle := i18n.NewBundle(language.Romanian)
+	bundle.MustAddMessages(language.Romanian, &i18n.Message{ID: "video_not_found", Other: "Videoclipul nu a fost găsit"})
+	bundle.MustAddMessages(language.English, &i18n.Message{ID: "video_not_found", Other: "The video was not found"})
+
+	cfg := &config.Config{}
+	cfg.JWT = "secret"
+
+	auth := NewClientAuthMiddleware(log, cache.New(time.Minute, time.Minute), service.NewUserService(log, repository.NewUserRepository(database)), cfg)
+
+	handler := NewLocaleMiddleware(bundle).New()(auth.New()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, locale.Localizer(r.Context()).MustLocalize(&i18n.LocalizeConfig{MessageID: "video_not_found"}))
+	})))
+
+	tests := []struct {
+		name     string
+		userUUID string
+		want     string
+	}{
+		{name: "stored locale", userUUID: "with-locale", want: "The video was not found"},
+		{name: "no stored locale", userUUID: "without-locale", want: "Videoclipul nu a fost găsit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				"user_uuid": tt.userUUID,
+				"exp":       time.Now().Add(time.Hour).Unix(),
+			}).SignedString([]byte(cfg.JWT))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Authorization", "Bearer "+token)
+			r.Header.Set("Accept-Language", "ro")
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != http.StatusOK || w.Body.String() != tt.want {
+				t.Fatalf("got %d %q, want %q", w.Code, w.Body.String(), tt.want)
+			}
+		})
+	}
+}
@@ -6,17 +6,26 @@ import (
 
 type Middleware struct {
 	Logger               *LoggerMiddleware
+	Metrics              *MetricsMiddleware
+	Tracing              *TracingMiddleware
+	Locale               *LocaleMiddleware
 	ClientAuthMiddleware *ClientAuthMiddleware
 	AdminAuthMiddleware  *AdminAuthMiddleware
 }
 
 func NewMiddlewares(
 	logger *LoggerMiddleware,
+	metrics *MetricsMiddleware,
+	tracing *TracingMiddleware,
+	locale *LocaleMiddleware,
 	clientAuth *ClientAuthMiddleware,
 	adminAuth *AdminAuthMiddleware,
 ) *Middleware {
 	return &Middleware{
 		Logger:               logger,
+		Metrics:              metrics,
+		Tracing:              tracing,
+		Locale:               locale,
 		ClientAuthMiddleware: clientAuth,
 		AdminAuthMiddleware:  adminAuth,
 	}
@@ -27,6 +36,9 @@ func NewMiddleware() fx.Option {
 		"middleware",
 		fx.Provide(
 			NewLoggerMiddleware,
+			NewMetricsMiddleware,
+			NewTracingMiddleware,
+			NewLocaleMiddleware,
 			NewClientAuthMiddleware,
 			NewAdminAuthMiddleware,
 			NewMiddlewares,
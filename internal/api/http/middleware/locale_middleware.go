@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go-fitness/external/ctx/locale"
+	"net/http"
+)
+
+type LocaleMiddleware struct {
+	bundle *i18n.Bundle
+}
+
+func NewLocaleMiddleware(bundle *i18n.Bundle) *LocaleMiddleware {
+	return &LocaleMiddleware{
+		bundle: bundle,
+	}
+}
+
+// New puts a localizer negotiated from Accept-Language in the request context,
+// the auth middlewares put the language of the user profile in front of it
+func (m *LocaleMiddleware) New() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Language")
+
+			ctx := locale.New(r.Context(), m.bundle, r.Header.Get("Accept-Language"))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -1,25 +1,36 @@
 package middleware
 
 import (
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"go-fitness/external/config"
+	"go-fitness/external/logger/logctx"
 	"go-fitness/external/logger/sl"
 	"log/slog"
+	"math/rand"
 	"net/http"
+	"path"
 	"runtime"
+	"time"
 )
 
 type LoggerMiddleware struct {
 	log *slog.Logger
+	cfg *config.Config
 }
 
 func NewLoggerMiddleware(
 	log *slog.Logger,
+	cfg *config.Config,
 ) *LoggerMiddleware {
 	return &LoggerMiddleware{
 		log: log,
+		cfg: cfg,
 	}
 }
 
+// New stores the request id in the context for the loggers of the handlers and services,
+// and writes the access log line once the request is served
 func (m *LoggerMiddleware) New() func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		const op string = "middleware.NewLogger"
@@ -31,32 +42,53 @@ func (m *LoggerMiddleware) New() func(next http.Handler) http.Handler {
 		log.Info("logger initialized")
 
 		fn := func(w http.ResponseWriter, r *http.Request) {
-			/*	entry := log.With(
-					sl.String("method", r.Method),
-					sl.String("url", r.URL.Path),
-					sl.String("remote_addr", r.RemoteAddr),
-					sl.String("user_agent", r.UserAgent()),
-					sl.String("request_id", middleware.GetReqID(r.Context())),
-				)
-
-				ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
-
-				t1 := time.Now()
-				defer func() {
-					entry.Info("request completed",
-						sl.Int("status", ww.Status()),
-						sl.Int("size", ww.BytesWritten()),
-						sl.String("duration", time.Since(t1).String()),
-					)
-				}()*/
+			ctx := logctx.With(r.Context(), sl.String("request_id", middleware.GetReqID(r.Context())))
 
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			start := time.Now()
 
 			log.Debug("Number of goroutines", sl.Int("goroutines", runtime.NumGoroutine()))
 
-			next.ServeHTTP(ww, r)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			if !m.sampled(r, status) {
+				return
+			}
+
+			route := "unmatched"
+			if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+
+			logctx.Logger(ctx, m.log).Info("request completed",
+				sl.String("method", r.Method),
+				sl.String("route", route),
+				sl.String("path", r.URL.Path),
+				sl.Int("status", status),
+				sl.Int("bytes", ww.BytesWritten()),
+				sl.Int64("duration_ms", time.Since(start).Milliseconds()),
+				sl.String("remote_addr", r.RemoteAddr),
+			)
 		}
 
 		return http.HandlerFunc(fn)
 	}
 }
+
+// sampled keeps every request but a share of the successful segment hits
+func (m *LoggerMiddleware) sampled(r *http.Request, status int) bool {
+	if !m.cfg.AccessLog.Enabled {
+		return false
+	}
+
+	if path.Ext(r.URL.Path) != ".ts" || status >= http.StatusBadRequest {
+		return true
+	}
+
+	return rand.Float64() < m.cfg.AccessLog.SegmentSampleRatio
+}
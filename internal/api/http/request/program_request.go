@@ -0,0 +1,16 @@
+package request
+
+type ProgramRequest struct {
+	Name     string `json:"name" validate:"required,max=255,min=2"`
+	GoalID   int64  `json:"goal_id" validate:"required,gt=0"`
+	LevelID  int64  `json:"level_id" validate:"required,gt=0"`
+	PeriodID int64  `json:"period_id" validate:"required,gt=0"`
+}
+
+type ProgramMonthRequest struct {
+	Month int `json:"month" validate:"required,min=1,max=120"`
+}
+
+type ProgramMonthWorkoutRequest struct {
+	WorkoutID int64 `json:"workout_id" validate:"required,gt=0"`
+}
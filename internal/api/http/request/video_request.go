@@ -6,10 +6,18 @@ type WorkoutRequest struct {
 }
 
 type VideoSavePositionRequest struct {
-	Position float64 `json:"position" validate:"required"`
+	Position float64 `json:"position" validate:"min=0"`
 }
 
 type VideoUpdateRequest struct {
 	Name        string `json:"name" validate:"required"`
 	Description string `json:"description"`
 }
+
+type WorkoutReorderRequest struct {
+	IDs []int64 `json:"ids" validate:"required,min=1,dive,gt=0"`
+}
+
+type PortalRequest struct {
+	Name string `json:"name" validate:"required,max=255,min=2"`
+}
@@ -3,24 +3,32 @@ package api
 import (
 	"encoding/json"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go-fitness/external/config"
 	"go-fitness/external/logger/sl"
 	"golang.org/x/text/language"
 	"log/slog"
+	"path/filepath"
 )
 
-func NewBundle(log *slog.Logger) *i18n.Bundle {
+// NewBundle loads every active.<lang>.json of the lang dir, Romanian is the language of the message ids
+func NewBundle(log *slog.Logger, cfg *config.Config) *i18n.Bundle {
 	bundle := i18n.NewBundle(language.Romanian)
 	bundle.RegisterUnmarshalFunc("json", json.Unmarshal)
 
-	_, err := bundle.LoadMessageFile("/app/lang/active.ro.json")
+	files, err := filepath.Glob(filepath.Join(cfg.Lang.Dir, "active.*.json"))
 	if err != nil {
-		log.Error("failed to load active.ro.json", sl.Err(err))
+		log.Error("failed to list the lang files", sl.Err(err))
 		panic(err)
 	}
 
-	return bundle
-}
+	for _, file := range files {
+		if _, err = bundle.LoadMessageFile(file); err != nil {
+			log.Error("failed to load lang file", sl.String("file", file), sl.Err(err))
+			panic(err)
+		}
+	}
 
-func NewLocalizer(log *slog.Logger, bundle *i18n.Bundle) *i18n.Localizer {
-	return i18n.NewLocalizer(bundle, "ro")
+	log.Info("languages loaded", sl.Any("languages", bundle.LanguageTags()))
+
+	return bundle
 }
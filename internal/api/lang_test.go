@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go-fitness/external/config"
+	"go-fitness/external/ctx/locale"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const (
+	repoRoot = "../.."
+	langDir  = repoRoot + "/lang"
+)
+
+// messageIDPattern matches the snake case message ids, to tell them from other strings of the validation package
+var messageIDPattern = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)+$`)
+
+// usedMessageIDs collects the literal message ids of the code: MessageID fields, the codes of the apperr
+// constructors, which response.Error localizes, and the field codes of the validation package
+func usedMessageIDs(t *testing.T) map[string][]string {
+	t.Helper()
+
+	ids := make(map[string][]string)
+	add := func(fset *token.FileSet, lit ast.Expr, match bool) {
+		basic, ok := lit.(*ast.BasicLit)
+		if !ok || basic.Kind != token.STRING {
+			return
+		}
+		id, err := strconv.Unquote(basic.Value)
+		if err != nil || (match && !messageIDPattern.MatchString(id)) {
+			return
+		}
+		ids[id] = append(ids[id], fset.Position(basic.Pos()).String())
+	}
+
+	for _, dir := range []string{"internal", "external"} {
+		err := filepath.WalkDir(filepath.Join(repoRoot, dir), func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return err
+			}
+
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, path, nil, 0)
+			if err != nil {
+				return err
+			}
+
+			ast.Inspect(file, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.KeyValueExpr:
+					if key, ok := n.Key.(*ast.Ident); ok && key.Name == "MessageID" {
+						add(fset, n.Value, false)
+					}
+				case *ast.CallExpr:
+					if sel, ok := n.Fun.(*ast.SelectorExpr); ok && len(n.Args) > 0 {
+						if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "apperr" {
+							add(fset, n.Args[len(n.Args)-1], false)
+						}
+					}
+				case *ast.BasicLit:
+					if file.Name.Name == "validation" {
+						add(fset, n, true)
+					}
+				}
+				return true
+			})
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return ids
+}
+
+func TestEveryMessageIDExistsInEveryLanguage(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join(langDir, "active.*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) < 2 {
+		t.Fatalf("expected several languages in %s, got %v", langDir, files)
+	}
+
+	ids := usedMessageIDs(t)
+	if len(ids) == 0 {
+		t.Fatal("no message ids found in the code")
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var messages map[string]string
+		if err = json.Unmarshal(data, &messages); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+
+		var missing []string
+		for id, positions := range ids {
+			if _, ok := messages[id]; !ok {
+				missing = append(missing, id+" (used at "+positions[0]+")")
+			}
+		}
+		sort.Strings(missing)
+
+		for _, id := range missing {
+			t.Errorf("%s: missing %s", filepath.Base(file), id)
+		}
+	}
+}
+
+func TestLocalizerNegotiatesTheLanguage(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Lang.Dir = langDir
+
+	bundle := NewBundle(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg)
+
+	tests := []struct {
+		name   string
+		accept string
+		user   string
+		want   string
+	}{
+		{name: "accept language", accept: "en-US,en;q=0.9", want: "The video was not found"},
+		{name: "unknown language falls back to romanian", accept: "de-DE", want: "Videoclipul nu a fost găsit"},
+		{name: "no header falls back to romanian", want: "Videoclipul nu a fost găsit"},
+		{name: "user profile wins over the header", accept: "ro", user: "en", want: "The video was not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := locale.Prefer(locale.New(context.Background(), bundle, tt.accept), tt.user)
+
+			got := locale.Localizer(ctx).MustLocalize(&i18n.LocalizeConfig{MessageID: "video_not_found"})
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,35 +3,43 @@ package api
 import (
 	"go-fitness/external/config"
 	"go-fitness/external/logger/handler/slogpretty"
+	"go-fitness/external/logger/handler/slogredact"
 	"log/slog"
 	"os"
 )
 
-func NewLogger(cfg *config.Config) *slog.Logger {
-	var log *slog.Logger
+// NewLogger picks the handler of the env and masks secrets and personal data whatever it is
+func NewLogger(cfg *config.Config) (*slog.Logger, error) {
+	var handler slog.Handler
 
 	switch cfg.Env {
 	case cfg.ENVState.Local:
-		log = setupPrettySlogLogger()
+		handler = setupPrettySlogHandler()
 	case cfg.ENVState.Dev:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})
 	case cfg.ENVState.Prod:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+		handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
+	default:
+		handler = slog.Default().Handler()
 	}
 
-	return log
+	redact, err := slogredact.Options{
+		Keys:     cfg.Log.RedactKeys,
+		Patterns: cfg.Log.RedactPatterns,
+	}.NewRedactHandler(handler)
+	if err != nil {
+		return nil, err
+	}
+
+	return slog.New(redact), nil
 }
 
-func setupPrettySlogLogger() *slog.Logger {
+func setupPrettySlogHandler() slog.Handler {
 	opts := slogpretty.PrettyHandlerOptions{
 		SlogOpts: &slog.HandlerOptions{
 			Level: slog.LevelDebug,
 		},
 	}
 
-	newPrettyHandler := opts.NewPrettyHandler(os.Stdout)
-
-	return slog.New(newPrettyHandler)
+	return opts.NewPrettyHandler(os.Stdout)
 }
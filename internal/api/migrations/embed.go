@@ -0,0 +1,59 @@
+// Package migrations holds the versioned schema of every table the repositories use.
+// Each version is a <version>_<name>.up.sql and .down.sql pair applied by the fitness migrate command,
+// or on start when db.migrate_on_start is set. Tables are created IF NOT EXISTS so a database
+// created before the migrations existed can adopt them, 0009 adds the columns its tables lack
+// and the downs of 0001-0004 leave those tables in place.
+// The mysql and sqlite directories hold the same versions written for each driver.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"go-fitness/external/config"
+	"go-fitness/external/db"
+	"go-fitness/external/db/migrate"
+	"go-fitness/external/logger/sl"
+	"go.uber.org/fx"
+	"io/fs"
+	"log/slog"
+)
+
+//go:embed mysql/*.sql sqlite/*.sql
+var files embed.FS
+
+// NewMigrator reads the migrations written for the dialect of the database
+func NewMigrator(log *slog.Logger, conn *sql.DB, database db.SqlInterface) (*migrate.Migrator, error) {
+	dir, err := fs.Sub(files, string(database.Dialect()))
+	if err != nil {
+		return nil, err
+	}
+
+	return migrate.New(log, conn, database.Dialect(), dir)
+}
+
+// RunOnStart applies the pending migrations before anything else starts when db.migrate_on_start is set
+func RunOnStart(
+	lc fx.Lifecycle,
+	log *slog.Logger,
+	cfg *config.Config,
+	migrator *migrate.Migrator,
+) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if !cfg.DB.MigrateOnStart {
+				return nil
+			}
+
+			applied, err := migrator.Up(ctx)
+			if err != nil {
+				log.Error("failed to migrate", sl.Err(err))
+				return err
+			}
+
+			log.Info("Database migrated", sl.Int("applied", applied))
+
+			return nil
+		},
+	})
+}
@@ -0,0 +1,17 @@
+-- videos are the transcoded uploads, the files live in <storage>/<video path>/<hash_name>
+-- status: 0 unknown, 1 processing, 2 processed, 3 failed, 4 disabled
+CREATE TABLE IF NOT EXISTS videos (
+    id         BIGINT UNSIGNED  NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    uuid       CHAR(36)         NOT NULL,
+    hash_name  VARCHAR(64)      NOT NULL,
+    status     TINYINT UNSIGNED NOT NULL DEFAULT 0,
+    duration   DOUBLE           NOT NULL DEFAULT 0,
+    poster     VARCHAR(255)     NULL,
+    deleted_at DATETIME         NULL,
+    created_at DATETIME         NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME         NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY videos_uuid_unique (uuid),
+    UNIQUE KEY videos_hash_name_unique (hash_name),
+    KEY videos_status_index (status),
+    KEY videos_deleted_at_index (deleted_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
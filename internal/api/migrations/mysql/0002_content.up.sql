@@ -0,0 +1,47 @@
+-- the content models a video belongs to, video_id is the main video,
+-- other roles live in video_attachments
+CREATE TABLE IF NOT EXISTS workouts (
+    id          BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name        VARCHAR(255)    NOT NULL,
+    description TEXT            NOT NULL,
+    video_id    BIGINT UNSIGNED NOT NULL,
+    position    INT             NOT NULL DEFAULT 0,
+    deleted_at  DATETIME        NULL,
+    created_at  DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    KEY workouts_name_index (name),
+    KEY workouts_video_id_index (video_id),
+    KEY workouts_deleted_at_index (deleted_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS goals (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name       VARCHAR(255)    NOT NULL,
+    video_id   BIGINT UNSIGNED NULL,
+    deleted_at DATETIME        NULL,
+    created_at DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    KEY goals_name_index (name),
+    KEY goals_video_id_index (video_id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS info_tabs (
+    id          BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name        VARCHAR(255)    NOT NULL,
+    description TEXT            NOT NULL,
+    video_id    BIGINT UNSIGNED NULL,
+    deleted_at  DATETIME        NULL,
+    created_at  DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    KEY info_tabs_name_index (name),
+    KEY info_tabs_video_id_index (video_id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS portal_videos (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name       VARCHAR(255)    NOT NULL,
+    video_id   BIGINT UNSIGNED NOT NULL,
+    created_at DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    KEY portal_videos_video_id_index (video_id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+-- the program tables predate the migrations, an adopted database would lose its data, so they are left in place
+SELECT 1;
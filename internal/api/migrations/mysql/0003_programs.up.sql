@@ -0,0 +1,52 @@
+-- a program is a goal, level and period combination split into months of ordered workouts
+CREATE TABLE IF NOT EXISTS levels (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name       VARCHAR(255)    NOT NULL,
+    created_at DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY levels_name_unique (name)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS periods (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name       VARCHAR(255)    NOT NULL,
+    created_at DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY periods_name_unique (name)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS programs (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name       VARCHAR(255)    NOT NULL,
+    goal_id    BIGINT UNSIGNED NOT NULL,
+    level_id   BIGINT UNSIGNED NOT NULL,
+    period_id  BIGINT UNSIGNED NOT NULL,
+    created_at DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY programs_name_unique (name),
+    KEY programs_goal_id_index (goal_id),
+    KEY programs_level_id_index (level_id),
+    KEY programs_period_id_index (period_id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS program_months (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    program_id BIGINT UNSIGNED NOT NULL,
+    month      INT             NOT NULL,
+    created_at DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY program_months_program_id_month_unique (program_id, month),
+    CONSTRAINT program_months_program_id_foreign FOREIGN KEY (program_id) REFERENCES programs (id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS program_month_has_workouts (
+    program_month_id BIGINT UNSIGNED NOT NULL,
+    workout_id       BIGINT UNSIGNED NOT NULL,
+    position         INT             NOT NULL DEFAULT 0,
+    PRIMARY KEY (program_month_id, workout_id),
+    KEY program_month_has_workouts_workout_id_index (workout_id),
+    CONSTRAINT program_month_has_workouts_program_month_id_foreign
+        FOREIGN KEY (program_month_id) REFERENCES program_months (id) ON DELETE CASCADE,
+    CONSTRAINT program_month_has_workouts_workout_id_foreign
+        FOREIGN KEY (workout_id) REFERENCES workouts (id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+-- the tables belong to the main backend and are left in place
+SELECT 1;
@@ -0,0 +1,32 @@
+-- users and their roles are owned by the main backend, which shares the database.
+-- This service only reads them, the tables are described here so a fresh database works on its own
+CREATE TABLE IF NOT EXISTS users (
+    id                BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    uuid              CHAR(36)        NOT NULL,
+    name              VARCHAR(255)    NOT NULL,
+    email             VARCHAR(255)    NOT NULL,
+    active            TINYINT(1)      NOT NULL DEFAULT 1,
+    email_verified_at DATETIME        NULL,
+    deleted_at        DATETIME        NULL,
+    created_at        DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at        DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY users_uuid_unique (uuid),
+    UNIQUE KEY users_email_unique (email)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS roles (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name       VARCHAR(255)    NOT NULL,
+    guard_name VARCHAR(255)    NOT NULL DEFAULT 'web',
+    created_at DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY roles_name_guard_name_unique (name, guard_name)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS model_has_roles (
+    role_id    BIGINT UNSIGNED NOT NULL,
+    model_type VARCHAR(255)    NOT NULL DEFAULT 'App\\Models\\User',
+    model_id   BIGINT UNSIGNED NOT NULL,
+    PRIMARY KEY (role_id, model_id, model_type),
+    KEY model_has_roles_model_id_model_type_index (model_id, model_type)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
@@ -0,0 +1,35 @@
+-- video_positions keeps where a user stopped watching, one row per user and video
+CREATE TABLE IF NOT EXISTS video_positions (
+    id           BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id      BIGINT UNSIGNED NOT NULL,
+    video_id     BIGINT UNSIGNED NOT NULL,
+    position     DOUBLE          NOT NULL DEFAULT 0,
+    completed_at DATETIME        NULL,
+    created_at   DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY video_positions_user_id_video_id_unique (user_id, video_id),
+    KEY video_positions_user_id_updated_at_index (user_id, updated_at),
+    CONSTRAINT video_positions_video_id_foreign FOREIGN KEY (video_id) REFERENCES videos (id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+-- workout_completions is the history of finished workouts, exp_ledger the experience awarded for them
+CREATE TABLE IF NOT EXISTS workout_completions (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id    BIGINT UNSIGNED NOT NULL,
+    workout_id BIGINT UNSIGNED NOT NULL,
+    source     VARCHAR(16)     NOT NULL,
+    created_at DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY workout_completions_user_id_workout_id_index (user_id, workout_id),
+    KEY workout_completions_user_id_created_at_index (user_id, created_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS exp_ledger (
+    id            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id       BIGINT UNSIGNED NOT NULL,
+    amount        INT             NOT NULL,
+    reason        VARCHAR(64)     NOT NULL,
+    completion_id BIGINT UNSIGNED NULL,
+    created_at    DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY exp_ledger_user_id_index (user_id),
+    KEY exp_ledger_completion_id_index (completion_id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
@@ -0,0 +1,35 @@
+-- outbox_events stores domain events in the transaction of the change they describe.
+-- delivered_to is the comma separated list of sinks that received the event,
+-- lock_token and locked_until are the lease of the dispatcher working on it
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id              BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name            VARCHAR(64)     NOT NULL,
+    payload         JSON            NOT NULL,
+    status          VARCHAR(16)     NOT NULL DEFAULT 'pending',
+    attempts        INT             NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME        NOT NULL,
+    last_error      TEXT            NULL,
+    delivered_to    TEXT            NOT NULL,
+    delivered_at    DATETIME        NULL,
+    lock_token      CHAR(36)        NULL,
+    locked_until    DATETIME        NULL,
+    created_at      DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY outbox_events_status_next_attempt_at_index (status, next_attempt_at),
+    KEY outbox_events_lock_token_index (lock_token),
+    KEY outbox_events_name_index (name)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+-- webhook_deliveries logs every attempt to POST an event to a webhook
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id          BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    event_id    BIGINT UNSIGNED NOT NULL,
+    webhook     VARCHAR(255)    NOT NULL,
+    url         VARCHAR(2048)   NOT NULL,
+    event       VARCHAR(64)     NOT NULL,
+    status_code INT             NULL,
+    error       TEXT            NULL,
+    duration_ms BIGINT          NOT NULL DEFAULT 0,
+    created_at  DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY webhook_deliveries_event_id_index (event_id),
+    KEY webhook_deliveries_webhook_index (webhook)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
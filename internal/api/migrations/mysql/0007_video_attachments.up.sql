@@ -0,0 +1,13 @@
+-- video_attachments links videos to workouts, goals, tabs and portal videos by role.
+-- The main role is mirrored in the video_id column of the owner
+CREATE TABLE IF NOT EXISTS video_attachments (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    video_id   BIGINT UNSIGNED NOT NULL,
+    owner_type VARCHAR(16)     NOT NULL,
+    owner_id   BIGINT UNSIGNED NOT NULL,
+    role       VARCHAR(16)     NOT NULL,
+    created_at DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY video_attachments_owner_type_owner_id_role_unique (owner_type, owner_id, role),
+    KEY video_attachments_video_id_index (video_id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
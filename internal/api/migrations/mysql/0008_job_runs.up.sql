@@ -0,0 +1,11 @@
+-- job_runs is the history of the scheduled maintenance jobs
+CREATE TABLE IF NOT EXISTS job_runs (
+    id           BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    job          VARCHAR(64)     NOT NULL,
+    triggered_by VARCHAR(16)     NOT NULL,
+    status       VARCHAR(16)     NOT NULL,
+    error        TEXT            NULL,
+    started_at   DATETIME        NOT NULL,
+    finished_at  DATETIME        NULL,
+    KEY job_runs_job_index (job)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
@@ -0,0 +1,49 @@
+-- the tables of 0001-0003 predate the migrations, on such a database CREATE TABLE IF NOT EXISTS skipped them.
+-- This adds the columns the service needs since then, each only when it is missing, a fresh database already has them
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 'videos' AND column_name = 'deleted_at') = 0,
+    'ALTER TABLE videos ADD COLUMN deleted_at DATETIME NULL, ADD KEY videos_deleted_at_index (deleted_at)',
+    'SELECT 1');
+PREPARE adopt FROM @ddl;
+EXECUTE adopt;
+DEALLOCATE PREPARE adopt;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 'workouts' AND column_name = 'position') = 0,
+    'ALTER TABLE workouts ADD COLUMN position INT NOT NULL DEFAULT 0',
+    'SELECT 1');
+PREPARE adopt FROM @ddl;
+EXECUTE adopt;
+DEALLOCATE PREPARE adopt;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 'workouts' AND column_name = 'deleted_at') = 0,
+    'ALTER TABLE workouts ADD COLUMN deleted_at DATETIME NULL, ADD KEY workouts_deleted_at_index (deleted_at)',
+    'SELECT 1');
+PREPARE adopt FROM @ddl;
+EXECUTE adopt;
+DEALLOCATE PREPARE adopt;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 'goals' AND column_name = 'deleted_at') = 0,
+    'ALTER TABLE goals ADD COLUMN deleted_at DATETIME NULL',
+    'SELECT 1');
+PREPARE adopt FROM @ddl;
+EXECUTE adopt;
+DEALLOCATE PREPARE adopt;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 'info_tabs' AND column_name = 'deleted_at') = 0,
+    'ALTER TABLE info_tabs ADD COLUMN deleted_at DATETIME NULL',
+    'SELECT 1');
+PREPARE adopt FROM @ddl;
+EXECUTE adopt;
+DEALLOCATE PREPARE adopt;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 'program_month_has_workouts' AND column_name = 'position') = 0,
+    'ALTER TABLE program_month_has_workouts ADD COLUMN position INT NOT NULL DEFAULT 0',
+    'SELECT 1');
+PREPARE adopt FROM @ddl;
+EXECUTE adopt;
+DEALLOCATE PREPARE adopt;
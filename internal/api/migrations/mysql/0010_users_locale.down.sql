@@ -0,0 +1,2 @@
+-- the column may come from the main backend, which owns users, so it is left in place
+SELECT 1;
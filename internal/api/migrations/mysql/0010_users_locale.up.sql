@@ -0,0 +1,9 @@
+-- locale is the language picked in the user profile, users belongs to the main backend,
+-- so the column is only added when that backend has not added it already
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 'users' AND column_name = 'locale') = 0,
+    'ALTER TABLE users ADD COLUMN locale VARCHAR(16) NULL',
+    'SELECT 1');
+PREPARE adopt FROM @ddl;
+EXECUTE adopt;
+DEALLOCATE PREPARE adopt;
@@ -0,0 +1,2 @@
+-- videos predates the migrations, an adopted database would lose its data, so the table is left in place
+SELECT 1;
@@ -0,0 +1,18 @@
+-- videos are the transcoded uploads, the files live in <storage>/<video path>/<hash_name>
+-- status: 0 unknown, 1 processing, 2 processed, 3 failed, 4 disabled
+CREATE TABLE IF NOT EXISTS videos (
+    id         INTEGER      NOT NULL PRIMARY KEY AUTOINCREMENT,
+    uuid       CHAR(36)     NOT NULL,
+    hash_name  VARCHAR(64)  NOT NULL,
+    status     INTEGER      NOT NULL DEFAULT 0,
+    duration   DOUBLE       NOT NULL DEFAULT 0,
+    poster     VARCHAR(255) NULL,
+    deleted_at DATETIME     NULL,
+    created_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS videos_uuid_unique ON videos (uuid);
+CREATE UNIQUE INDEX IF NOT EXISTS videos_hash_name_unique ON videos (hash_name);
+CREATE INDEX IF NOT EXISTS videos_status_index ON videos (status);
+CREATE INDEX IF NOT EXISTS videos_deleted_at_index ON videos (deleted_at);
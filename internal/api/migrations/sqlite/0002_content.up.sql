@@ -0,0 +1,51 @@
+-- the content models a video belongs to, video_id is the main video,
+-- other roles live in video_attachments
+CREATE TABLE IF NOT EXISTS workouts (
+    id          INTEGER      NOT NULL PRIMARY KEY AUTOINCREMENT,
+    name        VARCHAR(255) NOT NULL,
+    description TEXT         NOT NULL,
+    video_id    INTEGER      NOT NULL,
+    position    INTEGER      NOT NULL DEFAULT 0,
+    deleted_at  DATETIME     NULL,
+    created_at  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS workouts_name_index ON workouts (name);
+CREATE INDEX IF NOT EXISTS workouts_video_id_index ON workouts (video_id);
+CREATE INDEX IF NOT EXISTS workouts_deleted_at_index ON workouts (deleted_at);
+
+CREATE TABLE IF NOT EXISTS goals (
+    id         INTEGER      NOT NULL PRIMARY KEY AUTOINCREMENT,
+    name       VARCHAR(255) NOT NULL,
+    video_id   INTEGER      NULL,
+    deleted_at DATETIME     NULL,
+    created_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS goals_name_index ON goals (name);
+CREATE INDEX IF NOT EXISTS goals_video_id_index ON goals (video_id);
+
+CREATE TABLE IF NOT EXISTS info_tabs (
+    id          INTEGER      NOT NULL PRIMARY KEY AUTOINCREMENT,
+    name        VARCHAR(255) NOT NULL,
+    description TEXT         NOT NULL,
+    video_id    INTEGER      NULL,
+    deleted_at  DATETIME     NULL,
+    created_at  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS info_tabs_name_index ON info_tabs (name);
+CREATE INDEX IF NOT EXISTS info_tabs_video_id_index ON info_tabs (video_id);
+
+CREATE TABLE IF NOT EXISTS portal_videos (
+    id         INTEGER      NOT NULL PRIMARY KEY AUTOINCREMENT,
+    name       VARCHAR(255) NOT NULL,
+    video_id   INTEGER      NOT NULL,
+    created_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS portal_videos_video_id_index ON portal_videos (video_id);
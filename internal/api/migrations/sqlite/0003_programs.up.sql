@@ -0,0 +1,46 @@
+-- a program is a goal, level and period combination split into months of ordered workouts
+CREATE TABLE IF NOT EXISTS levels (
+    id         INTEGER      NOT NULL PRIMARY KEY AUTOINCREMENT,
+    name       VARCHAR(255) NOT NULL UNIQUE,
+    created_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS periods (
+    id         INTEGER      NOT NULL PRIMARY KEY AUTOINCREMENT,
+    name       VARCHAR(255) NOT NULL UNIQUE,
+    created_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS programs (
+    id         INTEGER      NOT NULL PRIMARY KEY AUTOINCREMENT,
+    name       VARCHAR(255) NOT NULL UNIQUE,
+    goal_id    INTEGER      NOT NULL,
+    level_id   INTEGER      NOT NULL,
+    period_id  INTEGER      NOT NULL,
+    created_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS programs_goal_id_index ON programs (goal_id);
+CREATE INDEX IF NOT EXISTS programs_level_id_index ON programs (level_id);
+CREATE INDEX IF NOT EXISTS programs_period_id_index ON programs (period_id);
+
+CREATE TABLE IF NOT EXISTS program_months (
+    id         INTEGER  NOT NULL PRIMARY KEY AUTOINCREMENT,
+    program_id INTEGER  NOT NULL REFERENCES programs (id) ON DELETE CASCADE,
+    month      INTEGER  NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (program_id, month)
+);
+
+CREATE TABLE IF NOT EXISTS program_month_has_workouts (
+    program_month_id INTEGER NOT NULL REFERENCES program_months (id) ON DELETE CASCADE,
+    workout_id       INTEGER NOT NULL REFERENCES workouts (id) ON DELETE CASCADE,
+    position         INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (program_month_id, workout_id)
+);
+
+CREATE INDEX IF NOT EXISTS program_month_has_workouts_workout_id_index ON program_month_has_workouts (workout_id);
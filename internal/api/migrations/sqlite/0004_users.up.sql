@@ -0,0 +1,31 @@
+-- users and their roles are owned by the main backend, which shares the database.
+-- This service only reads them, the tables are described here so a fresh database works on its own
+CREATE TABLE IF NOT EXISTS users (
+    id                INTEGER      NOT NULL PRIMARY KEY AUTOINCREMENT,
+    uuid              CHAR(36)     NOT NULL UNIQUE,
+    name              VARCHAR(255) NOT NULL,
+    email             VARCHAR(255) NOT NULL UNIQUE,
+    active            INTEGER      NOT NULL DEFAULT 1,
+    email_verified_at DATETIME     NULL,
+    deleted_at        DATETIME     NULL,
+    created_at        DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at        DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS roles (
+    id         INTEGER      NOT NULL PRIMARY KEY AUTOINCREMENT,
+    name       VARCHAR(255) NOT NULL,
+    guard_name VARCHAR(255) NOT NULL DEFAULT 'web',
+    created_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (name, guard_name)
+);
+
+CREATE TABLE IF NOT EXISTS model_has_roles (
+    role_id    INTEGER      NOT NULL,
+    model_type VARCHAR(255) NOT NULL DEFAULT 'App\Models\User',
+    model_id   INTEGER      NOT NULL,
+    PRIMARY KEY (role_id, model_id, model_type)
+);
+
+CREATE INDEX IF NOT EXISTS model_has_roles_model_id_model_type_index ON model_has_roles (model_id, model_type);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS exp_ledger;
+DROP TABLE IF EXISTS workout_completions;
+DROP TABLE IF EXISTS video_positions;
@@ -0,0 +1,37 @@
+-- video_positions keeps where a user stopped watching, one row per user and video
+CREATE TABLE IF NOT EXISTS video_positions (
+    id           INTEGER  NOT NULL PRIMARY KEY AUTOINCREMENT,
+    user_id      INTEGER  NOT NULL,
+    video_id     INTEGER  NOT NULL REFERENCES videos (id) ON DELETE CASCADE,
+    position     DOUBLE   NOT NULL DEFAULT 0,
+    completed_at DATETIME NULL,
+    created_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, video_id)
+);
+
+CREATE INDEX IF NOT EXISTS video_positions_user_id_updated_at_index ON video_positions (user_id, updated_at);
+
+-- workout_completions is the history of finished workouts, exp_ledger the experience awarded for them
+CREATE TABLE IF NOT EXISTS workout_completions (
+    id         INTEGER     NOT NULL PRIMARY KEY AUTOINCREMENT,
+    user_id    INTEGER     NOT NULL,
+    workout_id INTEGER     NOT NULL,
+    source     VARCHAR(16) NOT NULL,
+    created_at DATETIME    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS workout_completions_user_id_workout_id_index ON workout_completions (user_id, workout_id);
+CREATE INDEX IF NOT EXISTS workout_completions_user_id_created_at_index ON workout_completions (user_id, created_at);
+
+CREATE TABLE IF NOT EXISTS exp_ledger (
+    id            INTEGER     NOT NULL PRIMARY KEY AUTOINCREMENT,
+    user_id       INTEGER     NOT NULL,
+    amount        INTEGER     NOT NULL,
+    reason        VARCHAR(64) NOT NULL,
+    completion_id INTEGER     NULL,
+    created_at    DATETIME    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS exp_ledger_user_id_index ON exp_ledger (user_id);
+CREATE INDEX IF NOT EXISTS exp_ledger_completion_id_index ON exp_ledger (completion_id);
@@ -0,0 +1,37 @@
+-- outbox_events stores domain events in the transaction of the change they describe.
+-- delivered_to is the comma separated list of sinks that received the event,
+-- lock_token and locked_until are the lease of the dispatcher working on it
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id              INTEGER     NOT NULL PRIMARY KEY AUTOINCREMENT,
+    name            VARCHAR(64) NOT NULL,
+    payload         TEXT        NOT NULL,
+    status          VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts        INTEGER     NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME    NOT NULL,
+    last_error      TEXT        NULL,
+    delivered_to    TEXT        NOT NULL,
+    delivered_at    DATETIME    NULL,
+    lock_token      CHAR(36)    NULL,
+    locked_until    DATETIME    NULL,
+    created_at      DATETIME    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS outbox_events_status_next_attempt_at_index ON outbox_events (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS outbox_events_lock_token_index ON outbox_events (lock_token);
+CREATE INDEX IF NOT EXISTS outbox_events_name_index ON outbox_events (name);
+
+-- webhook_deliveries logs every attempt to POST an event to a webhook
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id          INTEGER       NOT NULL PRIMARY KEY AUTOINCREMENT,
+    event_id    INTEGER       NOT NULL,
+    webhook     VARCHAR(255)  NOT NULL,
+    url         VARCHAR(2048) NOT NULL,
+    event       VARCHAR(64)   NOT NULL,
+    status_code INTEGER       NULL,
+    error       TEXT          NULL,
+    duration_ms INTEGER       NOT NULL DEFAULT 0,
+    created_at  DATETIME      NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_event_id_index ON webhook_deliveries (event_id);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_index ON webhook_deliveries (webhook);
@@ -0,0 +1,14 @@
+-- video_attachments links videos to workouts, goals, tabs and portal videos by role.
+-- The main role is mirrored in the video_id column of the owner
+CREATE TABLE IF NOT EXISTS video_attachments (
+    id         INTEGER     NOT NULL PRIMARY KEY AUTOINCREMENT,
+    video_id   INTEGER     NOT NULL,
+    owner_type VARCHAR(16) NOT NULL,
+    owner_id   INTEGER     NOT NULL,
+    role       VARCHAR(16) NOT NULL,
+    created_at DATETIME    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (owner_type, owner_id, role)
+);
+
+CREATE INDEX IF NOT EXISTS video_attachments_video_id_index ON video_attachments (video_id);
@@ -0,0 +1,12 @@
+-- job_runs is the history of the scheduled maintenance jobs
+CREATE TABLE IF NOT EXISTS job_runs (
+    id           INTEGER     NOT NULL PRIMARY KEY AUTOINCREMENT,
+    job          VARCHAR(64) NOT NULL,
+    triggered_by VARCHAR(16) NOT NULL,
+    status       VARCHAR(16) NOT NULL,
+    error        TEXT        NULL,
+    started_at   DATETIME    NOT NULL,
+    finished_at  DATETIME    NULL
+);
+
+CREATE INDEX IF NOT EXISTS job_runs_job_index ON job_runs (job);
@@ -0,0 +1,2 @@
+-- on a fresh database the columns come from 0001-0003, which cannot tell them apart, so they are left in place
+SELECT 1;
@@ -0,0 +1,2 @@
+-- every SQLite database was created by these migrations, its tables already have the columns
+SELECT 1;
@@ -0,0 +1,2 @@
+-- locale is the language picked in the user profile
+ALTER TABLE users ADD COLUMN locale VARCHAR(16) NULL;
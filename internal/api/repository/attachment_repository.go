@@ -0,0 +1,325 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-fitness/external/db"
+	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/types"
+	"time"
+)
+
+type AttachmentRepository struct {
+	db db.SqlInterface
+}
+
+func NewAttachmentRepository(
+	db db.SqlInterface,
+) *AttachmentRepository {
+	return &AttachmentRepository{
+		db: db,
+	}
+}
+
+// ownerTable is the table of an owner type, its video_id column mirrors the main attachment
+// so the existing readers of workouts, goals, tabs and portal videos keep working
+type ownerTable struct {
+	name       string
+	softDelete bool
+}
+
+var ownerTables = map[enum.AttachmentOwner]ownerTable{
+	enum.AttachmentOwnerWorkout: {name: "workouts", softDelete: true},
+	enum.AttachmentOwnerGoal:    {name: "goals", softDelete: true},
+	enum.AttachmentOwnerTab:     {name: "info_tabs", softDelete: true},
+	enum.AttachmentOwnerPortal:  {name: "portal_videos"},
+}
+
+func lookupOwner(owner enum.AttachmentOwner) (ownerTable, error) {
+	table, ok := ownerTables[owner]
+	if !ok {
+		return table, fmt.Errorf("unknown owner type %q", owner)
+	}
+
+	return table, nil
+}
+
+// OwnerExists reports whether the live owner row exists
+func (r *AttachmentRepository) OwnerExists(ctx context.Context, owner enum.AttachmentOwner, ownerID int64) (bool, error) {
+	const op = "AttachmentRepository.OwnerExists"
+
+	table, err := lookupOwner(owner)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := "SELECT COUNT(*) FROM " + table.name + " WHERE id = ?"
+	if table.softDelete {
+		query += " AND deleted_at IS NULL"
+	}
+
+	var count int
+
+	if err = r.db.GetExecer(ctx).QueryRowContext(ctx, query, ownerID).Scan(&count); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count > 0, nil
+}
+
+// Attach sets the video of the owner in the role and returns the video it replaced, 0 if none
+func (r *AttachmentRepository) Attach(ctx context.Context, attachment types.VideoAttachment) (int64, error) {
+	const op = "AttachmentRepository.Attach"
+
+	table, err := lookupOwner(attachment.OwnerType)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var previous int64
+
+	err = r.db.DoInTransaction(ctx, func(tx db.QueryExecer) error {
+		if previous, err = r.current(ctx, tx, table, attachment.OwnerType, attachment.OwnerID, attachment.Role); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		now := time.Now()
+
+		upsert := `
+			INSERT INTO video_attachments (video_id,owner_type,owner_id,role,created_at,updated_at) VALUES (?,?,?,?,?,?)
+			ON DUPLICATE KEY UPDATE video_id = VALUES(video_id), updated_at = VALUES(updated_at)
+		`
+		if r.db.Dialect() == db.SQLite {
+			upsert = `
+				INSERT INTO video_attachments (video_id,owner_type,owner_id,role,created_at,updated_at) VALUES (?,?,?,?,?,?)
+				ON CONFLICT (owner_type,owner_id,role) DO UPDATE SET video_id = excluded.video_id, updated_at = excluded.updated_at
+			`
+		}
+
+		if _, err = tx.ExecContext(ctx, upsert,
+			attachment.VideoID,
+			attachment.OwnerType,
+			attachment.OwnerID,
+			attachment.Role,
+			now,
+			now,
+		); err != nil {
+			return err
+		}
+
+		if attachment.Role != enum.AttachmentRoleMain {
+			return nil
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"UPDATE "+table.name+" SET video_id = ?, updated_at = ? WHERE id = ?",
+			attachment.VideoID,
+			now,
+			attachment.OwnerID,
+		)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return previous, nil
+}
+
+// Detach removes the video of the owner in the role and returns it, sql.ErrNoRows when there is none
+func (r *AttachmentRepository) Detach(ctx context.Context, owner enum.AttachmentOwner, ownerID int64, role enum.AttachmentRole) (int64, error) {
+	const op = "AttachmentRepository.Detach"
+
+	table, err := lookupOwner(owner)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var previous int64
+
+	err = r.db.DoInTransaction(ctx, func(tx db.QueryExecer) error {
+		if previous, err = r.current(ctx, tx, table, owner, ownerID, role); err != nil {
+			return err
+		}
+
+		if _, err = tx.ExecContext(ctx,
+			"DELETE FROM video_attachments WHERE owner_type = ? AND owner_id = ? AND role = ?",
+			owner,
+			ownerID,
+			role,
+		); err != nil {
+			return err
+		}
+
+		if role != enum.AttachmentRoleMain {
+			return nil
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"UPDATE "+table.name+" SET video_id = NULL, updated_at = ? WHERE id = ?",
+			time.Now(),
+			ownerID,
+		)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return previous, nil
+}
+
+// current returns the attached video, for the main role of rows created before attachments existed
+// it falls back to the video_id column of the owner
+func (r *AttachmentRepository) current(
+	ctx context.Context,
+	tx db.QueryExecer,
+	table ownerTable,
+	owner enum.AttachmentOwner,
+	ownerID int64,
+	role enum.AttachmentRole,
+) (int64, error) {
+	var videoID sql.NullInt64
+
+	err := tx.QueryRowContext(ctx,
+		"SELECT video_id FROM video_attachments WHERE owner_type = ? AND owner_id = ? AND role = ?"+r.db.Dialect().ForUpdate(),
+		owner,
+		ownerID,
+		role,
+	).Scan(&videoID)
+	if err == nil || !errors.Is(err, sql.ErrNoRows) || role != enum.AttachmentRoleMain {
+		return videoID.Int64, err
+	}
+
+	if err = tx.QueryRowContext(ctx,
+		"SELECT video_id FROM "+table.name+" WHERE id = ?"+r.db.Dialect().ForUpdate(),
+		ownerID,
+	).Scan(&videoID); err != nil {
+		return 0, err
+	}
+
+	if !videoID.Valid || videoID.Int64 == 0 {
+		return 0, sql.ErrNoRows
+	}
+
+	return videoID.Int64, nil
+}
+
+// GetByOwner returns the attachments of the owner, including a main video only recorded on the owner row
+func (r *AttachmentRepository) GetByOwner(ctx context.Context, owner enum.AttachmentOwner, ownerID int64) ([]types.VideoAttachment, error) {
+	const op = "AttachmentRepository.GetByOwner"
+
+	table, err := lookupOwner(owner)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := r.db.GetExecer(ctx).QueryContext(ctx, `
+		SELECT id,video_id,owner_type,owner_id,role,created_at,updated_at FROM video_attachments
+		WHERE owner_type = ? AND owner_id = ?
+		ORDER BY role
+	`, owner, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	attachments := make([]types.VideoAttachment, 0)
+	hasMain := false
+
+	for rows.Next() {
+		var attachment types.VideoAttachment
+
+		if err = rows.Scan(
+			&attachment.ID,
+			&attachment.VideoID,
+			&attachment.OwnerType,
+			&attachment.OwnerID,
+			&attachment.Role,
+			&attachment.CreatedAt,
+			&attachment.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		hasMain = hasMain || attachment.Role == enum.AttachmentRoleMain
+		attachments = append(attachments, attachment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if hasMain {
+		return attachments, nil
+	}
+
+	var (
+		videoID   sql.NullInt64
+		createdAt time.Time
+		updatedAt time.Time
+	)
+
+	err = r.db.GetExecer(ctx).QueryRowContext(ctx,
+		"SELECT video_id,created_at,updated_at FROM "+table.name+" WHERE id = ?",
+		ownerID,
+	).Scan(&videoID, &createdAt, &updatedAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if videoID.Valid && videoID.Int64 != 0 {
+		attachments = append([]types.VideoAttachment{{
+			VideoID:   videoID.Int64,
+			OwnerType: owner,
+			OwnerID:   ownerID,
+			Role:      enum.AttachmentRoleMain,
+			CreatedAt: createdAt,
+			UpdatedAt: updatedAt,
+		}}, attachments...)
+	}
+
+	return attachments, nil
+}
+
+// DeleteByOwner removes every attachment of a deleted owner and returns the videos that were attached
+func (r *AttachmentRepository) DeleteByOwner(ctx context.Context, owner enum.AttachmentOwner, ownerID int64) ([]int64, error) {
+	const op = "AttachmentRepository.DeleteByOwner"
+
+	var videoIDs []int64
+
+	err := r.db.DoInTransaction(ctx, func(tx db.QueryExecer) error {
+		rows, err := tx.QueryContext(ctx,
+			"SELECT video_id FROM video_attachments WHERE owner_type = ? AND owner_id = ?"+r.db.Dialect().ForUpdate(),
+			owner,
+			ownerID,
+		)
+		if err != nil {
+			return err
+		}
+
+		for rows.Next() {
+			var videoID int64
+			if err = rows.Scan(&videoID); err != nil {
+				rows.Close()
+				return err
+			}
+			videoIDs = append(videoIDs, videoID)
+		}
+		rows.Close()
+
+		if err = rows.Err(); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, "DELETE FROM video_attachments WHERE owner_type = ? AND owner_id = ?", owner, ownerID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return videoIDs, nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/types"
+	"testing"
+)
+
+func TestAttachmentRepositoryAttachReplacesRole(t *testing.T) {
+	database := newTestDB(t)
+	ctx := context.Background()
+	attachments := NewAttachmentRepository(database)
+
+	first := createVideo(t, database, "first")
+	second := createVideo(t, database, "second")
+
+	workoutID, err := NewWorkoutRepository(database).Create(ctx, types.Workout{Name: "push", VideoID: first})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trailer := types.VideoAttachment{VideoID: first, OwnerType: enum.AttachmentOwnerWorkout, OwnerID: workoutID, Role: enum.AttachmentRoleTrailer}
+	if _, err = attachments.Attach(ctx, trailer); err != nil {
+		t.Fatal(err)
+	}
+
+	trailer.VideoID = second
+	previous, err := attachments.Attach(ctx, trailer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if previous != first {
+		t.Fatalf("got previous video %d, want %d", previous, first)
+	}
+
+	list, err := attachments.GetByOwner(ctx, enum.AttachmentOwnerWorkout, workoutID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the main video only lives on the workout row and is listed first
+	if len(list) != 2 || list[0].Role != enum.AttachmentRoleMain || list[1].VideoID != second {
+		t.Fatalf("unexpected attachments %+v", list)
+	}
+}
+
+func TestAttachmentRepositoryMainMirrorsOwner(t *testing.T) {
+	database := newTestDB(t)
+	ctx := context.Background()
+	attachments := NewAttachmentRepository(database)
+
+	first := createVideo(t, database, "first")
+	second := createVideo(t, database, "second")
+
+	workouts := NewWorkoutRepository(database)
+	workoutID, err := workouts.Create(ctx, types.Workout{Name: "push", VideoID: first})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previous, err := attachments.Attach(ctx, types.VideoAttachment{VideoID: second, OwnerType: enum.AttachmentOwnerWorkout, OwnerID: workoutID, Role: enum.AttachmentRoleMain})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if previous != first {
+		t.Fatalf("got previous video %d, want the one on the workout row %d", previous, first)
+	}
+
+	workout, err := workouts.GetByID(ctx, workoutID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if workout.VideoID != second {
+		t.Fatalf("workout video is %d, want %d", workout.VideoID, second)
+	}
+
+	videoIDs, err := attachments.DeleteByOwner(ctx, enum.AttachmentOwnerWorkout, workoutID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(videoIDs) != 1 || videoIDs[0] != second {
+		t.Fatalf("deleted attachments of videos %v, want [%d]", videoIDs, second)
+	}
+}
+
+func TestAttachmentRepositoryDetachMissing(t *testing.T) {
+	database := newTestDB(t)
+	ctx := context.Background()
+
+	goalID := int64(1)
+	if _, err := database.GetExecer(ctx).ExecContext(ctx, "INSERT INTO goals (id,name) VALUES (?,?)", goalID, "strength"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := NewAttachmentRepository(database).Detach(ctx, enum.AttachmentOwnerGoal, goalID, enum.AttachmentRolePreview)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("got %v, want sql.ErrNoRows", err)
+	}
+}
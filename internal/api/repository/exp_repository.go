@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/db"
+	"go-fitness/external/db/query"
+	"go-fitness/internal/api/types"
+	"time"
+)
+
+type ExpRepository struct {
+	db db.SqlInterface
+}
+
+func NewExpRepository(
+	db db.SqlInterface,
+) *ExpRepository {
+	return &ExpRepository{
+		db: db,
+	}
+}
+
+// LockUser locks the row of the user until the transaction of ctx ends, so the completions of one user are rewarded one at a time
+func (r *ExpRepository) LockUser(ctx context.Context, userID int64) error {
+	const op = "ExpRepository.LockUser"
+
+	var id int64
+
+	if err := r.db.GetExecer(ctx).QueryRowContext(ctx,
+		"SELECT id FROM users WHERE id = ?"+r.db.Dialect().ForUpdate(),
+		userID,
+	).Scan(&id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// CountCompletions counts the completions of the workout by the user since the given time
+func (r *ExpRepository) CountCompletions(ctx context.Context, userID, workoutID int64, since time.Time) (int, error) {
+	const op = "ExpRepository.CountCompletions"
+
+	const query = "SELECT COUNT(*) FROM workout_completions WHERE user_id = ? AND workout_id = ? AND created_at >= ?"
+
+	var count int
+
+	if err := r.db.GetExecer(ctx).QueryRowContext(ctx, query, userID, workoutID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count, nil
+}
+
+// CountRewardedCompletions counts the completions of the user since the given time that awarded exp
+func (r *ExpRepository) CountRewardedCompletions(ctx context.Context, userID int64, since time.Time) (int, error) {
+	const op = "ExpRepository.CountRewardedCompletions"
+
+	const query = `
+		SELECT COUNT(DISTINCT completion_id) FROM exp_ledger
+		WHERE user_id = ? AND completion_id IS NOT NULL AND created_at >= ?
+	`
+
+	var count int
+
+	if err := r.db.GetExecer(ctx).QueryRowContext(ctx, query, userID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count, nil
+}
+
+// RecordCompletion stores the completion, its ledger entries and the outbox events built by events
+// in one transaction and returns the completion id and the new total
+func (r *ExpRepository) RecordCompletion(
+	ctx context.Context,
+	completion types.WorkoutCompletion,
+	entries []types.ExpEntry,
+	events func(completionID, total int64) ([]types.OutboxEvent, error),
+) (int64, int64, error) {
+	const op = "ExpRepository.RecordCompletion"
+
+	var (
+		completionID int64
+		total        int64
+	)
+
+	err := r.db.DoInTransaction(ctx, func(tx db.QueryExecer) error {
+		res, err := tx.ExecContext(ctx,
+			"INSERT INTO workout_completions (user_id,workout_id,source,created_at) VALUES (?,?,?,?)",
+			completion.UserID,
+			completion.WorkoutID,
+			completion.Source,
+			completion.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		if completionID, err = res.LastInsertId(); err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if _, err = tx.ExecContext(ctx,
+				"INSERT INTO exp_ledger (user_id,amount,reason,completion_id,created_at) VALUES (?,?,?,?,?)",
+				entry.UserID,
+				entry.Amount,
+				entry.Reason,
+				completionID,
+				completion.CreatedAt,
+			); err != nil {
+				return err
+			}
+		}
+
+		if err = tx.QueryRowContext(ctx,
+			"SELECT COALESCE(SUM(amount), 0) FROM exp_ledger WHERE user_id = ?",
+			completion.UserID,
+		).Scan(&total); err != nil {
+			return err
+		}
+
+		outbox, err := events(completionID, total)
+		if err != nil {
+			return err
+		}
+
+		return insertOutbox(ctx, tx, outbox)
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return completionID, total, nil
+}
+
+func (r *ExpRepository) GetTotal(ctx context.Context, userID int64) (int64, error) {
+	const op = "ExpRepository.GetTotal"
+
+	var total int64
+
+	if err := r.db.GetExecer(ctx).QueryRowContext(ctx,
+		"SELECT COALESCE(SUM(amount), 0) FROM exp_ledger WHERE user_id = ?",
+		userID,
+	).Scan(&total); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return total, nil
+}
+
+var expLedgerSpec = query.Spec{
+	Table:      "exp_ledger",
+	Columns:    []string{"id", "user_id", "amount", "reason", "completion_id", "created_at"},
+	Filterable: map[string]string{"reason": "reason"},
+	Sortable: map[string]string{
+		"id":         "id",
+		"amount":     "amount",
+		"created_at": "created_at",
+	},
+	DefaultSort: "id DESC",
+}
+
+func (r *ExpRepository) GetLedger(ctx context.Context, userID int64, f filter.Filter) ([]types.ExpEntry, int64, error) {
+	const op = "ExpRepository.GetLedger"
+
+	b := query.New(expLedgerSpec).Where("user_id = ?", userID)
+	if err := b.Apply(f); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	entries := make([]types.ExpEntry, 0)
+
+	total, err := paginate(ctx, r.db.GetExecer(ctx), b, func(rows *sql.Rows) error {
+		var entry types.ExpEntry
+
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.UserID,
+			&entry.Amount,
+			&entry.Reason,
+			&entry.CompletionID,
+			&entry.CreatedAt,
+		); err != nil {
+			return err
+		}
+
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return entries, total, nil
+}
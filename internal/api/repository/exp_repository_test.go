@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/types"
+	"testing"
+	"time"
+)
+
+func TestExpRepositoryRecordCompletion(t *testing.T) {
+	database := newTestDB(t)
+	ctx := context.Background()
+	exp := NewExpRepository(database)
+
+	now := time.Now()
+	completion := types.WorkoutCompletion{UserID: 1, WorkoutID: 3, Source: enum.CompletionSourceManual, CreatedAt: now}
+	entries := []types.ExpEntry{
+		{UserID: 1, Amount: 10, Reason: "workout_completed"},
+		{UserID: 1, Amount: 5, Reason: "first_of_day"},
+	}
+
+	var eventTotal int64
+	_, total, err := exp.RecordCompletion(ctx, completion, entries, func(_, total int64) ([]types.OutboxEvent, error) {
+		eventTotal = total
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 15 || eventTotal != 15 {
+		t.Fatalf("got total %d and event total %d, want 15", total, eventTotal)
+	}
+
+	count, err := exp.CountCompletions(ctx, 1, 3, now.Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("got %d completions, want 1", count)
+	}
+
+	rewarded, err := exp.CountRewardedCompletions(ctx, 1, now.Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rewarded != 1 {
+		t.Fatalf("got %d rewarded completions, want 1", rewarded)
+	}
+
+	ledger, n, err := exp.GetLedger(ctx, 1, filter.Filter{Sort: "amount"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 || ledger[0].Amount != 5 {
+		t.Fatalf("unexpected ledger %+v", ledger)
+	}
+}
+
+func TestExpRepositoryLockUser(t *testing.T) {
+	database := newTestDB(t)
+	ctx := context.Background()
+	exp := NewExpRepository(database)
+
+	if _, err := database.GetExecer(ctx).ExecContext(ctx,
+		"INSERT INTO users (id,uuid,name,email) VALUES (1,'u-1','Jane','jane@example.com')",
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	err := database.WithTx(ctx, func(ctx context.Context) error {
+		return exp.LockUser(ctx, 1)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = exp.LockUser(ctx, 2); err == nil {
+		t.Fatal("expected an error for a missing user")
+	}
+}
@@ -14,6 +14,8 @@ func NewRepository() fx.Option {
 				NewVideoRepository,
 				fx.As(new(video.VideoRepository)),
 				fx.As(new(video.UpdateVideoStatus)),
+				fx.As(new(service.VideoFinder)),
+				fx.As(new(service.GCRepository)),
 			),
 
 			fx.Annotate(
@@ -45,6 +47,36 @@ func NewRepository() fx.Option {
 				NewPortalRepository,
 				fx.As(new(service.PortalRepository)),
 			),
+
+			fx.Annotate(
+				NewPositionRepository,
+				fx.As(new(service.PositionRepository)),
+			),
+
+			fx.Annotate(
+				NewExpRepository,
+				fx.As(new(service.ExpRepository)),
+			),
+
+			fx.Annotate(
+				NewOutboxRepository,
+				fx.As(new(service.OutboxRepository)),
+			),
+
+			fx.Annotate(
+				NewWebhookRepository,
+				fx.As(new(service.WebhookRepository)),
+			),
+
+			fx.Annotate(
+				NewJobRepository,
+				fx.As(new(service.JobRepository)),
+			),
+
+			fx.Annotate(
+				NewAttachmentRepository,
+				fx.As(new(service.AttachmentRepository)),
+			),
 		),
 	)
 }
@@ -2,8 +2,11 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"go-fitness/external/ctx/filter"
 	"go-fitness/external/db"
+	"go-fitness/external/db/query"
 	"go-fitness/internal/api/types"
 	"time"
 )
@@ -12,6 +15,24 @@ type GoalRepository struct {
 	db db.SqlInterface
 }
 
+var goalSpec = query.Spec{
+	Table:      "goals",
+	Columns:    []string{"id", "name", "video_id", "deleted_at", "created_at", "updated_at"},
+	Searchable: []string{"name"},
+	Filterable: map[string]string{
+		"name":     "name",
+		"video_id": "video_id",
+	},
+	Sortable: map[string]string{
+		"id":         "id",
+		"name":       "name",
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+	},
+	DefaultSort: "id DESC",
+	SoftDelete:  true,
+}
+
 func NewGoalRepository(
 	db db.SqlInterface,
 ) *GoalRepository {
@@ -27,7 +48,7 @@ func (r *GoalRepository) Store(ctx context.Context, goal types.Goal) error {
 
 	now := time.Now()
 
-	_, err := r.db.GetExecer().ExecContext(ctx, query, goal.Name, goal.VideoID, now, now)
+	_, err := r.db.GetExecer(ctx).ExecContext(ctx, query, goal.Name, goal.VideoID, now, now)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -41,7 +62,7 @@ func (r *GoalRepository) CheckIfNameExists(ctx context.Context, name string) boo
 	const query = "SELECT COUNT(*) FROM goals WHERE name = ?"
 
 	var count int
-	err := r.db.GetExecer().QueryRowContext(ctx, query, name).Scan(&count)
+	err := r.db.GetExecer(ctx).QueryRowContext(ctx, query, name).Scan(&count)
 	if err != nil {
 		return false
 	}
@@ -52,9 +73,9 @@ func (r *GoalRepository) CheckIfNameExists(ctx context.Context, name string) boo
 func (r *GoalRepository) GetByID(ctx context.Context, id int) (types.Goal, error) {
 	const op = "GoalRepository.GetByID"
 
-	const query = "SELECT id, name, video_id FROM goals WHERE id = ?"
+	const query = "SELECT id, name, video_id FROM goals WHERE id = ? AND deleted_at IS NULL"
 
-	row := r.db.GetExecer().QueryRowContext(ctx, query, id)
+	row := r.db.GetExecer(ctx).QueryRowContext(ctx, query, id)
 
 	goal := types.Goal{}
 
@@ -72,10 +93,106 @@ func (r *GoalRepository) Update(ctx context.Context, goal types.Goal) error {
 
 	now := time.Now()
 
-	_, err := r.db.GetExecer().ExecContext(ctx, query, goal.Name, goal.VideoID, now, goal.ID)
+	_, err := r.db.GetExecer(ctx).ExecContext(ctx, query, goal.Name, goal.VideoID, now, goal.ID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *GoalRepository) SoftDelete(ctx context.Context, id int) error {
+	const op = "GoalRepository.SoftDelete"
+
+	const query = "UPDATE goals SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL"
+
+	res, err := r.db.GetExecer(ctx).ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return checkAffected(op, res)
+}
+
+func (r *GoalRepository) Restore(ctx context.Context, id int) error {
+	const op = "GoalRepository.Restore"
+
+	const query = "UPDATE goals SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL"
+
+	res, err := r.db.GetExecer(ctx).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return checkAffected(op, res)
+}
+
+func (r *GoalRepository) GetTrashedBefore(ctx context.Context, before time.Time) ([]types.Goal, error) {
+	const op = "GoalRepository.GetTrashedBefore"
+
+	const query = "SELECT id,name,video_id,deleted_at FROM goals WHERE deleted_at IS NOT NULL AND deleted_at < ?"
+
+	rows, err := r.db.GetExecer(ctx).QueryContext(ctx, query, before)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var goals []types.Goal
+	for rows.Next() {
+		var goal types.Goal
+
+		if err = rows.Scan(&goal.ID, &goal.Name, &goal.VideoID, &goal.DeletedAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		goals = append(goals, goal)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return goals, nil
+}
+
+func (r *GoalRepository) ForceDelete(ctx context.Context, id int) error {
+	const op = "GoalRepository.ForceDelete"
+
+	const query = "DELETE FROM goals WHERE id = ?"
+
+	_, err := r.db.GetExecer(ctx).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	return nil
 }
+
+func (r *GoalRepository) GetList(ctx context.Context, f filter.Filter) ([]types.Goal, int64, error) {
+	const op = "GoalRepository.GetList"
+
+	b := query.New(goalSpec)
+	if err := b.Apply(f); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	goals := make([]types.Goal, 0)
+
+	total, err := paginate(ctx, r.db.GetExecer(ctx), b, func(rows *sql.Rows) error {
+		var goal types.Goal
+
+		if err := rows.Scan(&goal.ID, &goal.Name, &goal.VideoID, &goal.DeletedAt, &goal.CreatedAt, &goal.UpdatedAt); err != nil {
+			return err
+		}
+
+		goals = append(goals, goal)
+
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return goals, total, nil
+}
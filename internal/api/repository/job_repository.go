@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/db"
+	"go-fitness/external/db/query"
+	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/types"
+	"time"
+)
+
+type JobRepository struct {
+	db db.SqlInterface
+}
+
+func NewJobRepository(
+	db db.SqlInterface,
+) *JobRepository {
+	return &JobRepository{
+		db: db,
+	}
+}
+
+// WithLock runs do only if no other instance of the app holds the lock of the job
+func (r *JobRepository) WithLock(ctx context.Context, job string, do func(context.Context) error) (bool, error) {
+	const op = "JobRepository.WithLock"
+
+	locked, err := r.db.WithLock(ctx, "go-fitness:job:"+job, do)
+	if err != nil && !locked {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return locked, err
+}
+
+func (r *JobRepository) StartRun(ctx context.Context, job string, trigger enum.JobTrigger) (int64, error) {
+	const op = "JobRepository.StartRun"
+
+	const query = "INSERT INTO job_runs (job,triggered_by,status,started_at) VALUES (?,?,?,?)"
+
+	res, err := r.db.GetExecer(ctx).ExecContext(ctx, query, job, trigger, enum.JobRunStatusRunning, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+func (r *JobRepository) FinishRun(ctx context.Context, id int64, status enum.JobRunStatus, runErr *string) error {
+	const op = "JobRepository.FinishRun"
+
+	const query = "UPDATE job_runs SET status = ?, error = ?, finished_at = ? WHERE id = ?"
+
+	res, err := r.db.GetExecer(ctx).ExecContext(ctx, query, status, runErr, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return checkAffected(op, res)
+}
+
+// GetLastRuns returns the latest run of every job that ran at least once, keyed by job name
+func (r *JobRepository) GetLastRuns(ctx context.Context) (map[string]types.JobRun, error) {
+	const op = "JobRepository.GetLastRuns"
+
+	const query = `
+		SELECT id,job,triggered_by,status,error,started_at,finished_at
+		FROM job_runs
+		WHERE id IN (SELECT MAX(id) FROM job_runs GROUP BY job)
+	`
+
+	rows, err := r.db.GetExecer(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	runs := make(map[string]types.JobRun)
+	for rows.Next() {
+		run, err := scanJobRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		runs[run.Job] = run
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return runs, nil
+}
+
+var jobRunSpec = query.Spec{
+	Table:   "job_runs",
+	Columns: []string{"id", "job", "triggered_by", "status", "error", "started_at", "finished_at"},
+	Filterable: map[string]string{
+		"job":     "job",
+		"trigger": "triggered_by",
+		"status":  "status",
+	},
+	Sortable: map[string]string{
+		"id":         "id",
+		"started_at": "started_at",
+	},
+	DefaultSort: "id DESC",
+}
+
+func (r *JobRepository) GetRuns(ctx context.Context, f filter.Filter) ([]types.JobRun, int64, error) {
+	const op = "JobRepository.GetRuns"
+
+	b := query.New(jobRunSpec)
+	if err := b.Apply(f); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	runs := make([]types.JobRun, 0)
+
+	total, err := paginate(ctx, r.db.GetExecer(ctx), b, func(rows *sql.Rows) error {
+		run, err := scanJobRun(rows)
+		if err != nil {
+			return err
+		}
+
+		runs = append(runs, run)
+
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return runs, total, nil
+}
+
+func scanJobRun(rows *sql.Rows) (types.JobRun, error) {
+	var run types.JobRun
+
+	err := rows.Scan(
+		&run.ID,
+		&run.Job,
+		&run.Trigger,
+		&run.Status,
+		&run.Error,
+		&run.StartedAt,
+		&run.FinishedAt,
+	)
+
+	return run, err
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/internal/api/enum"
+	"testing"
+)
+
+func TestJobRepositoryRuns(t *testing.T) {
+	jobs := NewJobRepository(newTestDB(t))
+	ctx := context.Background()
+
+	first, err := jobs.StartRun(ctx, "gc", enum.JobTriggerSchedule)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failure := "disk full"
+	if err = jobs.FinishRun(ctx, first, enum.JobRunStatusFailed, &failure); err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := jobs.StartRun(ctx, "gc", enum.JobTriggerManual)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	last, err := jobs.GetLastRuns(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last["gc"].ID != second || last["gc"].Status != enum.JobRunStatusRunning {
+		t.Fatalf("unexpected last run %+v", last["gc"])
+	}
+
+	runs, total, err := jobs.GetRuns(ctx, filter.Filter{Fields: map[string]string{"status": string(enum.JobRunStatusFailed)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || runs[0].Error == nil || *runs[0].Error != failure || runs[0].FinishedAt == nil {
+		t.Fatalf("unexpected failed runs %+v", runs)
+	}
+}
+
+func TestJobRepositoryWithLockSkipsWhileHeld(t *testing.T) {
+	jobs := NewJobRepository(newTestDB(t))
+	ctx := context.Background()
+
+	var nested bool
+
+	locked, err := jobs.WithLock(ctx, "gc", func(ctx context.Context) error {
+		var err error
+		nested, err = jobs.WithLock(ctx, "gc", func(context.Context) error { return nil })
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !locked || nested {
+		t.Fatalf("got locked %v and nested %v, want only the outer run", locked, nested)
+	}
+}
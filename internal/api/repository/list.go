@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"go-fitness/external/db"
+	"go-fitness/external/db/query"
+)
+
+// paginate runs the count and the paged select built by b, calling scan for every selected row
+func paginate(ctx context.Context, execer db.QueryExecer, b *query.Builder, scan func(*sql.Rows) error) (int64, error) {
+	var total int64
+
+	countQuery, countArgs := b.Count()
+	if err := execer.QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		return 0, err
+	}
+
+	if total == 0 {
+		return 0, nil
+	}
+
+	selectQuery, selectArgs := b.Select()
+
+	rows, err := execer.QueryContext(ctx, selectQuery, selectArgs...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = scan(rows); err != nil {
+			return 0, err
+		}
+	}
+
+	return total, rows.Err()
+}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/db"
+	"go-fitness/external/db/query"
+	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/types"
+	"strings"
+	"time"
+)
+
+type OutboxRepository struct {
+	db db.SqlInterface
+}
+
+func NewOutboxRepository(
+	db db.SqlInterface,
+) *OutboxRepository {
+	return &OutboxRepository{
+		db: db,
+	}
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// insertOutbox writes the events with the given execer so callers can pass the transaction of the state change
+func insertOutbox(ctx context.Context, ex execer, events []types.OutboxEvent) error {
+	const query = `
+		INSERT INTO outbox_events (name,payload,status,attempts,next_attempt_at,delivered_to,created_at)
+		VALUES (?,?,?,0,?,'',?)
+	`
+
+	for _, e := range events {
+		if _, err := ex.ExecContext(ctx, query, e.Name, []byte(e.Payload), e.Status, e.NextAttemptAt, e.CreatedAt); err != nil {
+			return fmt.Errorf("insert outbox event %s: %w", e.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Add writes events that are not tied to any other state change
+func (r *OutboxRepository) Add(ctx context.Context, events ...types.OutboxEvent) error {
+	const op = "OutboxRepository.Add"
+
+	if err := insertOutbox(ctx, r.db.GetExecer(ctx), events); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+const outboxColumns = "id,name,payload,status,attempts,next_attempt_at,last_error,delivered_to,delivered_at,created_at"
+
+// Claim leases up to limit due events to the caller identified by token, an expired lease can be claimed again
+func (r *OutboxRepository) Claim(ctx context.Context, token string, lease time.Duration, limit int) ([]types.OutboxEvent, error) {
+	const op = "OutboxRepository.Claim"
+
+	now := time.Now()
+
+	claim := `
+		UPDATE outbox_events SET lock_token = ?, locked_until = ?
+		WHERE status = ? AND next_attempt_at <= ? AND (locked_until IS NULL OR locked_until < ?)
+		ORDER BY id
+		LIMIT ?
+	`
+	if r.db.Dialect() == db.SQLite {
+		// SQLite is built without UPDATE ... LIMIT, the due ids are picked in a subquery instead
+		claim = `
+			UPDATE outbox_events SET lock_token = ?, locked_until = ?
+			WHERE id IN (
+				SELECT id FROM outbox_events
+				WHERE status = ? AND next_attempt_at <= ? AND (locked_until IS NULL OR locked_until < ?)
+				ORDER BY id
+				LIMIT ?
+			)
+		`
+	}
+
+	if _, err := r.db.GetExecer(ctx).ExecContext(ctx, claim,
+		token,
+		now.Add(lease),
+		enum.OutboxStatusPending,
+		now,
+		now,
+		limit,
+	); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := r.db.GetExecer(ctx).QueryContext(ctx,
+		"SELECT "+outboxColumns+" FROM outbox_events WHERE lock_token = ? ORDER BY id",
+		token,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var events []types.OutboxEvent
+	for rows.Next() {
+		e, err := scanOutbox(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// SaveAttempt stores the outcome of a delivery attempt and releases the lease
+func (r *OutboxRepository) SaveAttempt(ctx context.Context, e types.OutboxEvent) error {
+	const op = "OutboxRepository.SaveAttempt"
+
+	const query = `
+		UPDATE outbox_events
+		SET status = ?, attempts = ?, next_attempt_at = ?, last_error = ?, delivered_to = ?, delivered_at = ?,
+			lock_token = NULL, locked_until = NULL
+		WHERE id = ?
+	`
+
+	if _, err := r.db.GetExecer(ctx).ExecContext(ctx, query,
+		e.Status,
+		e.Attempts,
+		e.NextAttemptAt,
+		e.LastError,
+		strings.Join(e.DeliveredTo, ","),
+		e.DeliveredAt,
+		e.ID,
+	); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Retry puts a failed event back in the queue with a fresh attempt budget
+func (r *OutboxRepository) Retry(ctx context.Context, id int64) error {
+	const op = "OutboxRepository.Retry"
+
+	const query = `
+		UPDATE outbox_events SET status = ?, attempts = 0, next_attempt_at = ?, lock_token = NULL, locked_until = NULL
+		WHERE id = ? AND status = ?
+	`
+
+	res, err := r.db.GetExecer(ctx).ExecContext(ctx, query, enum.OutboxStatusPending, time.Now(), id, enum.OutboxStatusFailed)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return checkAffected(op, res)
+}
+
+var outboxSpec = query.Spec{
+	Table:   "outbox_events",
+	Columns: strings.Split(outboxColumns, ","),
+	Filterable: map[string]string{
+		"name":   "name",
+		"status": "status",
+	},
+	Sortable: map[string]string{
+		"id":              "id",
+		"attempts":        "attempts",
+		"next_attempt_at": "next_attempt_at",
+		"created_at":      "created_at",
+	},
+	DefaultSort: "id DESC",
+}
+
+func (r *OutboxRepository) GetList(ctx context.Context, f filter.Filter) ([]types.OutboxEvent, int64, error) {
+	const op = "OutboxRepository.GetList"
+
+	b := query.New(outboxSpec)
+	if err := b.Apply(f); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	events := make([]types.OutboxEvent, 0)
+
+	total, err := paginate(ctx, r.db.GetExecer(ctx), b, func(rows *sql.Rows) error {
+		e, err := scanOutbox(rows)
+		if err != nil {
+			return err
+		}
+
+		events = append(events, e)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return events, total, nil
+}
+
+func scanOutbox(rows *sql.Rows) (types.OutboxEvent, error) {
+	var (
+		e           types.OutboxEvent
+		payload     []byte
+		deliveredTo string
+	)
+
+	if err := rows.Scan(
+		&e.ID,
+		&e.Name,
+		&payload,
+		&e.Status,
+		&e.Attempts,
+		&e.NextAttemptAt,
+		&e.LastError,
+		&deliveredTo,
+		&e.DeliveredAt,
+		&e.CreatedAt,
+	); err != nil {
+		return e, err
+	}
+
+	e.Payload = payload
+
+	if deliveredTo != "" {
+		e.DeliveredTo = strings.Split(deliveredTo, ",")
+	}
+
+	return e, nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/event"
+	"testing"
+	"time"
+)
+
+func addOutboxEvents(t *testing.T, outbox *OutboxRepository, n int) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		e, err := event.NewOutboxEvent(event.VideoProcessed, event.VideoPayload{VideoID: int64(i + 1)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.NextAttemptAt = e.NextAttemptAt.Add(-time.Second)
+
+		if err = outbox.Add(context.Background(), e); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestOutboxRepositoryClaimLeasesOldestEvents(t *testing.T) {
+	outbox := NewOutboxRepository(newTestDB(t))
+	ctx := context.Background()
+
+	addOutboxEvents(t, outbox, 3)
+
+	first, err := outbox.Claim(ctx, "first", time.Minute, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 2 || first[0].ID != 1 || first[1].ID != 2 {
+		t.Fatalf("first claim got %+v, want events 1 and 2", first)
+	}
+
+	second, err := outbox.Claim(ctx, "second", time.Minute, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second) != 1 || second[0].ID != 3 {
+		t.Fatalf("second claim got %+v, want only event 3", second)
+	}
+}
+
+func TestOutboxRepositorySaveAttemptAndRetry(t *testing.T) {
+	outbox := NewOutboxRepository(newTestDB(t))
+	ctx := context.Background()
+
+	addOutboxEvents(t, outbox, 1)
+
+	claimed, err := outbox.Claim(ctx, "token", time.Minute, 1)
+	if err != nil || len(claimed) != 1 {
+		t.Fatalf("claim got %d events, %v", len(claimed), err)
+	}
+
+	failed := claimed[0]
+	failed.Status = enum.OutboxStatusFailed
+	failed.Attempts = 5
+	failed.DeliveredTo = []string{"log"}
+
+	if err = outbox.SaveAttempt(ctx, failed); err != nil {
+		t.Fatal(err)
+	}
+
+	events, _, err := outbox.GetList(ctx, filter.Filter{Fields: map[string]string{"status": string(enum.OutboxStatusFailed)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Attempts != 5 || len(events[0].DeliveredTo) != 1 {
+		t.Fatalf("unexpected failed events %+v", events)
+	}
+
+	if err = outbox.Retry(ctx, failed.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err = outbox.Retry(ctx, failed.ID); err == nil {
+		t.Fatal("retrying a pending event should fail")
+	}
+
+	again, err := outbox.Claim(ctx, "again", time.Minute, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != 1 || again[0].Attempts != 0 {
+		t.Fatalf("retried event was not claimable with a fresh budget: %+v", again)
+	}
+}
@@ -2,8 +2,11 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"go-fitness/external/ctx/filter"
 	"go-fitness/external/db"
+	"go-fitness/external/db/query"
 	"go-fitness/internal/api/types"
 	"time"
 )
@@ -12,6 +15,22 @@ type PortalRepository struct {
 	db db.SqlInterface
 }
 
+var portalSpec = query.Spec{
+	Table:      "portal_videos",
+	Columns:    []string{"id", "name", "video_id", "created_at", "updated_at"},
+	Searchable: []string{"name"},
+	Filterable: map[string]string{
+		"name":     "name",
+		"video_id": "video_id",
+	},
+	Sortable: map[string]string{
+		"id":         "id",
+		"name":       "name",
+		"created_at": "created_at",
+	},
+	DefaultSort: "id DESC",
+}
+
 func NewPortalRepository(
 	db db.SqlInterface,
 ) *PortalRepository {
@@ -20,17 +39,50 @@ func NewPortalRepository(
 	}
 }
 
-func (r *PortalRepository) Store(ctx context.Context, portal types.Portal) error {
+func (r *PortalRepository) Store(ctx context.Context, portal types.Portal) (int64, error) {
 	const op = "PortalRepository.Store"
 
 	const query = "INSERT INTO portal_videos (name,video_id,created_at,updated_at) VALUES (?,?,?,?)"
 
 	now := time.Now()
 
-	_, err := r.db.GetExecer().ExecContext(ctx, query, portal.Name, portal.VideoID, now, now)
+	res, err := r.db.GetExecer(ctx).ExecContext(ctx, query, portal.Name, portal.VideoID, now, now)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+func (r *PortalRepository) GetList(ctx context.Context, f filter.Filter) ([]types.Portal, int64, error) {
+	const op = "PortalRepository.GetList"
+
+	b := query.New(portalSpec)
+	if err := b.Apply(f); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	portals := make([]types.Portal, 0)
+
+	total, err := paginate(ctx, r.db.GetExecer(ctx), b, func(rows *sql.Rows) error {
+		var portal types.Portal
+
+		if err := rows.Scan(&portal.ID, &portal.Name, &portal.VideoID, &portal.CreatedAt, &portal.UpdatedAt); err != nil {
+			return err
+		}
+
+		portals = append(portals, portal)
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return nil
+	return portals, total, nil
 }
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"go-fitness/external/db"
+	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/types"
+	"time"
+)
+
+type PositionRepository struct {
+	db db.SqlInterface
+}
+
+func NewPositionRepository(
+	db db.SqlInterface,
+) *PositionRepository {
+	return &PositionRepository{
+		db: db,
+	}
+}
+
+// Upsert stores the position of the user in the video, completed_at is never cleared once set
+func (r *PositionRepository) Upsert(ctx context.Context, position types.VideoPosition) error {
+	const op = "PositionRepository.Upsert"
+
+	query := `
+		INSERT INTO video_positions (user_id,video_id,position,completed_at,created_at,updated_at) VALUES (?,?,?,?,?,?)
+		ON DUPLICATE KEY UPDATE
+			position = VALUES(position),
+			completed_at = COALESCE(completed_at, VALUES(completed_at)),
+			updated_at = VALUES(updated_at)
+	`
+	if r.db.Dialect() == db.SQLite {
+		query = `
+			INSERT INTO video_positions (user_id,video_id,position,completed_at,created_at,updated_at) VALUES (?,?,?,?,?,?)
+			ON CONFLICT (user_id,video_id) DO UPDATE SET
+				position = excluded.position,
+				completed_at = COALESCE(completed_at, excluded.completed_at),
+				updated_at = excluded.updated_at
+		`
+	}
+
+	now := time.Now()
+
+	_, err := r.db.GetExecer(ctx).ExecContext(ctx, query,
+		position.UserID,
+		position.VideoID,
+		position.Position,
+		position.CompletedAt,
+		now,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *PositionRepository) GetByUserAndVideo(ctx context.Context, userID, videoID int64) (types.VideoPosition, error) {
+	const op = "PositionRepository.GetByUserAndVideo"
+
+	const query = "SELECT id,user_id,video_id,position,completed_at,created_at,updated_at FROM video_positions WHERE user_id = ? AND video_id = ?"
+
+	var position types.VideoPosition
+
+	if err := r.db.GetExecer(ctx).QueryRowContext(ctx, query, userID, videoID).Scan(
+		&position.ID,
+		&position.UserID,
+		&position.VideoID,
+		&position.Position,
+		&position.CompletedAt,
+		&position.CreatedAt,
+		&position.UpdatedAt,
+	); err != nil {
+		return position, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return position, nil
+}
+
+// MarkCompleted sets completed_at unless the position is completed already, it reports whether this call completed it,
+// so of concurrent saves only one sees true
+func (r *PositionRepository) MarkCompleted(ctx context.Context, userID, videoID int64, at time.Time) (bool, error) {
+	const op = "PositionRepository.MarkCompleted"
+
+	const query = "UPDATE video_positions SET completed_at = ? WHERE user_id = ? AND video_id = ? AND completed_at IS NULL"
+
+	res, err := r.db.GetExecer(ctx).ExecContext(ctx, query, at, userID, videoID)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return affected == 1, nil
+}
+
+// GetContinueWatching returns the most recently watched unfinished videos of the user
+func (r *PositionRepository) GetContinueWatching(ctx context.Context, userID int64, limit int) ([]types.ContinueWatching, error) {
+	const op = "PositionRepository.GetContinueWatching"
+
+	const query = `
+		SELECT v.uuid, v.poster, v.duration, vp.position, w.id, w.name, vp.updated_at
+		FROM video_positions vp
+		INNER JOIN videos v ON v.id = vp.video_id AND v.status = ? AND v.deleted_at IS NULL
+		LEFT JOIN workouts w ON w.id = (
+			SELECT MIN(fw.id) FROM workouts fw WHERE fw.video_id = v.id AND fw.deleted_at IS NULL
+		)
+		WHERE vp.user_id = ? AND vp.completed_at IS NULL AND vp.position > 0
+		ORDER BY vp.updated_at DESC
+		LIMIT ?
+	`
+
+	rows, err := r.db.GetExecer(ctx).QueryContext(ctx, query, enum.VideoStatusProcessed, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	items := make([]types.ContinueWatching, 0)
+	for rows.Next() {
+		var item types.ContinueWatching
+
+		if err = rows.Scan(
+			&item.VideoUUID,
+			&item.Poster,
+			&item.Duration,
+			&item.Position,
+			&item.WorkoutID,
+			&item.WorkoutName,
+			&item.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"go-fitness/internal/api/types"
+	"testing"
+	"time"
+)
+
+func TestPositionRepositoryUpsertKeepsCompletion(t *testing.T) {
+	database := newTestDB(t)
+	ctx := context.Background()
+	positions := NewPositionRepository(database)
+
+	videoID := createVideo(t, database, "abc")
+	completed := time.Now()
+
+	if err := positions.Upsert(ctx, types.VideoPosition{UserID: 1, VideoID: videoID, Position: 59, CompletedAt: &completed}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := positions.Upsert(ctx, types.VideoPosition{UserID: 1, VideoID: videoID, Position: 10}); err != nil {
+		t.Fatal(err)
+	}
+
+	position, err := positions.GetByUserAndVideo(ctx, 1, videoID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if position.Position != 10 {
+		t.Fatalf("got position %v, want 10", position.Position)
+	}
+	if position.CompletedAt == nil {
+		t.Fatal("completed_at was cleared by a later position")
+	}
+}
+
+func TestPositionRepositoryMarkCompletedOnlyOnce(t *testing.T) {
+	database := newTestDB(t)
+	ctx := context.Background()
+	positions := NewPositionRepository(database)
+
+	videoID := createVideo(t, database, "abc")
+
+	if err := positions.Upsert(ctx, types.VideoPosition{UserID: 1, VideoID: videoID, Position: 59}); err != nil {
+		t.Fatal(err)
+	}
+
+	first := time.Now().Add(-time.Minute)
+
+	for i, want := range []bool{true, false} {
+		completed, err := positions.MarkCompleted(ctx, 1, videoID, first.Add(time.Duration(i)*time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if completed != want {
+			t.Fatalf("call %d completed %v, want %v", i, completed, want)
+		}
+	}
+
+	position, err := positions.GetByUserAndVideo(ctx, 1, videoID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if position.CompletedAt == nil || !position.CompletedAt.Equal(first) {
+		t.Fatalf("got completed_at %v, want the first completion %v", position.CompletedAt, first)
+	}
+}
+
+func TestPositionRepositoryGetContinueWatching(t *testing.T) {
+	database := newTestDB(t)
+	ctx := context.Background()
+	positions := NewPositionRepository(database)
+
+	watching := createVideo(t, database, "watching")
+	finished := createVideo(t, database, "finished")
+	completed := time.Now()
+
+	workoutID, err := NewWorkoutRepository(database).Create(ctx, types.Workout{Name: "push", VideoID: watching})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a later workout sorts first by name, the id and the name must still come from the same row
+	if _, err = NewWorkoutRepository(database).Create(ctx, types.Workout{Name: "abs", VideoID: watching}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := positions.Upsert(ctx, types.VideoPosition{UserID: 1, VideoID: watching, Position: 12}); err != nil {
+		t.Fatal(err)
+	}
+	if err := positions.Upsert(ctx, types.VideoPosition{UserID: 1, VideoID: finished, Position: 60, CompletedAt: &completed}); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := positions.GetContinueWatching(ctx, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 1 {
+		t.Fatalf("got %d items, want only the unfinished video", len(items))
+	}
+	if items[0].Position != 12 || items[0].WorkoutID == nil || *items[0].WorkoutID != workoutID ||
+		items[0].WorkoutName == nil || *items[0].WorkoutName != "push" {
+		t.Fatalf("unexpected item %+v", items[0])
+	}
+}
+
+func TestPositionRepositoryCascadesWithVideo(t *testing.T) {
+	database := newTestDB(t)
+	ctx := context.Background()
+
+	videoID := createVideo(t, database, "abc")
+
+	if err := NewPositionRepository(database).Upsert(ctx, types.VideoPosition{UserID: 1, VideoID: videoID, Position: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewVideoRepository(database).Delete(ctx, videoID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewPositionRepository(database).GetByUserAndVideo(ctx, 1, videoID); err == nil {
+		t.Fatal("position outlived its video, foreign keys are off")
+	}
+}
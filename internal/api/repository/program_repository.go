@@ -5,7 +5,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"go-fitness/external/ctx/filter"
 	"go-fitness/external/db"
+	"go-fitness/external/db/query"
+	"go-fitness/internal/api/enum"
 	"go-fitness/internal/api/types"
 	"time"
 )
@@ -14,6 +17,22 @@ type ProgramRepository struct {
 	db db.SqlInterface
 }
 
+var programSpec = query.Spec{
+	Table:      "programs",
+	Columns:    []string{"id", "name", "goal_id", "level_id", "period_id"},
+	Searchable: []string{"name"},
+	Filterable: map[string]string{
+		"goal_id":   "goal_id",
+		"level_id":  "level_id",
+		"period_id": "period_id",
+	},
+	Sortable: map[string]string{
+		"id":   "id",
+		"name": "name",
+	},
+	DefaultSort: "id DESC",
+}
+
 func NewProgramRepository(
 	db db.SqlInterface,
 ) *ProgramRepository {
@@ -27,7 +46,7 @@ func (r *ProgramRepository) GetProgramMonth(ctx context.Context, programID int64
 
 	const query = "SELECT id, program_id, month FROM program_months WHERE program_id = ? AND month = ?"
 
-	row := r.db.GetExecer().QueryRowContext(ctx, query, programID, month)
+	row := r.db.GetExecer(ctx).QueryRowContext(ctx, query, programID, month)
 
 	programMonth := types.ProgramMonth{}
 
@@ -49,7 +68,7 @@ func (r *ProgramRepository) CreateProgramMonth(ctx context.Context, programID in
 
 	now := time.Now()
 
-	res, err := r.db.GetExecer().ExecContext(ctx, query, programID, month, now, now)
+	res, err := r.db.GetExecer(ctx).ExecContext(ctx, query, programID, month, now, now)
 	if err != nil {
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
@@ -64,7 +83,7 @@ func (r *ProgramRepository) GetProgramByName(ctx context.Context, name string) (
 
 	const query = "SELECT id, name FROM programs WHERE name = ?"
 
-	row := r.db.GetExecer().QueryRowContext(ctx, query, name)
+	row := r.db.GetExecer(ctx).QueryRowContext(ctx, query, name)
 
 	program := types.Program{}
 
@@ -83,9 +102,9 @@ func (r *ProgramRepository) GetProgramByName(ctx context.Context, name string) (
 func (r *ProgramRepository) GetGoalByName(ctx context.Context, name string) (types.Goal, error) {
 	const op string = "repository.ProgramRepository.GetGoalByID"
 
-	const query = "SELECT id, name FROM goals WHERE name = ?"
+	const query = "SELECT id, name FROM goals WHERE name = ? AND deleted_at IS NULL"
 
-	row := r.db.GetExecer().QueryRowContext(ctx, query, name)
+	row := r.db.GetExecer(ctx).QueryRowContext(ctx, query, name)
 
 	goal := types.Goal{}
 
@@ -106,7 +125,7 @@ func (r *ProgramRepository) GetLevelByName(ctx context.Context, name string) (ty
 
 	const query = "SELECT id, name FROM levels WHERE name = ?"
 
-	row := r.db.GetExecer().QueryRowContext(ctx, query, name)
+	row := r.db.GetExecer(ctx).QueryRowContext(ctx, query, name)
 
 	level := types.Level{}
 
@@ -127,7 +146,7 @@ func (r *ProgramRepository) GetPeriodByName(ctx context.Context, name string) (t
 
 	const query = "SELECT id, name FROM periods WHERE name = ?"
 
-	row := r.db.GetExecer().QueryRowContext(ctx, query, name)
+	row := r.db.GetExecer(ctx).QueryRowContext(ctx, query, name)
 
 	period := types.Period{}
 
@@ -142,3 +161,328 @@ func (r *ProgramRepository) GetPeriodByName(ctx context.Context, name string) (t
 
 	return period, nil
 }
+
+func (r *ProgramRepository) GetList(ctx context.Context, f filter.Filter) ([]types.Program, int64, error) {
+	const op string = "repository.ProgramRepository.GetList"
+
+	b := query.New(programSpec)
+	if err := b.Apply(f); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	programs := make([]types.Program, 0)
+
+	total, err := paginate(ctx, r.db.GetExecer(ctx), b, func(rows *sql.Rows) error {
+		var program types.Program
+
+		if err := rows.Scan(&program.ID, &program.Name, &program.GoalID, &program.LevelID, &program.PeriodID); err != nil {
+			return err
+		}
+
+		programs = append(programs, program)
+
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return programs, total, nil
+}
+
+func (r *ProgramRepository) GetByID(ctx context.Context, id int64) (types.Program, error) {
+	const op string = "repository.ProgramRepository.GetByID"
+
+	const query = "SELECT id, name, goal_id, level_id, period_id FROM programs WHERE id = ?"
+
+	program := types.Program{}
+
+	if err := r.db.GetExecer(ctx).QueryRowContext(ctx, query, id).Scan(
+		&program.ID,
+		&program.Name,
+		&program.GoalID,
+		&program.LevelID,
+		&program.PeriodID,
+	); err != nil {
+		return program, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return program, nil
+}
+
+func (r *ProgramRepository) Create(ctx context.Context, program types.Program) (int64, error) {
+	const op string = "repository.ProgramRepository.Create"
+
+	const query = "INSERT INTO programs (name, goal_id, level_id, period_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)"
+
+	now := time.Now()
+
+	res, err := r.db.GetExecer(ctx).ExecContext(ctx, query, program.Name, program.GoalID, program.LevelID, program.PeriodID, now, now)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+func (r *ProgramRepository) Update(ctx context.Context, program types.Program) error {
+	const op string = "repository.ProgramRepository.Update"
+
+	const query = "UPDATE programs SET name = ?, goal_id = ?, level_id = ?, period_id = ?, updated_at = ? WHERE id = ?"
+
+	_, err := r.db.GetExecer(ctx).ExecContext(ctx, query, program.Name, program.GoalID, program.LevelID, program.PeriodID, time.Now(), program.ID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *ProgramRepository) CheckIfNameExists(ctx context.Context, name string) bool {
+	const query = "SELECT COUNT(*) FROM programs WHERE name = ?"
+
+	var count int
+
+	if err := r.db.GetExecer(ctx).QueryRowContext(ctx, query, name).Scan(&count); err != nil {
+		return false
+	}
+
+	return count > 0
+}
+
+// CheckIfReferencesExist reports whether the goal, level and period of the program all exist
+func (r *ProgramRepository) CheckIfReferencesExist(ctx context.Context, program types.Program) bool {
+	const query = `
+		SELECT
+			(SELECT COUNT(*) FROM goals WHERE id = ? AND deleted_at IS NULL) +
+			(SELECT COUNT(*) FROM levels WHERE id = ?) +
+			(SELECT COUNT(*) FROM periods WHERE id = ?)
+	`
+
+	var count int
+
+	if err := r.db.GetExecer(ctx).QueryRowContext(ctx, query, program.GoalID, program.LevelID, program.PeriodID).Scan(&count); err != nil {
+		return false
+	}
+
+	return count == 3
+}
+
+func (r *ProgramRepository) GetProgramMonths(ctx context.Context, programID int64) ([]types.ProgramMonth, error) {
+	const op string = "repository.ProgramRepository.GetProgramMonths"
+
+	const query = "SELECT id, program_id, month FROM program_months WHERE program_id = ? ORDER BY month"
+
+	rows, err := r.db.GetExecer(ctx).QueryContext(ctx, query, programID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	months := make([]types.ProgramMonth, 0)
+	for rows.Next() {
+		var month types.ProgramMonth
+
+		if err = rows.Scan(&month.ID, &month.ProgramID, &month.Month); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		months = append(months, month)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return months, nil
+}
+
+// DeleteProgramMonth removes the month and detaches its workouts, the workouts themselves are kept
+func (r *ProgramRepository) DeleteProgramMonth(ctx context.Context, programMonthID int64) error {
+	const op string = "repository.ProgramRepository.DeleteProgramMonth"
+
+	err := r.db.DoInTransaction(ctx, func(tx db.QueryExecer) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM program_month_has_workouts WHERE program_month_id = ?", programMonthID); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, "DELETE FROM program_months WHERE id = ?", programMonthID)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *ProgramRepository) CheckIfWorkoutAttached(ctx context.Context, programMonthID, workoutID int64) bool {
+	const query = "SELECT COUNT(*) FROM program_month_has_workouts WHERE program_month_id = ? AND workout_id = ?"
+
+	var count int
+
+	if err := r.db.GetExecer(ctx).QueryRowContext(ctx, query, programMonthID, workoutID).Scan(&count); err != nil {
+		return false
+	}
+
+	return count > 0
+}
+
+// AttachWorkout links the workout to the month after the workouts already attached to it.
+// The month row is locked first, so concurrent attaches to the same month do not take the same position
+func (r *ProgramRepository) AttachWorkout(ctx context.Context, programMonthID, workoutID int64) error {
+	const op string = "repository.ProgramRepository.AttachWorkout"
+
+	const query = `
+		INSERT INTO program_month_has_workouts (workout_id, program_month_id, position)
+		SELECT ?, ?, COALESCE(MAX(position) + 1, 0) FROM program_month_has_workouts WHERE program_month_id = ?
+	`
+
+	err := r.db.DoInTransaction(ctx, func(tx db.QueryExecer) error {
+		var id int64
+		if err := tx.QueryRowContext(ctx,
+			"SELECT id FROM program_months WHERE id = ?"+r.db.Dialect().ForUpdate(),
+			programMonthID,
+		).Scan(&id); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, query, workoutID, programMonthID, programMonthID)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *ProgramRepository) DetachWorkout(ctx context.Context, programMonthID, workoutID int64) error {
+	const op string = "repository.ProgramRepository.DetachWorkout"
+
+	const query = "DELETE FROM program_month_has_workouts WHERE program_month_id = ? AND workout_id = ?"
+
+	res, err := r.db.GetExecer(ctx).ExecContext(ctx, query, programMonthID, workoutID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return checkAffected(op, res)
+}
+
+// ReorderWorkouts sets the position of every workout in the month to its index in workoutIDs
+func (r *ProgramRepository) ReorderWorkouts(ctx context.Context, programMonthID int64, workoutIDs []int64) error {
+	const op string = "repository.ProgramRepository.ReorderWorkouts"
+
+	const query = "UPDATE program_month_has_workouts SET position = ? WHERE program_month_id = ? AND workout_id = ?"
+
+	err := r.db.DoInTransaction(ctx, func(tx db.QueryExecer) error {
+		for position, workoutID := range workoutIDs {
+			if _, err := tx.ExecContext(ctx, query, position, programMonthID, workoutID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetTree loads the program with names of its goal, level and period, its months and their workouts
+func (r *ProgramRepository) GetTree(ctx context.Context, programID int64) (types.ProgramTree, error) {
+	const op string = "repository.ProgramRepository.GetTree"
+
+	const programQuery = `
+		SELECT p.id, p.name, p.goal_id, p.level_id, p.period_id, g.name, l.name, pr.name
+		FROM programs p
+		INNER JOIN goals g ON g.id = p.goal_id
+		INNER JOIN levels l ON l.id = p.level_id
+		INNER JOIN periods pr ON pr.id = p.period_id
+		WHERE p.id = ?
+	`
+
+	const workoutsQuery = `
+		SELECT pm.id, w.id, w.name, w.description, w.video_id, COALESCE(v.uuid, ''), pmw.position
+		FROM program_months pm
+		INNER JOIN program_month_has_workouts pmw ON pmw.program_month_id = pm.id
+		INNER JOIN workouts w ON w.id = pmw.workout_id AND w.deleted_at IS NULL
+		LEFT JOIN videos v ON v.id = w.video_id AND v.deleted_at IS NULL AND v.status = ?
+		WHERE pm.program_id = ?
+		ORDER BY pm.month, pmw.position, w.id
+	`
+
+	tree := types.ProgramTree{}
+
+	if err := r.db.GetExecer(ctx).QueryRowContext(ctx, programQuery, programID).Scan(
+		&tree.ID,
+		&tree.Name,
+		&tree.GoalID,
+		&tree.LevelID,
+		&tree.PeriodID,
+		&tree.GoalName,
+		&tree.LevelName,
+		&tree.PeriodName,
+	); err != nil {
+		return tree, fmt.Errorf("%s: %w", op, err)
+	}
+
+	months, err := r.GetProgramMonths(ctx, programID)
+	if err != nil {
+		return tree, fmt.Errorf("%s: %w", op, err)
+	}
+
+	index := make(map[int64]int, len(months))
+	tree.Months = make([]types.ProgramMonthTree, len(months))
+
+	for i, month := range months {
+		index[month.ID] = i
+		tree.Months[i] = types.ProgramMonthTree{
+			ProgramMonth: month,
+			Workouts:     make([]types.Workout, 0),
+		}
+	}
+
+	rows, err := r.db.GetExecer(ctx).QueryContext(ctx, workoutsQuery, enum.VideoStatusProcessed, programID)
+	if err != nil {
+		return tree, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			monthID int64
+			workout types.Workout
+		)
+
+		if err = rows.Scan(
+			&monthID,
+			&workout.ID,
+			&workout.Name,
+			&workout.Description,
+			&workout.VideoID,
+			&workout.VideoUUID,
+			&workout.Position,
+		); err != nil {
+			return tree, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if i, ok := index[monthID]; ok {
+			tree.Months[i].Workouts = append(tree.Months[i].Workouts, workout)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return tree, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tree, nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"go-fitness/internal/api/types"
+	"testing"
+)
+
+func TestProgramRepositoryTree(t *testing.T) {
+	database := newTestDB(t)
+	ctx := context.Background()
+	programs := NewProgramRepository(database)
+	workouts := NewWorkoutRepository(database)
+
+	for _, statement := range []string{
+		"INSERT INTO goals (id,name) VALUES (1,'strength')",
+		"INSERT INTO levels (id,name) VALUES (1,'beginner')",
+		"INSERT INTO periods (id,name) VALUES (1,'3 months')",
+	} {
+		if _, err := database.GetExecer(ctx).ExecContext(ctx, statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	programID, err := programs.Create(ctx, types.Program{Name: "start", GoalID: 1, LevelID: 1, PeriodID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	monthID, err := programs.CreateProgramMonth(ctx, programID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	videoID := createVideo(t, database, "abc")
+
+	var ids []int64
+	for _, name := range []string{"push", "pull"} {
+		id, err := workouts.Create(ctx, types.Workout{Name: name, VideoID: videoID})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = programs.AttachWorkout(ctx, monthID, id); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err = programs.ReorderWorkouts(ctx, monthID, []int64{ids[1], ids[0]}); err != nil {
+		t.Fatal(err)
+	}
+
+	tree, err := programs.GetTree(ctx, programID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tree.GoalName != "strength" || len(tree.Months) != 1 {
+		t.Fatalf("unexpected tree %+v", tree)
+	}
+	if got := tree.Months[0].Workouts; len(got) != 2 || got[0].Name != "pull" || got[0].VideoUUID == "" {
+		t.Fatalf("unexpected month workouts %+v", got)
+	}
+
+	if err = programs.DeleteProgramMonth(ctx, monthID); err != nil {
+		t.Fatal(err)
+	}
+	if programs.CheckIfWorkoutAttached(ctx, monthID, ids[0]) {
+		t.Fatal("workout is still attached to the deleted month")
+	}
+}
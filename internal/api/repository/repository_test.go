@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-fitness/external/db"
+	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/migrations"
+	"go-fitness/internal/api/types"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// newTestDB returns a migrated in-memory SQLite database that lives as long as the test
+func newTestDB(t *testing.T) db.SqlInterface {
+	t.Helper()
+
+	conn, err := db.OpenSqlite(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	database := db.New(conn, db.SQLite)
+
+	migrator, err := migrations.NewMigrator(slog.New(slog.NewTextHandler(io.Discard, nil)), conn, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return database
+}
+
+func TestMigrationsRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := db.OpenSqlite(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	migrator, err := migrations.NewMigrator(slog.New(slog.NewTextHandler(io.Discard, nil)), conn, db.New(conn, db.SQLite))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = migrator.Down(ctx, applied); err != nil {
+		t.Fatal(err)
+	}
+
+	// the tables that predate the migrations survive a full rollback
+	var count int
+	if err = conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM videos").Scan(&count); err != nil {
+		t.Fatalf("videos was dropped: %v", err)
+	}
+
+	if again, err := migrator.Up(ctx); err != nil || again != applied {
+		t.Fatalf("up after down applied %d, %v, want %d", again, err, applied)
+	}
+}
+
+// createVideo inserts a processed video and returns its id
+func createVideo(t *testing.T, database db.SqlInterface, hashName string) int64 {
+	t.Helper()
+
+	id, err := NewVideoRepository(database).Create(context.Background(), types.Video{
+		HashName: hashName,
+		Status:   enum.VideoStatusProcessed,
+		Duration: 60,
+	}, func(int64, string) ([]types.OutboxEvent, error) { return nil, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return id
+}
+
+func TestWithTxRollsBackEveryRepository(t *testing.T) {
+	database := newTestDB(t)
+	ctx := context.Background()
+
+	var videoID int64
+
+	err := database.WithTx(ctx, func(ctx context.Context) error {
+		id, err := NewVideoRepository(database).Create(ctx, types.Video{HashName: "abc"}, func(int64, string) ([]types.OutboxEvent, error) {
+			return nil, nil
+		})
+		if err != nil {
+			return err
+		}
+		videoID = id
+
+		if _, err = NewWorkoutRepository(database).Create(ctx, types.Workout{Name: "push", VideoID: id}); err != nil {
+			return err
+		}
+
+		return errors.New("owner failed")
+	})
+	if err == nil {
+		t.Fatal("expected the error of fn")
+	}
+
+	if _, err = NewVideoRepository(database).GetByIDWithTrashed(ctx, videoID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("video survived the rollback: %v", err)
+	}
+
+	if NewWorkoutRepository(database).CheckIfNameExists(ctx, "push") {
+		t.Fatal("workout survived the rollback")
+	}
+}
+
+func TestWithTxRunsHooksOnceItIsOver(t *testing.T) {
+	database := newTestDB(t)
+	ctx := context.Background()
+
+	var steps []string
+
+	commit := func(ctx context.Context) error {
+		db.AfterCommit(ctx, func() { steps = append(steps, "queued") })
+		db.OnRollback(ctx, func() { steps = append(steps, "files removed") })
+		return nil
+	}
+
+	if err := database.WithTx(ctx, commit); err != nil {
+		t.Fatal(err)
+	}
+
+	err := database.WithTx(ctx, func(ctx context.Context) error {
+		// a nested unit of work registers on the outer transaction
+		if err := database.WithTx(ctx, commit); err != nil {
+			return err
+		}
+
+		db.OnRollback(ctx, func() { steps = append(steps, "row removed") })
+		return errors.New("owner failed")
+	})
+	if err == nil {
+		t.Fatal("expected the error of fn")
+	}
+
+	want := []string{"queued", "row removed", "files removed"}
+	if strings.Join(steps, ",") != strings.Join(want, ",") {
+		t.Fatalf("got steps %v, want %v", steps, want)
+	}
+}
+
+func TestQueriesAreTracedUnderTheCallerSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	database := newTestDB(t)
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+
+	id := createVideo(t, database, "abc")
+	if _, err := NewVideoRepository(database).GetByIDWithTrashed(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	var query sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Parent().SpanID() == parent.SpanContext().SpanID() {
+			query = span
+		}
+	}
+
+	if query == nil || query.Name() != "db SELECT" {
+		t.Fatalf("no db SELECT span under the request span, got %d spans", len(recorder.Ended()))
+	}
+}
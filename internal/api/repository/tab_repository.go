@@ -2,8 +2,11 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"go-fitness/external/ctx/filter"
 	"go-fitness/external/db"
+	"go-fitness/external/db/query"
 	"go-fitness/internal/api/types"
 	"time"
 )
@@ -12,6 +15,24 @@ type TabRepository struct {
 	db db.SqlInterface
 }
 
+var tabSpec = query.Spec{
+	Table:      "info_tabs",
+	Columns:    []string{"id", "name", "description", "video_id", "deleted_at", "created_at", "updated_at"},
+	Searchable: []string{"name", "description"},
+	Filterable: map[string]string{
+		"name":     "name",
+		"video_id": "video_id",
+	},
+	Sortable: map[string]string{
+		"id":         "id",
+		"name":       "name",
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+	},
+	DefaultSort: "id DESC",
+	SoftDelete:  true,
+}
+
 func NewTabRepository(
 	db db.SqlInterface,
 ) *TabRepository {
@@ -20,19 +41,24 @@ func NewTabRepository(
 	}
 }
 
-func (r *TabRepository) Store(ctx context.Context, tab types.Tab) error {
+func (r *TabRepository) Store(ctx context.Context, tab types.Tab) (int64, error) {
 	const op = "TabRepository.Store"
 
 	const query = "INSERT INTO info_tabs (name, description, video_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?)"
 
 	now := time.Now()
 
-	_, err := r.db.GetExecer().ExecContext(ctx, query, tab.Name, tab.Description, tab.VideoID, now, now)
+	res, err := r.db.GetExecer(ctx).ExecContext(ctx, query, tab.Name, tab.Description, tab.VideoID, now, now)
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return nil
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
 }
 
 func (r *TabRepository) CheckIfNameExists(ctx context.Context, name string) bool {
@@ -41,7 +67,7 @@ func (r *TabRepository) CheckIfNameExists(ctx context.Context, name string) bool
 	const query = "SELECT COUNT(*) FROM info_tabs WHERE name = ?"
 
 	var count int
-	err := r.db.GetExecer().QueryRowContext(ctx, query, name).Scan(&count)
+	err := r.db.GetExecer(ctx).QueryRowContext(ctx, query, name).Scan(&count)
 	if err != nil {
 		return false
 	}
@@ -52,9 +78,9 @@ func (r *TabRepository) CheckIfNameExists(ctx context.Context, name string) bool
 func (r *TabRepository) GetByID(ctx context.Context, id int) (types.Tab, error) {
 	const op = "TabRepository.GetByID"
 
-	const query = "SELECT id, name, description, video_id FROM info_tabs WHERE id = ?"
+	const query = "SELECT id, name, description, video_id FROM info_tabs WHERE id = ? AND deleted_at IS NULL"
 
-	row := r.db.GetExecer().QueryRowContext(ctx, query, id)
+	row := r.db.GetExecer(ctx).QueryRowContext(ctx, query, id)
 
 	tab := types.Tab{}
 
@@ -72,10 +98,106 @@ func (r *TabRepository) Update(ctx context.Context, tab types.Tab) error {
 
 	now := time.Now()
 
-	_, err := r.db.GetExecer().ExecContext(ctx, query, tab.Name, tab.Description, tab.VideoID, now, tab.ID)
+	_, err := r.db.GetExecer(ctx).ExecContext(ctx, query, tab.Name, tab.Description, tab.VideoID, now, tab.ID)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	return nil
 }
+
+func (r *TabRepository) SoftDelete(ctx context.Context, id int) error {
+	const op = "TabRepository.SoftDelete"
+
+	const query = "UPDATE info_tabs SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL"
+
+	res, err := r.db.GetExecer(ctx).ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return checkAffected(op, res)
+}
+
+func (r *TabRepository) Restore(ctx context.Context, id int) error {
+	const op = "TabRepository.Restore"
+
+	const query = "UPDATE info_tabs SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL"
+
+	res, err := r.db.GetExecer(ctx).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return checkAffected(op, res)
+}
+
+func (r *TabRepository) GetTrashedBefore(ctx context.Context, before time.Time) ([]types.Tab, error) {
+	const op = "TabRepository.GetTrashedBefore"
+
+	const query = "SELECT id,name,video_id,deleted_at FROM info_tabs WHERE deleted_at IS NOT NULL AND deleted_at < ?"
+
+	rows, err := r.db.GetExecer(ctx).QueryContext(ctx, query, before)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var tabs []types.Tab
+	for rows.Next() {
+		var tab types.Tab
+
+		if err = rows.Scan(&tab.ID, &tab.Name, &tab.VideoID, &tab.DeletedAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		tabs = append(tabs, tab)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tabs, nil
+}
+
+func (r *TabRepository) ForceDelete(ctx context.Context, id int) error {
+	const op = "TabRepository.ForceDelete"
+
+	const query = "DELETE FROM info_tabs WHERE id = ?"
+
+	_, err := r.db.GetExecer(ctx).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *TabRepository) GetList(ctx context.Context, f filter.Filter) ([]types.Tab, int64, error) {
+	const op = "TabRepository.GetList"
+
+	b := query.New(tabSpec)
+	if err := b.Apply(f); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tabs := make([]types.Tab, 0)
+
+	total, err := paginate(ctx, r.db.GetExecer(ctx), b, func(rows *sql.Rows) error {
+		var tab types.Tab
+
+		if err := rows.Scan(&tab.ID, &tab.Name, &tab.Description, &tab.VideoID, &tab.DeletedAt, &tab.CreatedAt, &tab.UpdatedAt); err != nil {
+			return err
+		}
+
+		tabs = append(tabs, tab)
+
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tabs, total, nil
+}
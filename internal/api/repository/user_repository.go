@@ -24,7 +24,7 @@ func (r *UserRepository) GetUserByUUID(ctx context.Context, uuid string) (types.
 
 	const query = "SELECT id,uuid,name,email FROM users WHERE uuid = ? AND active = 1 AND deleted_at IS NULL AND email_verified_at IS NOT NULL"
 
-	row := r.db.GetExecer().QueryRowContext(ctx, query, uuid)
+	row := r.db.GetExecer(ctx).QueryRowContext(ctx, query, uuid)
 
 	user := types.User{}
 
@@ -39,12 +39,28 @@ func (r *UserRepository) GetUserByUUID(ctx context.Context, uuid string) (types.
 	return user, nil
 }
 
+// GetLocale reads the language of the user profile, the column is added by 0010_users_locale.
+// It is read apart from GetUserByUUID so a database that has not run it yet still authenticates
+func (r *UserRepository) GetLocale(ctx context.Context, userID int64) (string, error) {
+	const op = "repository.user.GetLocale"
+
+	const query = "SELECT COALESCE(locale, '') FROM users WHERE id = ?"
+
+	var locale string
+
+	if err := r.db.GetExecer(ctx).QueryRowContext(ctx, query, userID).Scan(&locale); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return locale, nil
+}
+
 func (r *UserRepository) GetRoleByUserID(ctx context.Context, userID int64) (types.Role, error) {
 	const op = "repository.user.GetRoleByUserID"
 
 	const query = "SELECT r.id, r.name FROM roles r INNER JOIN model_has_roles ur ON r.id = ur.role_id WHERE ur.model_id = ?"
 
-	row := r.db.GetExecer().QueryRowContext(ctx, query, userID)
+	row := r.db.GetExecer(ctx).QueryRowContext(ctx, query, userID)
 
 	role := types.Role{}
 
@@ -2,11 +2,15 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"github.com/google/uuid"
+	"go-fitness/external/ctx/filter"
 	"go-fitness/external/db"
+	"go-fitness/external/db/query"
 	"go-fitness/internal/api/enum"
 	"go-fitness/internal/api/types"
+	"strconv"
 	"time"
 )
 
@@ -29,7 +33,7 @@ func (r *VideoRepository) CheckIfVideoExistByHashName(ctx context.Context, hashN
 
 	var count int
 
-	if err := r.db.GetExecer().QueryRowContext(ctx, query, hashName).Scan(&count); err != nil {
+	if err := r.db.GetExecer(ctx).QueryRowContext(ctx, query, hashName).Scan(&count); err != nil {
 		return false
 	}
 
@@ -39,9 +43,9 @@ func (r *VideoRepository) CheckIfVideoExistByHashName(ctx context.Context, hashN
 func (r *VideoRepository) UpdatePoster(ctx context.Context, id int64, poster string) error {
 	const op = "VideoRepository.UpdatePoster"
 
-	const query = "UPDATE videos SET poster = ? WHERE id = ?"
+	const query = "UPDATE videos SET poster = ?, updated_at = ? WHERE id = ?"
 
-	_, err := r.db.GetExecer().ExecContext(ctx, query, poster, id)
+	_, err := r.db.GetExecer(ctx).ExecContext(ctx, query, poster, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -49,7 +53,12 @@ func (r *VideoRepository) UpdatePoster(ctx context.Context, id int64, poster str
 	return nil
 }
 
-func (r *VideoRepository) Create(ctx context.Context, video types.Video) (int64, error) {
+// Create inserts the video and the outbox events built by events for the new id and uuid in one transaction
+func (r *VideoRepository) Create(
+	ctx context.Context,
+	video types.Video,
+	events func(id int64, uuid string) ([]types.OutboxEvent, error),
+) (int64, error) {
 	const op = "VideoRepository.Create"
 
 	now := time.Now()
@@ -62,20 +71,33 @@ func (r *VideoRepository) Create(ctx context.Context, video types.Video) (int64,
 		INSERT INTO videos (uuid,hash_name,status,duration,poster,created_at,updated_at) VALUES (?,?,?,?,?,?,?)
 	`
 
-	inId, err := r.db.GetExecer().ExecContext(ctx, query,
-		video.UUID,
-		video.HashName,
-		video.Status,
-		video.Duration,
-		video.Poster,
-		video.CreatedAt,
-		video.UpdatedAt,
-	)
-	if err != nil {
-		return 0, fmt.Errorf("%s: %w", op, err)
-	}
+	var id int64
+
+	err := r.db.DoInTransaction(ctx, func(tx db.QueryExecer) error {
+		inId, err := tx.ExecContext(ctx, query,
+			video.UUID,
+			video.HashName,
+			video.Status,
+			video.Duration,
+			video.Poster,
+			video.CreatedAt,
+			video.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		if id, err = inId.LastInsertId(); err != nil {
+			return err
+		}
 
-	id, err := inId.LastInsertId()
+		outbox, err := events(id, video.UUID)
+		if err != nil {
+			return err
+		}
+
+		return insertOutbox(ctx, tx, outbox)
+	})
 	if err != nil {
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
@@ -83,12 +105,19 @@ func (r *VideoRepository) Create(ctx context.Context, video types.Video) (int64,
 	return id, nil
 }
 
-func (r *VideoRepository) UpdateStatus(ctx context.Context, id int64, status enum.VideoStatus) error {
+// UpdateStatus changes the status of the video and writes the given outbox events in the same transaction
+func (r *VideoRepository) UpdateStatus(ctx context.Context, id int64, status enum.VideoStatus, events ...types.OutboxEvent) error {
 	const op = "VideoRepository.UpdateStatus"
 
-	const query = "UPDATE videos SET status = ? WHERE id = ?"
+	const query = "UPDATE videos SET status = ?, updated_at = ? WHERE id = ?"
+
+	err := r.db.DoInTransaction(ctx, func(tx db.QueryExecer) error {
+		if _, err := tx.ExecContext(ctx, query, status, time.Now(), id); err != nil {
+			return err
+		}
 
-	_, err := r.db.GetExecer().ExecContext(ctx, query, status, id)
+		return insertOutbox(ctx, tx, events)
+	})
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -100,16 +129,17 @@ func (r *VideoRepository) GetByUUID(ctx context.Context, uuid string) (*types.Vi
 	const op = "VideoRepository.GetByUUID"
 
 	const query = `
-		SELECT id,uuid,hash_name,status,poster,created_at,updated_at FROM videos WHERE uuid = ? AND status = ?
+		SELECT id,uuid,hash_name,status,duration,poster,created_at,updated_at FROM videos WHERE uuid = ? AND status = ? AND deleted_at IS NULL
 	`
 
 	var video types.Video
 
-	if err := r.db.GetExecer().QueryRowContext(ctx, query, uuid, enum.VideoStatusProcessed).Scan(
+	if err := r.db.GetExecer(ctx).QueryRowContext(ctx, query, uuid, enum.VideoStatusProcessed).Scan(
 		&video.ID,
 		&video.UUID,
 		&video.HashName,
 		&video.Status,
+		&video.Duration,
 		&video.Poster,
 		&video.CreatedAt,
 		&video.UpdatedAt,
@@ -123,9 +153,9 @@ func (r *VideoRepository) GetByUUID(ctx context.Context, uuid string) (*types.Vi
 func (r *VideoRepository) GetListWhereStatusProcessedAndPosterIsNull(ctx context.Context) ([]types.Video, error) {
 	const op = "VideoRepository.GetListWhereStatusProcessedAndPosterIsNull"
 
-	const query = "SELECT id,uuid,hash_name,status,duration,created_at,updated_at FROM videos WHERE status = ? AND poster IS NULL"
+	const query = "SELECT id,uuid,hash_name,status,duration,created_at,updated_at FROM videos WHERE status = ? AND poster IS NULL AND deleted_at IS NULL"
 
-	rows, err := r.db.GetExecer().QueryContext(ctx, query, enum.VideoStatusProcessed)
+	rows, err := r.db.GetExecer(ctx).QueryContext(ctx, query, enum.VideoStatusProcessed)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -148,22 +178,281 @@ func (r *VideoRepository) GetListWhereStatusProcessedAndPosterIsNull(ctx context
 		}
 
 		videos = append(videos, video)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return videos, nil
+}
+
+// GetByStatus returns the videos in the status that are not soft deleted
+func (r *VideoRepository) GetByStatus(ctx context.Context, status enum.VideoStatus) ([]types.Video, error) {
+	const op = "VideoRepository.GetByStatus"
+
+	const query = `
+		SELECT id,uuid,hash_name,status,duration,poster,created_at,updated_at
+		FROM videos
+		WHERE status = ? AND deleted_at IS NULL
+		ORDER BY id
+	`
+
+	rows, err := r.db.GetExecer(ctx).QueryContext(ctx, query, status)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var videos []types.Video
+	for rows.Next() {
+		var video types.Video
+
+		if err = rows.Scan(
+			&video.ID,
+			&video.UUID,
+			&video.HashName,
+			&video.Status,
+			&video.Duration,
+			&video.Poster,
+			&video.CreatedAt,
+			&video.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		videos = append(videos, video)
+	}
 
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+
 	return videos, nil
 }
 
-func (r *VideoRepository) GetList(ctx context.Context, filters map[string]interface{}) ([]types.Video, error) {
+var videoSpec = query.Spec{
+	Table:      "videos",
+	Columns:    []string{"id", "uuid", "hash_name", "status", "duration", "poster", "deleted_at", "created_at", "updated_at"},
+	Searchable: []string{"uuid", "hash_name"},
+	Filterable: map[string]string{
+		"uuid":   "uuid",
+		"status": "status",
+	},
+	Values: map[string]func(string) (interface{}, bool){
+		"status": videoStatusValue,
+	},
+	Sortable: map[string]string{
+		"id":         "id",
+		"duration":   "duration",
+		"status":     "status",
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+	},
+	DefaultSort: "id DESC",
+	SoftDelete:  true,
+}
+
+// videoStatusValue reads a status filter by name or number, status is a number column and MySQL would read
+// any other string as 0
+func videoStatusValue(value string) (interface{}, bool) {
+	if status, ok := enum.ParseVideoStatus(value); ok {
+		return status, true
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < int(enum.VideoStatusUnknown) || n > int(enum.VideoStatusDisabled) {
+		return nil, false
+	}
+
+	return enum.VideoStatus(n), true
+}
+
+func (r *VideoRepository) GetList(ctx context.Context, f filter.Filter) ([]types.Video, int64, error) {
 	const op = "VideoRepository.GetList"
-	var query = "SELECT id,uuid,hash_name,status,duration,created_at,updated_at FROM videos"
 
-	if len(filters) > 0 {
-		for field, value := range filters {
-			query += fmt.Sprintf(" AND %s = '%d'", field, value)
+	b := query.New(videoSpec)
+	if err := b.Apply(f); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	videos := make([]types.Video, 0)
+
+	total, err := paginate(ctx, r.db.GetExecer(ctx), b, func(rows *sql.Rows) error {
+		var video types.Video
+
+		if err := rows.Scan(
+			&video.ID,
+			&video.UUID,
+			&video.HashName,
+			&video.Status,
+			&video.Duration,
+			&video.Poster,
+			&video.DeletedAt,
+			&video.CreatedAt,
+			&video.UpdatedAt,
+		); err != nil {
+			return err
+		}
+
+		videos = append(videos, video)
+
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return videos, total, nil
+}
+
+// GetGCCandidates returns every video row, soft deleted ones included, with whether anything still points at it
+func (r *VideoRepository) GetGCCandidates(ctx context.Context) ([]types.GCVideo, error) {
+	const op = "VideoRepository.GetGCCandidates"
+
+	const query = `
+		SELECT v.id, v.uuid, v.hash_name, v.status, v.deleted_at, v.updated_at,
+			EXISTS(SELECT 1 FROM workouts WHERE video_id = v.id) OR
+			EXISTS(SELECT 1 FROM info_tabs WHERE video_id = v.id) OR
+			EXISTS(SELECT 1 FROM goals WHERE video_id = v.id) OR
+			EXISTS(SELECT 1 FROM portal_videos WHERE video_id = v.id) OR
+			EXISTS(SELECT 1 FROM video_attachments WHERE video_id = v.id)
+		FROM videos v
+	`
+
+	rows, err := r.db.GetExecer(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var videos []types.GCVideo
+	for rows.Next() {
+		var video types.GCVideo
+
+		if err = rows.Scan(
+			&video.ID,
+			&video.UUID,
+			&video.HashName,
+			&video.Status,
+			&video.DeletedAt,
+			&video.UpdatedAt,
+			&video.Referenced,
+		); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		videos = append(videos, video)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return videos, nil
+}
+
+func (r *VideoRepository) Delete(ctx context.Context, id int64, events ...types.OutboxEvent) error {
+	const op = "VideoRepository.Delete"
+
+	const query = "DELETE FROM videos WHERE id = ?"
+
+	err := r.db.DoInTransaction(ctx, func(tx db.QueryExecer) error {
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return err
+		}
+
+		return insertOutbox(ctx, tx, events)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *VideoRepository) GetByUUIDWithTrashed(ctx context.Context, uuid string) (*types.Video, error) {
+	const op = "VideoRepository.GetByUUIDWithTrashed"
+
+	const query = `
+		SELECT id,uuid,hash_name,status,duration,poster,deleted_at,created_at,updated_at FROM videos WHERE uuid = ?
+	`
+
+	return r.scanOne(ctx, op, query, uuid)
+}
+
+func (r *VideoRepository) GetByIDWithTrashed(ctx context.Context, id int64) (*types.Video, error) {
+	const op = "VideoRepository.GetByIDWithTrashed"
+
+	const query = `
+		SELECT id,uuid,hash_name,status,duration,poster,deleted_at,created_at,updated_at FROM videos WHERE id = ?
+	`
+
+	return r.scanOne(ctx, op, query, id)
+}
+
+func (r *VideoRepository) scanOne(ctx context.Context, op string, query string, args ...interface{}) (*types.Video, error) {
+	var video types.Video
+
+	if err := r.db.GetExecer(ctx).QueryRowContext(ctx, query, args...).Scan(
+		&video.ID,
+		&video.UUID,
+		&video.HashName,
+		&video.Status,
+		&video.Duration,
+		&video.Poster,
+		&video.DeletedAt,
+		&video.CreatedAt,
+		&video.UpdatedAt,
+	); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &video, nil
+}
+
+func (r *VideoRepository) SoftDelete(ctx context.Context, id int64, events ...types.OutboxEvent) error {
+	const op = "VideoRepository.SoftDelete"
+
+	const query = "UPDATE videos SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL"
+
+	return r.db.DoInTransaction(ctx, func(tx db.QueryExecer) error {
+		res, err := tx.ExecContext(ctx, query, time.Now(), id)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
 		}
+
+		if err = checkAffected(op, res); err != nil {
+			return err
+		}
+
+		if err = insertOutbox(ctx, tx, events); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		return nil
+	})
+}
+
+func (r *VideoRepository) Restore(ctx context.Context, id int64) error {
+	const op = "VideoRepository.Restore"
+
+	const query = "UPDATE videos SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL"
+
+	res, err := r.db.GetExecer(ctx).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	rows, err := r.db.GetExecer().QueryContext(ctx, query)
+	return checkAffected(op, res)
+}
+
+func (r *VideoRepository) GetTrashedBefore(ctx context.Context, before time.Time) ([]types.Video, error) {
+	const op = "VideoRepository.GetTrashedBefore"
+
+	const query = "SELECT id,uuid,hash_name,status,duration,deleted_at,created_at,updated_at FROM videos WHERE deleted_at IS NOT NULL AND deleted_at < ?"
+
+	rows, err := r.db.GetExecer(ctx).QueryContext(ctx, query, before)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -179,6 +468,7 @@ func (r *VideoRepository) GetList(ctx context.Context, filters map[string]interf
 			&video.HashName,
 			&video.Status,
 			&video.Duration,
+			&video.DeletedAt,
 			&video.CreatedAt,
 			&video.UpdatedAt,
 		); err != nil {
@@ -186,20 +476,47 @@ func (r *VideoRepository) GetList(ctx context.Context, filters map[string]interf
 		}
 
 		videos = append(videos, video)
+	}
 
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+
 	return videos, nil
 }
 
-func (r *VideoRepository) Delete(ctx context.Context, id int64) error {
-	const op = "VideoRepository.Delete"
+// IsReferenced reports whether any workout, tab, goal or portal row (trashed or not) still points at the video
+func (r *VideoRepository) IsReferenced(ctx context.Context, id int64) (bool, error) {
+	const op = "VideoRepository.IsReferenced"
 
-	const query = "DELETE FROM videos WHERE id = ?"
+	const query = `
+		SELECT
+			(SELECT COUNT(*) FROM workouts WHERE video_id = ?) +
+			(SELECT COUNT(*) FROM info_tabs WHERE video_id = ?) +
+			(SELECT COUNT(*) FROM goals WHERE video_id = ?) +
+			(SELECT COUNT(*) FROM portal_videos WHERE video_id = ?) +
+			(SELECT COUNT(*) FROM video_attachments WHERE video_id = ?)
+	`
 
-	_, err := r.db.GetExecer().ExecContext(ctx, query, id)
+	var count int
+
+	if err := r.db.GetExecer(ctx).QueryRowContext(ctx, query, id, id, id, id, id).Scan(&count); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count > 0, nil
+}
+
+// checkAffected turns an update that matched nothing into sql.ErrNoRows
+func checkAffected(op string, res sql.Result) error {
+	affected, err := res.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, sql.ErrNoRows)
+	}
+
 	return nil
 }
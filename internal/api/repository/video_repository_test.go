@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/db/query"
+	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/event"
+	"go-fitness/internal/api/types"
+	"testing"
+	"time"
+)
+
+func TestVideoRepositoryCreateWritesOutboxInSameTransaction(t *testing.T) {
+	database := newTestDB(t)
+	ctx := context.Background()
+	videos := NewVideoRepository(database)
+
+	id, err := videos.Create(ctx, types.Video{HashName: "abc", Status: enum.VideoStatusProcessing}, func(id int64, uuid string) ([]types.OutboxEvent, error) {
+		e, err := event.NewOutboxEvent(event.VideoUploaded, event.VideoPayload{VideoID: id, UUID: uuid})
+		return []types.OutboxEvent{e}, err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	video, err := videos.GetByIDWithTrashed(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if video.HashName != "abc" || video.Status != enum.VideoStatusProcessing {
+		t.Fatalf("unexpected video %+v", video)
+	}
+
+	events, total, err := NewOutboxRepository(database).GetList(ctx, filter.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || events[0].Name != event.VideoUploaded {
+		t.Fatalf("got %d events %+v, want one %s", total, events, event.VideoUploaded)
+	}
+}
+
+func TestVideoRepositoryCreateRollsBackOnEventError(t *testing.T) {
+	database := newTestDB(t)
+	ctx := context.Background()
+
+	_, err := NewVideoRepository(database).Create(ctx, types.Video{HashName: "abc"}, func(int64, string) ([]types.OutboxEvent, error) {
+		return nil, errors.New("boom")
+	})
+	if err == nil {
+		t.Fatal("expected the event error")
+	}
+
+	if NewVideoRepository(database).CheckIfVideoExistByHashName(ctx, "abc") {
+		t.Fatal("video was stored although the transaction failed")
+	}
+}
+
+func TestVideoRepositorySoftDeleteAndRestore(t *testing.T) {
+	database := newTestDB(t)
+	ctx := context.Background()
+	videos := NewVideoRepository(database)
+
+	id := createVideo(t, database, "abc")
+
+	if err := videos.SoftDelete(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+
+	trashed, err := videos.GetTrashedBefore(ctx, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trashed) != 1 || trashed[0].ID != id {
+		t.Fatalf("got trashed %+v, want video %d", trashed, id)
+	}
+
+	if err = videos.SoftDelete(ctx, id); err == nil {
+		t.Fatal("deleting a trashed video twice should fail")
+	}
+
+	if err = videos.Restore(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+
+	video, err := videos.GetByIDWithTrashed(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if video.DeletedAt != nil {
+		t.Fatalf("restored video still has deleted_at %v", video.DeletedAt)
+	}
+}
+
+func TestVideoRepositoryGetListSearchEscapesWildcards(t *testing.T) {
+	database := newTestDB(t)
+	ctx := context.Background()
+
+	// uuids are searchable too, the names avoid hex digits so only hash_name can match
+	createVideo(t, database, "x_y")
+	createVideo(t, database, "xzy")
+	createVideo(t, database, "z%")
+
+	for search, want := range map[string]int64{"x_y": 1, "%": 1, "x": 2} {
+		_, total, err := NewVideoRepository(database).GetList(ctx, filter.Filter{Search: search})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != want {
+			t.Errorf("search %q matched %d videos, want %d", search, total, want)
+		}
+	}
+}
+
+func TestVideoRepositoryGetListFiltersStatus(t *testing.T) {
+	database := newTestDB(t)
+	ctx := context.Background()
+	videos := NewVideoRepository(database)
+
+	createVideo(t, database, "processed")
+	failed := createVideo(t, database, "failed")
+
+	if err := videos.UpdateStatus(ctx, failed, enum.VideoStatusFailed); err != nil {
+		t.Fatal(err)
+	}
+
+	for value, want := range map[string]int64{"processed": 1, "failed": 1, "3": 1, "unknown": 0} {
+		_, total, err := videos.GetList(ctx, filter.Filter{Fields: map[string]string{"status": value}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != want {
+			t.Errorf("status %q matched %d videos, want %d", value, total, want)
+		}
+	}
+
+	for _, value := range []string{"done", "9", ""} {
+		if _, _, err := videos.GetList(ctx, filter.Filter{Fields: map[string]string{"status": value}}); !errors.Is(err, query.ErrUnknownFilter) {
+			t.Errorf("status %q got %v, want ErrUnknownFilter", value, err)
+		}
+	}
+}
+
+func TestVideoRepositoryGetGCCandidatesMarksReferenced(t *testing.T) {
+	database := newTestDB(t)
+	ctx := context.Background()
+
+	used := createVideo(t, database, "used")
+	unused := createVideo(t, database, "unused")
+
+	if _, err := NewWorkoutRepository(database).Create(ctx, types.Workout{Name: "push", VideoID: used}); err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := NewVideoRepository(database).GetGCCandidates(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	referenced := make(map[int64]bool)
+	for _, c := range candidates {
+		referenced[c.ID] = c.Referenced
+	}
+
+	if !referenced[used] || referenced[unused] {
+		t.Fatalf("got referenced %v, want only video %d", referenced, used)
+	}
+}
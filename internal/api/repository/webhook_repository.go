@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/db"
+	"go-fitness/external/db/query"
+	"go-fitness/internal/api/types"
+)
+
+type WebhookRepository struct {
+	db db.SqlInterface
+}
+
+func NewWebhookRepository(
+	db db.SqlInterface,
+) *WebhookRepository {
+	return &WebhookRepository{
+		db: db,
+	}
+}
+
+func (r *WebhookRepository) LogDelivery(ctx context.Context, delivery types.WebhookDelivery) error {
+	const op = "WebhookRepository.LogDelivery"
+
+	const query = `
+		INSERT INTO webhook_deliveries (event_id,webhook,url,event,status_code,error,duration_ms,created_at)
+		VALUES (?,?,?,?,?,?,?,?)
+	`
+
+	if _, err := r.db.GetExecer(ctx).ExecContext(ctx, query,
+		delivery.EventID,
+		delivery.Webhook,
+		delivery.URL,
+		delivery.Event,
+		delivery.StatusCode,
+		delivery.Error,
+		delivery.DurationMS,
+		delivery.CreatedAt,
+	); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+var webhookDeliverySpec = query.Spec{
+	Table:   "webhook_deliveries",
+	Columns: []string{"id", "event_id", "webhook", "url", "event", "status_code", "error", "duration_ms", "created_at"},
+	Filterable: map[string]string{
+		"event_id":    "event_id",
+		"webhook":     "webhook",
+		"event":       "event",
+		"status_code": "status_code",
+	},
+	Sortable: map[string]string{
+		"id":          "id",
+		"duration_ms": "duration_ms",
+		"created_at":  "created_at",
+	},
+	DefaultSort: "id DESC",
+}
+
+func (r *WebhookRepository) GetDeliveries(ctx context.Context, f filter.Filter) ([]types.WebhookDelivery, int64, error) {
+	const op = "WebhookRepository.GetDeliveries"
+
+	b := query.New(webhookDeliverySpec)
+	if err := b.Apply(f); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	deliveries := make([]types.WebhookDelivery, 0)
+
+	total, err := paginate(ctx, r.db.GetExecer(ctx), b, func(rows *sql.Rows) error {
+		var delivery types.WebhookDelivery
+
+		if err := rows.Scan(
+			&delivery.ID,
+			&delivery.EventID,
+			&delivery.Webhook,
+			&delivery.URL,
+			&delivery.Event,
+			&delivery.StatusCode,
+			&delivery.Error,
+			&delivery.DurationMS,
+			&delivery.CreatedAt,
+		); err != nil {
+			return err
+		}
+
+		deliveries = append(deliveries, delivery)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deliveries, total, nil
+}
@@ -2,8 +2,11 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"go-fitness/external/ctx/filter"
 	"go-fitness/external/db"
+	"go-fitness/external/db/query"
 	"go-fitness/internal/api/types"
 	"time"
 )
@@ -12,6 +15,25 @@ type WorkoutRepository struct {
 	db db.SqlInterface
 }
 
+var workoutSpec = query.Spec{
+	Table:      "workouts",
+	Columns:    []string{"id", "name", "description", "video_id", "position", "deleted_at", "created_at", "updated_at"},
+	Searchable: []string{"name", "description"},
+	Filterable: map[string]string{
+		"name":     "name",
+		"video_id": "video_id",
+	},
+	Sortable: map[string]string{
+		"id":         "id",
+		"name":       "name",
+		"position":   "position",
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+	},
+	DefaultSort: "position ASC, id DESC",
+	SoftDelete:  true,
+}
+
 func NewWorkoutRepository(
 	db db.SqlInterface,
 ) *WorkoutRepository {
@@ -25,7 +47,7 @@ func (r *WorkoutRepository) Update(ctx context.Context, id int64, workout types.
 
 	const query = "UPDATE workouts SET name = ?, description = ?, video_id = ?, updated_at = ? WHERE id = ?"
 
-	_, err := r.db.GetExecer().ExecContext(ctx, query, workout.Name, workout.Description, workout.VideoID, time.Now(), id)
+	_, err := r.db.GetExecer(ctx).ExecContext(ctx, query, workout.Name, workout.Description, workout.VideoID, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -33,14 +55,42 @@ func (r *WorkoutRepository) Update(ctx context.Context, id int64, workout types.
 	return nil
 }
 
+func (r *WorkoutRepository) GetByID(ctx context.Context, id int64) (types.Workout, error) {
+	const op = "WorkoutRepository.GetByID"
+
+	const query = `
+		SELECT w.id,w.name,w.description,w.video_id,COALESCE(v.uuid, ''),w.position,w.created_at,w.updated_at
+		FROM workouts w
+		LEFT JOIN videos v ON v.id = w.video_id AND v.deleted_at IS NULL
+		WHERE w.id = ? AND w.deleted_at IS NULL
+	`
+
+	var workout types.Workout
+
+	if err := r.db.GetExecer(ctx).QueryRowContext(ctx, query, id).Scan(
+		&workout.ID,
+		&workout.Name,
+		&workout.Description,
+		&workout.VideoID,
+		&workout.VideoUUID,
+		&workout.Position,
+		&workout.CreatedAt,
+		&workout.UpdatedAt,
+	); err != nil {
+		return workout, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return workout, nil
+}
+
 func (r *WorkoutRepository) GetWorkoutByName(ctx context.Context, name string) (types.Workout, error) {
 	const op = "VideoRepository.GetWorkoutByName"
 
-	const query = "SELECT id,name FROM workouts WHERE name = ?"
+	const query = "SELECT id,name FROM workouts WHERE name = ? AND deleted_at IS NULL"
 
 	var workout types.Workout
 
-	if err := r.db.GetExecer().QueryRowContext(ctx, query, name).Scan(
+	if err := r.db.GetExecer(ctx).QueryRowContext(ctx, query, name).Scan(
 		&workout.ID,
 		&workout.Name,
 	); err != nil {
@@ -57,7 +107,7 @@ func (r *WorkoutRepository) Create(ctx context.Context, workout types.Workout) (
 
 	now := time.Now()
 
-	res, err := r.db.GetExecer().ExecContext(ctx, query, workout.Name, workout.Description, workout.VideoID, now, now)
+	res, err := r.db.GetExecer(ctx).ExecContext(ctx, query, workout.Name, workout.Description, workout.VideoID, now, now)
 	if err != nil {
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
@@ -75,7 +125,7 @@ func (r *WorkoutRepository) AddWorkoutToProgramMonth(ctx context.Context, workou
 
 	const query = "INSERT INTO program_month_has_workouts (workout_id,program_month_id) VALUES (?,?)"
 
-	_, err := r.db.GetExecer().ExecContext(ctx, query, workoutID, programMonthID)
+	_, err := r.db.GetExecer(ctx).ExecContext(ctx, query, workoutID, programMonthID)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -86,13 +136,160 @@ func (r *WorkoutRepository) AddWorkoutToProgramMonth(ctx context.Context, workou
 func (r *WorkoutRepository) CheckIfNameExists(ctx context.Context, name string) bool {
 	const op = "WorkoutRepository.CheckIfNameExists"
 
+	// trashed workouts still reserve their name so they can be restored without a conflict
 	const query = "SELECT COUNT(id) FROM workouts WHERE name = ?"
 
 	var count int
 
-	if err := r.db.GetExecer().QueryRowContext(ctx, query, name).Scan(&count); err != nil {
+	if err := r.db.GetExecer(ctx).QueryRowContext(ctx, query, name).Scan(&count); err != nil {
 		return false
 	}
 
 	return count > 0
 }
+
+// GetIDsByVideoID returns the live workouts that play the video
+func (r *WorkoutRepository) GetIDsByVideoID(ctx context.Context, videoID int64) ([]int64, error) {
+	const op = "WorkoutRepository.GetIDsByVideoID"
+
+	rows, err := r.db.GetExecer(ctx).QueryContext(ctx, "SELECT id FROM workouts WHERE video_id = ? AND deleted_at IS NULL", videoID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (r *WorkoutRepository) SoftDelete(ctx context.Context, id int64) error {
+	const op = "WorkoutRepository.SoftDelete"
+
+	const query = "UPDATE workouts SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL"
+
+	res, err := r.db.GetExecer(ctx).ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return checkAffected(op, res)
+}
+
+func (r *WorkoutRepository) Restore(ctx context.Context, id int64) error {
+	const op = "WorkoutRepository.Restore"
+
+	const query = "UPDATE workouts SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL"
+
+	res, err := r.db.GetExecer(ctx).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return checkAffected(op, res)
+}
+
+func (r *WorkoutRepository) GetTrashedBefore(ctx context.Context, before time.Time) ([]types.Workout, error) {
+	const op = "WorkoutRepository.GetTrashedBefore"
+
+	const query = "SELECT id,name,video_id,deleted_at FROM workouts WHERE deleted_at IS NOT NULL AND deleted_at < ?"
+
+	rows, err := r.db.GetExecer(ctx).QueryContext(ctx, query, before)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var workouts []types.Workout
+	for rows.Next() {
+		var workout types.Workout
+
+		if err = rows.Scan(&workout.ID, &workout.Name, &workout.VideoID, &workout.DeletedAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		workouts = append(workouts, workout)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return workouts, nil
+}
+
+// Reorder sets the position of every given workout to its index in ids, unknown or trashed ids are ignored
+func (r *WorkoutRepository) Reorder(ctx context.Context, ids []int64) error {
+	const op = "WorkoutRepository.Reorder"
+
+	const query = "UPDATE workouts SET position = ? WHERE id = ? AND deleted_at IS NULL"
+
+	err := r.db.DoInTransaction(ctx, func(tx db.QueryExecer) error {
+		for position, id := range ids {
+			if _, err := tx.ExecContext(ctx, query, position, id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ForceDelete permanently removes the workout together with its program month links
+func (r *WorkoutRepository) ForceDelete(ctx context.Context, id int64) error {
+	const op = "WorkoutRepository.ForceDelete"
+
+	err := r.db.DoInTransaction(ctx, func(tx db.QueryExecer) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM program_month_has_workouts WHERE workout_id = ?", id); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, "DELETE FROM workouts WHERE id = ?", id)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *WorkoutRepository) GetList(ctx context.Context, f filter.Filter) ([]types.Workout, int64, error) {
+	const op = "WorkoutRepository.GetList"
+
+	b := query.New(workoutSpec)
+	if err := b.Apply(f); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	workouts := make([]types.Workout, 0)
+
+	total, err := paginate(ctx, r.db.GetExecer(ctx), b, func(rows *sql.Rows) error {
+		var workout types.Workout
+
+		if err := rows.Scan(&workout.ID, &workout.Name, &workout.Description, &workout.VideoID, &workout.Position, &workout.DeletedAt, &workout.CreatedAt, &workout.UpdatedAt); err != nil {
+			return err
+		}
+
+		workouts = append(workouts, workout)
+
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return workouts, total, nil
+}
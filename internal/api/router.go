@@ -3,6 +3,7 @@ package api
 import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"go-fitness/external/metrics"
 	"go-fitness/internal/api/http/handler"
 	md "go-fitness/internal/api/http/middleware"
 	"net/http"
@@ -12,15 +13,21 @@ import (
 func NewRouter(
 	handlers *handler.Handlers,
 	md *md.Middleware,
+	metrics *metrics.Metrics,
 ) *chi.Mux {
 	r := chi.NewRouter()
 
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
+	// outermost after the request id so the access log sees the final status and the user set by the auth middlewares
+	r.Use(md.Logger.New())
+	r.Use(md.Tracing.New())
+	// before Recoverer so a panic is counted as the 500 it turns into
+	r.Use(md.Metrics.New())
 	r.Use(middleware.Recoverer)
+	r.Use(md.Locale.New())
 	r.Use(middleware.URLFormat)
 	r.Use(middleware.Timeout(10 * time.Minute))
-	r.Use(md.Logger.New())
 	r.Use(cors())
 
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -30,6 +37,11 @@ func NewRouter(
 		return
 	})
 
+	r.Get("/livez", handlers.Health.Livez())
+	r.Get("/readyz", handlers.Health.Readyz())
+
+	r.Handle("/metrics", metrics.Handler())
+
 	r.Route("/api/v1", func(r chi.Router) {
 		r.Route("/videos/AQmjberxHcZj4ck", func(r chi.Router) {
 			// FOR TESTING PURPOSES without auth
@@ -38,8 +50,6 @@ func NewRouter(
 				// r.Get("/drive/auth", handlers.Drive.InitiateAuth())
 				// r.Get("/oauth2callback", handlers.Drive.HandleAuthCallback())
 
-				r.Get("/delete-files", handlers.Video.DeleteVideoFilesIfDoesntExistInTable())
-				r.Get("/posters", handlers.Poster.CreatePosterFromUploadedTsFiles())
 				r.Get("/poster/{uuid}", handlers.Poster.GetPosterByUUID())
 
 				r.Get("/{uuid}", handlers.Video.GetVideo())
@@ -66,40 +76,158 @@ func NewRouter(
 		r.Route("/admin/ms/videos", func(r chi.Router) {
 			r.Group(func(r chi.Router) {
 				r.Use(md.AdminAuthMiddleware.New())
+				r.Get("/", handlers.Video.List())
 				r.Get("/{uuid}", handlers.Video.GetVideo())
 				r.Get("/{uuid}/{resolution}", handlers.Video.GetVideo())
+				r.Delete("/{uuid}", handlers.Video.Delete())
+				r.Post("/{uuid}/restore", handlers.Video.Restore())
+			})
+		})
+
+		r.Route("/admin/ms/pusher", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(md.AdminAuthMiddleware.New())
+				r.Post("/auth", handlers.Pusher.AdminAuth())
+			})
+		})
+
+		r.Route("/admin/ms/outbox", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(md.AdminAuthMiddleware.New())
+				r.Get("/", handlers.Outbox.List())
+				r.Post("/{id}/retry", handlers.Outbox.Retry())
+			})
+		})
+
+		r.Route("/admin/ms/webhooks", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(md.AdminAuthMiddleware.New())
+				r.Get("/", handlers.Webhook.List())
+				r.Get("/deliveries", handlers.Webhook.Deliveries())
+			})
+		})
+
+		r.Route("/admin/ms/jobs", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(md.AdminAuthMiddleware.New())
+				r.Get("/", handlers.Scheduler.List())
+				r.Get("/runs", handlers.Scheduler.Runs())
+				r.Post("/{name}/run", handlers.Scheduler.Trigger())
+			})
+		})
+
+		r.Route("/admin/ms/gc", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(md.AdminAuthMiddleware.New())
+				r.Get("/", handlers.GC.Report())
+				r.Post("/run", handlers.GC.Run())
+			})
+		})
+
+		r.Route("/admin/ms/attachments", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(md.AdminAuthMiddleware.New())
+				r.Get("/{owner}/{id}", handlers.Attachment.List())
+				r.Delete("/{owner}/{id}/{role}", handlers.Attachment.Detach())
 			})
 		})
 
 		r.Route("/admin/ms/workouts", func(r chi.Router) {
 			r.Group(func(r chi.Router) {
-				//r.Use(md.AdminAuthMiddleware.New())
+				r.Use(md.AdminAuthMiddleware.New())
+				r.Get("/", handlers.Workout.List())
 				r.Post("/store", handlers.Workout.StoreWorkout())
+				r.Put("/reorder", handlers.Workout.Reorder())
+				r.Get("/{id}", handlers.Workout.Get())
+				r.Put("/{id}", handlers.Workout.Update())
+				r.Put("/{id}/video", handlers.Workout.ReplaceVideo())
+				r.Delete("/{id}", handlers.Workout.Delete())
+				r.Post("/{id}/restore", handlers.Workout.Restore())
 			})
 		})
 
 		r.Route("/admin/ms/goals", func(r chi.Router) {
 			r.Group(func(r chi.Router) {
 				r.Use(md.AdminAuthMiddleware.New())
+				r.Get("/", handlers.Goal.List())
 				r.Put("/{id}/update", handlers.Goal.VideoUpload())
+				r.Delete("/{id}", handlers.Goal.Delete())
+				r.Post("/{id}/restore", handlers.Goal.Restore())
 			})
 		})
 
 		r.Route("/admin/ms/tabs", func(r chi.Router) {
 			r.Group(func(r chi.Router) {
 				r.Use(md.AdminAuthMiddleware.New())
+				r.Get("/", handlers.Tab.List())
 				r.Post("/store", handlers.Tab.Store())
 				r.Put("/{id}/update", handlers.Tab.UpdateVideo())
+				r.Delete("/{id}", handlers.Tab.Delete())
+				r.Post("/{id}/restore", handlers.Tab.Restore())
+			})
+		})
+
+		r.Route("/admin/ms/programs", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(md.AdminAuthMiddleware.New())
+				r.Get("/", handlers.Program.List())
+				r.Post("/", handlers.Program.Store())
+				r.Get("/{id}", handlers.Program.GetTree())
+				r.Put("/{id}", handlers.Program.Update())
+				r.Post("/{id}/months", handlers.Program.AddMonth())
+				r.Delete("/{id}/months/{month}", handlers.Program.DeleteMonth())
+				r.Post("/{id}/months/{month}/workouts", handlers.Program.AttachWorkout())
+				r.Put("/{id}/months/{month}/workouts/reorder", handlers.Program.ReorderWorkouts())
+				r.Delete("/{id}/months/{month}/workouts/{workoutID}", handlers.Program.DetachWorkout())
+			})
+		})
+
+		r.Route("/admin/ms/portal", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(md.AdminAuthMiddleware.New())
+				r.Get("/", handlers.Portal.List())
 			})
 		})
 
 		r.Route("/client/ms/videos", func(r chi.Router) {
 			r.Group(func(r chi.Router) {
 				r.Use(md.ClientAuthMiddleware.New())
+				r.Get("/continue", handlers.Playback.ContinueWatching())
+				r.Get("/{uuid}/position", handlers.Playback.GetPosition())
+				r.Put("/{uuid}/position", handlers.Playback.SavePosition())
 				r.Get("/{uuid}", handlers.Video.GetVideo())
 				r.Get("/{uuid}/{resolution}", handlers.Video.GetVideo())
 			})
 		})
+
+		r.Route("/client/ms/workouts", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(md.ClientAuthMiddleware.New())
+				r.Post("/{id}/complete", handlers.Exp.CompleteWorkout())
+			})
+		})
+
+		r.Route("/client/ms/pusher", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(md.ClientAuthMiddleware.New())
+				r.Post("/auth", handlers.Pusher.ClientAuth())
+			})
+		})
+
+		r.Route("/client/ms/exp", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(md.ClientAuthMiddleware.New())
+				r.Get("/", handlers.Exp.Total())
+				r.Get("/ledger", handlers.Exp.Ledger())
+			})
+		})
+
+		r.Route("/client/ms/programs", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(md.ClientAuthMiddleware.New())
+				r.Get("/{id}", handlers.Program.GetTree())
+			})
+		})
 	})
 
 	return r
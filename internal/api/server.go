@@ -8,7 +8,6 @@ import (
 	"go.uber.org/fx"
 	"log/slog"
 	"net/http"
-	"os"
 	"time"
 )
 
@@ -79,10 +78,6 @@ func RunServer(
 			go func() {
 				log.Info("Starting server", sl.Any("config", server.Addr))
 
-				if os.Getenv("JWT_SECRET") == "" {
-					panic("JWT_SECRET is not set")
-				}
-
 				if err := server.ListenAndServe(); err != nil {
 					log.Error("Server failed", sl.Err(err))
 				} else {
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-fitness/external/apperr"
+	"go-fitness/external/db"
+	"go-fitness/external/logger/logctx"
+	"go-fitness/external/logger/sl"
+	"go-fitness/internal/api/data"
+	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/types"
+	"log/slog"
+)
+
+// AttachmentService is the one place that uploads a video for a piece of content and links it in a role,
+// replacing or detaching releases the previous video according to the replace policy
+type AttachmentService struct {
+	log            *slog.Logger
+	tx             db.Transactor
+	videoService   VideoServiceInterface
+	attachmentRepo AttachmentRepository
+}
+
+type AttachmentRepository interface {
+	OwnerExists(context.Context, enum.AttachmentOwner, int64) (bool, error)
+	Attach(context.Context, types.VideoAttachment) (int64, error)
+	Detach(context.Context, enum.AttachmentOwner, int64, enum.AttachmentRole) (int64, error)
+	GetByOwner(context.Context, enum.AttachmentOwner, int64) ([]types.VideoAttachment, error)
+	DeleteByOwner(context.Context, enum.AttachmentOwner, int64) ([]int64, error)
+}
+
+// VideoAttacher is what content services use to manage their videos
+type VideoAttacher interface {
+	Attach(context.Context, enum.AttachmentOwner, int64, enum.AttachmentRole, *data.VideoData) (int64, error)
+	Create(context.Context, enum.AttachmentOwner, *data.VideoData, func(ctx context.Context, videoID int64) (int64, error)) (int64, error)
+}
+
+func NewAttachmentService(
+	log *slog.Logger,
+	tx db.Transactor,
+	videoService VideoServiceInterface,
+	attachmentRepo AttachmentRepository,
+) *AttachmentService {
+	return &AttachmentService{
+		log:            log,
+		tx:             tx,
+		videoService:   videoService,
+		attachmentRepo: attachmentRepo,
+	}
+}
+
+func (s *AttachmentService) validate(owner enum.AttachmentOwner, role enum.AttachmentRole) error {
+	if !owner.Valid() {
+		return apperr.BadRequest("invalid_owner_type")
+	}
+
+	if !role.Valid() {
+		return apperr.BadRequest("invalid_attachment_role")
+	}
+
+	return nil
+}
+
+// Attach uploads the video and links it to an existing owner in the role, returning the new video id
+func (s *AttachmentService) Attach(
+	ctx context.Context,
+	owner enum.AttachmentOwner,
+	ownerID int64,
+	role enum.AttachmentRole,
+	videoData *data.VideoData,
+) (int64, error) {
+	const op string = "AttachmentService.Attach"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.String("owner_type", string(owner)),
+		sl.Int64("owner_id", ownerID),
+		sl.String("role", string(role)),
+	)
+
+	if err := s.validate(owner, role); err != nil {
+		log.Warn("invalid attachment", sl.Err(err))
+		return 0, err
+	}
+
+	if videoData == nil {
+		log.Warn("no video given")
+		return 0, apperr.BadRequest("video_required")
+	}
+
+	exists, err := s.attachmentRepo.OwnerExists(ctx, owner, ownerID)
+	if err != nil {
+		log.Error("failed to check owner", sl.Err(err))
+		return 0, apperr.Internal("failed_to_attach_video")
+	}
+
+	if !exists {
+		log.Warn("owner not found")
+		return 0, apperr.NotFound("owner_not_found")
+	}
+
+	// the file is written and probed before the transaction, which then holds a connection only for the inserts
+	upload, err := s.videoService.PrepareUpload(ctx, *videoData)
+	if err != nil {
+		log.Error("failed to prepare upload", sl.Err(err))
+		return 0, err
+	}
+
+	var videoID, previous int64
+
+	err = s.tx.WithTx(ctx, func(ctx context.Context) error {
+		if videoID, err = s.videoService.StoreUpload(ctx, upload); err != nil {
+			return err
+		}
+
+		if previous, err = s.attachmentRepo.Attach(ctx, types.VideoAttachment{
+			VideoID:   videoID,
+			OwnerType: owner,
+			OwnerID:   ownerID,
+			Role:      role,
+		}); err != nil {
+			log.Error("failed to attach video", sl.Err(err))
+			return apperr.Internal("failed_to_attach_video")
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.discard(ctx, videoID, upload)
+		return 0, err
+	}
+
+	if previous != 0 && previous != videoID {
+		s.release(ctx, log, previous)
+	}
+
+	return videoID, nil
+}
+
+// Create uploads the main video of a new owner, create inserts the owner row with the video and returns its id.
+// The video, the owner and the attachment are written in one transaction, so none is kept when another fails
+func (s *AttachmentService) Create(
+	ctx context.Context,
+	owner enum.AttachmentOwner,
+	videoData *data.VideoData,
+	create func(ctx context.Context, videoID int64) (int64, error),
+) (int64, error) {
+	const op string = "AttachmentService.Create"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.String("owner_type", string(owner)),
+	)
+
+	if err := s.validate(owner, enum.AttachmentRoleMain); err != nil {
+		log.Warn("invalid attachment", sl.Err(err))
+		return 0, err
+	}
+
+	if videoData == nil {
+		log.Warn("no video given")
+		return 0, apperr.BadRequest("video_required")
+	}
+
+	upload, err := s.videoService.PrepareUpload(ctx, *videoData)
+	if err != nil {
+		log.Error("failed to prepare upload", sl.Err(err))
+		return 0, err
+	}
+
+	var videoID, ownerID int64
+
+	err = s.tx.WithTx(ctx, func(ctx context.Context) error {
+		if videoID, err = s.videoService.StoreUpload(ctx, upload); err != nil {
+			return err
+		}
+
+		if ownerID, err = create(ctx, videoID); err != nil {
+			return err
+		}
+
+		if _, err = s.attachmentRepo.Attach(ctx, types.VideoAttachment{
+			VideoID:   videoID,
+			OwnerType: owner,
+			OwnerID:   ownerID,
+			Role:      enum.AttachmentRoleMain,
+		}); err != nil {
+			log.Error("failed to record attachment", sl.Int64("owner_id", ownerID), sl.Err(err))
+			return apperr.Internal("failed_to_attach_video")
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.discard(ctx, videoID, upload)
+		return 0, err
+	}
+
+	return ownerID, nil
+}
+
+// Detach unlinks the video of the owner in the role and releases it
+func (s *AttachmentService) Detach(ctx context.Context, owner enum.AttachmentOwner, ownerID int64, role enum.AttachmentRole) error {
+	const op string = "AttachmentService.Detach"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.String("owner_type", string(owner)),
+		sl.Int64("owner_id", ownerID),
+		sl.String("role", string(role)),
+	)
+
+	if err := s.validate(owner, role); err != nil {
+		log.Warn("invalid attachment", sl.Err(err))
+		return err
+	}
+
+	if role == enum.AttachmentRoleMain && owner.RequiresMain() {
+		log.Warn("main video can not be detached")
+		return apperr.Conflict("main_video_required")
+	}
+
+	previous, err := s.attachmentRepo.Detach(ctx, owner, ownerID, role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn("attachment not found")
+			return apperr.NotFound("attachment_not_found")
+		}
+
+		log.Error("failed to detach video", sl.Err(err))
+		return apperr.Internal("failed_to_detach_video")
+	}
+
+	s.release(ctx, log, previous)
+
+	return nil
+}
+
+func (s *AttachmentService) List(ctx context.Context, owner enum.AttachmentOwner, ownerID int64) ([]types.VideoAttachment, error) {
+	const op string = "AttachmentService.List"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.String("owner_type", string(owner)),
+		sl.Int64("owner_id", ownerID),
+	)
+
+	if !owner.Valid() {
+		log.Warn("invalid owner type")
+		return nil, apperr.BadRequest("invalid_owner_type")
+	}
+
+	attachments, err := s.attachmentRepo.GetByOwner(ctx, owner, ownerID)
+	if err != nil {
+		log.Error("failed to get attachments", sl.Err(err))
+		return nil, apperr.Internal("failed_to_get_list")
+	}
+
+	return attachments, nil
+}
+
+// discard removes the files of an upload whose transaction failed before its video row was written,
+// once the row is written the rollback of the transaction removes them
+func (s *AttachmentService) discard(ctx context.Context, videoID int64, upload data.PreparedUpload) {
+	if videoID == 0 {
+		s.videoService.DiscardUpload(ctx, upload)
+	}
+}
+
+func (s *AttachmentService) release(ctx context.Context, log *slog.Logger, videoID int64) {
+	if err := s.videoService.Release(ctx, videoID); err != nil {
+		log.Error("failed to release video", sl.Int64("video_id", videoID), sl.Err(err))
+	}
+}
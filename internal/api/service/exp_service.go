@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-fitness/external/apperr"
+	"go-fitness/external/config"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/db"
+	"go-fitness/external/db/query"
+	"go-fitness/external/logger/logctx"
+	"go-fitness/external/logger/sl"
+	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/event"
+	"go-fitness/internal/api/types"
+	"log/slog"
+	"time"
+)
+
+const (
+	ExpReasonWorkoutCompleted = "workout_completed"
+	ExpReasonFirstCompletion  = "first_completion"
+)
+
+type ExpService struct {
+	log         *slog.Logger
+	cfg         *config.Config
+	tx          db.Transactor
+	expRepo     ExpRepository
+	workoutRepo WorkoutRepository
+}
+
+type ExpRepository interface {
+	LockUser(context.Context, int64) error
+	CountCompletions(context.Context, int64, int64, time.Time) (int, error)
+	CountRewardedCompletions(context.Context, int64, time.Time) (int, error)
+	RecordCompletion(context.Context, types.WorkoutCompletion, []types.ExpEntry, func(int64, int64) ([]types.OutboxEvent, error)) (int64, int64, error)
+	GetTotal(context.Context, int64) (int64, error)
+	GetLedger(context.Context, int64, filter.Filter) ([]types.ExpEntry, int64, error)
+}
+
+// WorkoutCompleter marks the workouts that play a fully watched video as completed
+type WorkoutCompleter interface {
+	CompleteByVideo(context.Context, types.User, int64) error
+}
+
+func NewExpService(
+	log *slog.Logger,
+	cfg *config.Config,
+	tx db.Transactor,
+	expRepo ExpRepository,
+	workoutRepo WorkoutRepository,
+) *ExpService {
+	return &ExpService{
+		log:         log,
+		cfg:         cfg,
+		tx:          tx,
+		expRepo:     expRepo,
+		workoutRepo: workoutRepo,
+	}
+}
+
+// CompleteWorkout records the completion and awards the exp the rules allow, returning the amount awarded
+func (s *ExpService) CompleteWorkout(ctx context.Context, user types.User, workoutID int64, source enum.CompletionSource) (int, error) {
+	const op string = "ExpService.CompleteWorkout"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("user_id", user.ID),
+		sl.Int64("workout_id", workoutID),
+		sl.String("source", string(source)),
+	)
+
+	if _, err := s.workoutRepo.GetByID(ctx, workoutID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn("workout not found")
+			return 0, apperr.NotFound("workout_not_found")
+		}
+
+		log.Error("failed to get workout", sl.Err(err))
+		return 0, apperr.Internal("failed_to_complete_workout")
+	}
+
+	var (
+		amount int
+		total  int64
+	)
+
+	// the rules read the ledger, so they run in the transaction of the insert with the user locked,
+	// otherwise two completions at once, a complete request and a playback reaching the end, would both be rewarded
+	err := s.tx.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.expRepo.LockUser(ctx, user.ID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+
+		entries, err := s.rewards(ctx, user.ID, workoutID, now)
+		if err != nil {
+			return err
+		}
+
+		amount = 0
+		for _, entry := range entries {
+			amount += entry.Amount
+		}
+
+		_, total, err = s.expRepo.RecordCompletion(ctx, types.WorkoutCompletion{
+			UserID:    user.ID,
+			WorkoutID: workoutID,
+			Source:    source,
+			CreatedAt: now,
+		}, entries, func(completionID, total int64) ([]types.OutboxEvent, error) {
+			completed, err := event.NewOutboxEvent(event.WorkoutCompleted, event.WorkoutCompletedPayload{
+				UserID:       user.ID,
+				UserUUID:     user.UUID,
+				WorkoutID:    workoutID,
+				CompletionID: completionID,
+				Source:       string(source),
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			if amount == 0 {
+				return []types.OutboxEvent{completed}, nil
+			}
+
+			awarded, err := event.NewOutboxEvent(event.ExpAwarded, event.ExpAwardedPayload{
+				UserID:   user.ID,
+				UserUUID: user.UUID,
+				Amount:   amount,
+				Total:    total,
+				Reason:   ExpReasonWorkoutCompleted,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			return []types.OutboxEvent{completed, awarded}, nil
+		})
+
+		return err
+	})
+	if err != nil {
+		log.Error("failed to record completion", sl.Err(err))
+		return 0, apperr.Internal("failed_to_complete_workout")
+	}
+
+	log.Info("workout completed", sl.Int("exp", amount), sl.Int64("total", total))
+
+	return amount, nil
+}
+
+// CompleteByVideo completes every workout that plays the video
+func (s *ExpService) CompleteByVideo(ctx context.Context, user types.User, videoID int64) error {
+	const op string = "ExpService.CompleteByVideo"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("user_id", user.ID),
+		sl.Int64("video_id", videoID),
+	)
+
+	workoutIDs, err := s.workoutRepo.GetIDsByVideoID(ctx, videoID)
+	if err != nil {
+		log.Error("failed to get workouts of video", sl.Err(err))
+		return err
+	}
+
+	for _, workoutID := range workoutIDs {
+		if _, err = s.CompleteWorkout(ctx, user, workoutID, enum.CompletionSourcePlayback); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rewards builds the ledger entries for a new completion from the configured rules
+func (s *ExpService) rewards(ctx context.Context, userID, workoutID int64, now time.Time) ([]types.ExpEntry, error) {
+	rules := s.cfg.Exp
+
+	if rules.RepeatCooldown > 0 {
+		recent, err := s.expRepo.CountCompletions(ctx, userID, workoutID, now.Add(-rules.RepeatCooldown))
+		if err != nil {
+			return nil, err
+		}
+
+		if recent > 0 {
+			return nil, nil
+		}
+	}
+
+	if rules.DailyLimit > 0 {
+		startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+		rewarded, err := s.expRepo.CountRewardedCompletions(ctx, userID, startOfDay)
+		if err != nil {
+			return nil, err
+		}
+
+		if rewarded >= rules.DailyLimit {
+			return nil, nil
+		}
+	}
+
+	var entries []types.ExpEntry
+
+	if rules.WorkoutCompleted > 0 {
+		entries = append(entries, types.ExpEntry{
+			UserID: userID,
+			Amount: rules.WorkoutCompleted,
+			Reason: ExpReasonWorkoutCompleted,
+		})
+	}
+
+	if rules.FirstCompletionBonus > 0 {
+		ever, err := s.expRepo.CountCompletions(ctx, userID, workoutID, time.Time{})
+		if err != nil {
+			return nil, err
+		}
+
+		if ever == 0 {
+			entries = append(entries, types.ExpEntry{
+				UserID: userID,
+				Amount: rules.FirstCompletionBonus,
+				Reason: ExpReasonFirstCompletion,
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+func (s *ExpService) GetTotal(ctx context.Context, user types.User) (int64, error) {
+	const op string = "ExpService.GetTotal"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("user_id", user.ID),
+	)
+
+	total, err := s.expRepo.GetTotal(ctx, user.ID)
+	if err != nil {
+		log.Error("failed to get exp total", sl.Err(err))
+		return 0, apperr.Internal("failed_to_get_exp")
+	}
+
+	return total, nil
+}
+
+func (s *ExpService) Ledger(ctx context.Context, user types.User) ([]types.ExpEntry, int64, error) {
+	const op string = "ExpService.Ledger"
+
+	f := filter.FromContext(ctx)
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("user_id", user.ID),
+		sl.Any("filter", f),
+	)
+
+	entries, total, err := s.expRepo.GetLedger(ctx, user.ID, f)
+	if err != nil {
+		if errors.Is(err, query.ErrUnknownFilter) || errors.Is(err, query.ErrUnknownSort) {
+			log.Warn("invalid filter", sl.Err(err))
+			return nil, 0, apperr.BadRequest("invalid_filter")
+		}
+
+		log.Error("failed to get exp ledger", sl.Err(err))
+		return nil, 0, apperr.Internal("failed_to_get_list")
+	}
+
+	return entries, total, nil
+}
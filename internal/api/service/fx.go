@@ -13,12 +13,20 @@ func NewService() fx.Option {
 			video.NewVideoService,
 			NewWorkoutService,
 			NewUserService,
+			NewRetentionService,
+			NewPlaybackService,
+
+			fx.Annotate(
+				NewOutboxService,
+				fx.ParamTags(``, ``, ``, `group:"event_sinks"`),
+			),
 			//video.NewWorkerPool,
 			//video.NewTranscodeService,
 
 			fx.Annotate(
 				video.NewWorkerPool,
 				fx.As(new(video.TaskQueue)),
+				fx.As(new(TranscodeQueue)),
 			),
 
 			fx.Annotate(
@@ -32,6 +40,8 @@ func NewService() fx.Option {
 				//fx.As(new(video.UploadAndTranscodeQueueInterface)),
 				fx.As(new(VideoServiceInterface)),
 				fx.As(new(handler.PosterService)),
+				fx.As(new(VideoPurger)),
+				fx.As(new(PosterBackfiller)),
 			),
 
 			fx.Annotate(
@@ -42,6 +52,7 @@ func NewService() fx.Option {
 			fx.Annotate(
 				NewGoogleDriveService,
 				fx.As(new(handler.GoogleDriveService)),
+				fx.As(new(DriveImporter)),
 			),
 
 			fx.Annotate(
@@ -58,6 +69,86 @@ func NewService() fx.Option {
 				NewPortalService,
 				fx.As(new(handler.PortalService)),
 			),
+
+			fx.Annotate(
+				NewProgramService,
+				fx.As(new(handler.ProgramService)),
+			),
+
+			fx.Annotate(
+				NewPlaybackService,
+				fx.As(new(handler.PlaybackService)),
+			),
+
+			fx.Annotate(
+				NewExpService,
+				fx.As(new(handler.ExpService)),
+				fx.As(new(WorkoutCompleter)),
+			),
+
+			fx.Annotate(
+				NewOutboxService,
+				fx.ParamTags(``, ``, ``, `group:"event_sinks"`),
+				fx.As(new(handler.OutboxService)),
+			),
+
+			fx.Annotate(
+				NewWebhookSinks,
+				fx.ResultTags(`group:"event_sinks,flatten"`),
+			),
+
+			fx.Annotate(
+				NewAttachmentService,
+				fx.As(new(handler.AttachmentService)),
+				fx.As(new(VideoAttacher)),
+			),
+
+			fx.Annotate(
+				NewGCService,
+				fx.As(new(handler.GCService)),
+				fx.As(new(GarbageCollector)),
+			),
+
+			fx.Annotate(
+				NewSchedulerService,
+				fx.ParamTags(``, ``, ``, `group:"scheduler_jobs"`),
+				fx.As(new(handler.SchedulerService)),
+				fx.As(new(JobScheduler)),
+			),
+
+			fx.Annotate(
+				RetentionJob,
+				fx.ResultTags(`group:"scheduler_jobs"`),
+			),
+
+			fx.Annotate(
+				GCJob,
+				fx.ResultTags(`group:"scheduler_jobs"`),
+			),
+
+			fx.Annotate(
+				PosterJob,
+				fx.ResultTags(`group:"scheduler_jobs"`),
+			),
+
+			fx.Annotate(
+				NewWebhookService,
+				fx.As(new(handler.WebhookService)),
+			),
+
+			fx.Annotate(
+				NewHealthService,
+				fx.As(new(handler.HealthService)),
+			),
 		),
 	)
 }
+
+// NewWorkers starts the background loops, only the server runs them
+func NewWorkers() fx.Option {
+	return fx.Options(
+		fx.Invoke(RunPlaybackFlusher),
+		fx.Invoke(RunOutboxDispatcher),
+		fx.Invoke(RunScheduler),
+	)
+}
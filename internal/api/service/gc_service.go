@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"go-fitness/external/apperr"
+	"go-fitness/external/config"
+	"go-fitness/external/logger/logctx"
+	"go-fitness/external/logger/sl"
+	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/service/video"
+	"go-fitness/internal/api/types"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type GCService struct {
+	log         *slog.Logger
+	cfg         *config.Config
+	gcRepo      GCRepository
+	videoPurger VideoPurger
+
+	mu      sync.Mutex
+	running bool
+	last    *types.GCReport
+}
+
+type GCRepository interface {
+	GetGCCandidates(context.Context) ([]types.GCVideo, error)
+}
+
+// GarbageCollector is the part of the GCService the scheduled job needs
+type GarbageCollector interface {
+	Run(context.Context, bool) (types.GCReport, error)
+}
+
+func NewGCService(
+	log *slog.Logger,
+	cfg *config.Config,
+	gcRepo GCRepository,
+	videoPurger VideoPurger,
+) *GCService {
+	return &GCService{
+		log:         log,
+		cfg:         cfg,
+		gcRepo:      gcRepo,
+		videoPurger: videoPurger,
+	}
+}
+
+// Run collects orphaned folders, rows whose folder is missing and videos nothing references,
+// removing the first and last kind unless dryRun is set. Unreferenced videos are kept when the replace policy is keep
+// and trashed ones are left to retention. Only one run happens at a time
+func (s *GCService) Run(ctx context.Context, dryRun bool) (types.GCReport, error) {
+	const op string = "GCService.Run"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Bool("dry_run", dryRun),
+	)
+
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		log.Warn("gc is already running")
+		return types.GCReport{}, apperr.Conflict("gc_already_running")
+	}
+	s.running = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	report := types.GCReport{
+		DryRun:    dryRun,
+		StartedAt: time.Now(),
+	}
+
+	before := report.StartedAt.Add(-s.cfg.GC.GracePeriod)
+
+	videos, err := s.gcRepo.GetGCCandidates(ctx)
+	if err != nil {
+		log.Error("failed to get videos", sl.Err(err))
+		return report, apperr.Internal("failed_to_run_gc")
+	}
+
+	videoPath := filepath.Join(s.cfg.HTTPServer.StoragePath, s.cfg.Video.VideoPath)
+
+	entries, err := os.ReadDir(videoPath)
+	if err != nil {
+		log.Error("failed to read video directory", sl.Err(err))
+		return report, apperr.Internal("failed_to_run_gc")
+	}
+
+	known := make(map[string]bool, len(videos))
+	for _, v := range videos {
+		known[v.HashName] = true
+	}
+
+	folders := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		folders[entry.Name()] = true
+
+		if known[entry.Name()] {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+			continue
+		}
+
+		// the folder of an upload exists before its row does
+		if info.ModTime().After(before) {
+			continue
+		}
+
+		report.OrphanFolders = append(report.OrphanFolders, entry.Name())
+
+		if dryRun {
+			continue
+		}
+
+		if err = os.RemoveAll(filepath.Join(videoPath, entry.Name())); err != nil {
+			log.Error("failed to remove orphan folder", sl.String("name", entry.Name()), sl.Err(err))
+			report.Errors = append(report.Errors, err.Error())
+			continue
+		}
+
+		report.RemovedFolders++
+	}
+
+	// with the keep policy a replaced video stays on purpose, it is only reported
+	keep := s.cfg.Video.ReplacePolicy == video.ReplacePolicyKeep
+
+	for _, v := range videos {
+		if !folders[v.HashName] {
+			report.MissingFolders = append(report.MissingFolders, v)
+		}
+
+		// a trashed video waits for retention, so it can still be restored until retention.grace_period is over
+		if v.Referenced || v.DeletedAt != nil || v.Status == enum.VideoStatusProcessing || v.UpdatedAt.After(before) {
+			continue
+		}
+
+		report.Unreferenced = append(report.Unreferenced, v)
+
+		if dryRun || keep {
+			continue
+		}
+
+		purged, err := s.videoPurger.PurgeVideo(ctx, v.ID)
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+			continue
+		}
+
+		if purged {
+			report.PurgedVideos++
+		}
+	}
+
+	report.FinishedAt = time.Now()
+
+	log.Info("gc finished",
+		sl.Int("orphan_folders", len(report.OrphanFolders)),
+		sl.Int("missing_folders", len(report.MissingFolders)),
+		sl.Int("unreferenced", len(report.Unreferenced)),
+		sl.Int("removed_folders", report.RemovedFolders),
+		sl.Int("purged_videos", report.PurgedVideos),
+		sl.Int("errors", len(report.Errors)),
+	)
+
+	for _, v := range report.MissingFolders {
+		log.Warn("video folder is missing", sl.Int64("video_id", v.ID), sl.String("hash_name", v.HashName))
+	}
+
+	s.mu.Lock()
+	s.last = &report
+	s.mu.Unlock()
+
+	return report, nil
+}
+
+// LastReport returns the report of the latest finished run
+func (s *GCService) LastReport() (types.GCReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.last == nil {
+		return types.GCReport{}, apperr.NotFound("gc_report_not_found")
+	}
+
+	return *s.last, nil
+}
+
+// GCJob registers the garbage collection with the scheduler, dry run unless the config says otherwise
+func GCJob(cfg *config.Config, gc GarbageCollector) Job {
+	return Job{
+		Name: "gc",
+		Run: func(ctx context.Context) error {
+			_, err := gc.Run(ctx, cfg.GC.DryRun)
+			return err
+		},
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"go-fitness/external/config"
+	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/service/video"
+	"go-fitness/internal/api/types"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fixedCandidates []types.GCVideo
+
+func (c fixedCandidates) GetGCCandidates(context.Context) ([]types.GCVideo, error) { return c, nil }
+
+type recordingPurger struct{ purged []int64 }
+
+func (p *recordingPurger) PurgeVideo(_ context.Context, id int64) (bool, error) {
+	p.purged = append(p.purged, id)
+	return true, nil
+}
+
+func (p *recordingPurger) PurgeTrashed(context.Context, time.Time) (int, error) { return 0, nil }
+
+func runGC(t *testing.T, policy string, videos ...types.GCVideo) []int64 {
+	t.Helper()
+
+	cfg := &config.Config{}
+	cfg.HTTPServer.StoragePath = t.TempDir()
+	cfg.Video.VideoPath = "videos"
+	cfg.Video.ReplacePolicy = policy
+	cfg.GC.GracePeriod = time.Hour
+
+	for _, v := range videos {
+		if err := os.MkdirAll(filepath.Join(cfg.HTTPServer.StoragePath, cfg.Video.VideoPath, v.HashName), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	purger := &recordingPurger{}
+	s := NewGCService(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg, fixedCandidates(videos), purger)
+
+	if _, err := s.Run(context.Background(), false); err != nil {
+		t.Fatal(err)
+	}
+
+	return purger.purged
+}
+
+func TestGCServicePurgesOnlyLiveUnreferencedVideos(t *testing.T) {
+	old := time.Now().Add(-2 * time.Hour)
+	trashed := old
+
+	purged := runGC(t, video.ReplacePolicyTrash,
+		types.GCVideo{ID: 1, HashName: "unreferenced", Status: enum.VideoStatusProcessed, UpdatedAt: old},
+		types.GCVideo{ID: 2, HashName: "trashed", Status: enum.VideoStatusProcessed, UpdatedAt: old, DeletedAt: &trashed},
+		types.GCVideo{ID: 3, HashName: "referenced", Status: enum.VideoStatusProcessed, UpdatedAt: old, Referenced: true},
+	)
+
+	if len(purged) != 1 || purged[0] != 1 {
+		t.Fatalf("got purged %v, want [1]", purged)
+	}
+}
+
+func TestGCServiceKeepsUnreferencedVideosWithTheKeepPolicy(t *testing.T) {
+	purged := runGC(t, video.ReplacePolicyKeep,
+		types.GCVideo{ID: 1, HashName: "unreferenced", Status: enum.VideoStatusProcessed, UpdatedAt: time.Now().Add(-2 * time.Hour)},
+	)
+
+	if len(purged) != 0 {
+		t.Fatalf("got purged %v, want none", purged)
+	}
+}
@@ -2,18 +2,24 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"go-fitness/external/apperr"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/db/query"
+	"go-fitness/external/logger/logctx"
 	"go-fitness/external/logger/sl"
 	"go-fitness/internal/api/data"
-	"go-fitness/internal/api/service/video"
+	"go-fitness/internal/api/enum"
 	"go-fitness/internal/api/types"
 	"log/slog"
+	"time"
 )
 
 type GoalService struct {
-	log          *slog.Logger
-	videoService *video.VideoService
-	goalRepo     GoalRepository
+	log         *slog.Logger
+	attachments VideoAttacher
+	goalRepo    GoalRepository
 }
 
 type GoalRepository interface {
@@ -21,45 +27,113 @@ type GoalRepository interface {
 	CheckIfNameExists(ctx context.Context, name string) bool
 	GetByID(ctx context.Context, id int) (types.Goal, error)
 	Update(ctx context.Context, goal types.Goal) error
+	SoftDelete(ctx context.Context, id int) error
+	Restore(ctx context.Context, id int) error
+	GetTrashedBefore(ctx context.Context, before time.Time) ([]types.Goal, error)
+	ForceDelete(ctx context.Context, id int) error
+	GetList(ctx context.Context, f filter.Filter) ([]types.Goal, int64, error)
 }
 
 func NewGoalService(
 	log *slog.Logger,
-	videoService *video.VideoService,
+	attachments VideoAttacher,
 	goalRepo GoalRepository,
 ) *GoalService {
 	return &GoalService{
-		log:          log,
-		videoService: videoService,
-		goalRepo:     goalRepo,
+		log:         log,
+		attachments: attachments,
+		goalRepo:    goalRepo,
 	}
 }
 
-func (s *GoalService) Upload(ctx context.Context, goalID int, videoData *data.VideoData) error {
-	const op = "GoalService.Update"
+// Upload attaches the video to the goal in the role, replacing the previous one
+func (s *GoalService) Upload(ctx context.Context, goalID int, role enum.AttachmentRole, videoData *data.VideoData) error {
+	const op = "GoalService.Upload"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 		sl.Int("goal_id", goalID),
 	)
 
 	log.Info("updating goal")
 
-	goal, err := s.goalRepo.GetByID(ctx, goalID)
-	if err != nil {
+	if _, err := s.goalRepo.GetByID(ctx, goalID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn("goal not found")
+			return apperr.NotFound("goal_not_found")
+		}
+
 		log.Error("failed to get goal", sl.Err(err))
-		return errors.New("failed_to_get_goal")
+		return apperr.Internal("failed_to_get_goal")
 	}
 
-	videoID, err := s.videoService.ProcessUpload(ctx, *videoData)
-	if err == nil {
-		goal.VideoID = &videoID
+	_, err := s.attachments.Attach(ctx, enum.AttachmentOwnerGoal, int64(goalID), role, videoData)
+
+	return err
+}
+
+func (s *GoalService) SoftDelete(ctx context.Context, id int) error {
+	const op string = "GoalService.SoftDelete"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int("goal_id", id),
+	)
+
+	if err := s.goalRepo.SoftDelete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn("goal not found or already deleted")
+			return apperr.NotFound("goal_not_found")
+		}
+
+		log.Error("failed to soft delete goal", sl.Err(err))
+		return apperr.Internal("failed_to_delete")
 	}
 
-	if err := s.goalRepo.Update(ctx, goal); err != nil {
-		log.Error("failed to update goal", sl.Err(err))
-		return errors.New("failed_to_update_goal")
+	return nil
+}
+
+func (s *GoalService) Restore(ctx context.Context, id int) error {
+	const op string = "GoalService.Restore"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int("goal_id", id),
+	)
+
+	if err := s.goalRepo.Restore(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn("goal not found or not deleted")
+			return apperr.NotFound("goal_not_found")
+		}
+
+		log.Error("failed to restore goal", sl.Err(err))
+		return apperr.Internal("failed_to_restore")
 	}
 
 	return nil
 }
+
+func (s *GoalService) List(ctx context.Context) ([]types.Goal, int64, error) {
+	const op string = "GoalService.List"
+
+	f := filter.FromContext(ctx)
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Any("filter", f),
+	)
+
+	goals, total, err := s.goalRepo.GetList(ctx, f)
+	if err != nil {
+		if errors.Is(err, query.ErrUnknownFilter) || errors.Is(err, query.ErrUnknownSort) {
+			log.Warn("invalid filter", sl.Err(err))
+			return nil, 0, apperr.BadRequest("invalid_filter")
+		}
+
+		log.Error("failed to get goals", sl.Err(err))
+		return nil, 0, apperr.Internal("failed_to_get_list")
+	}
+
+	return goals, total, nil
+}
@@ -4,9 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"go-fitness/external/logger/logctx"
 	"go-fitness/external/logger/sl"
 	"go-fitness/internal/api/data"
-	"go-fitness/internal/api/types"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/drive/v3"
@@ -27,13 +27,9 @@ type GoogleDriveService struct {
 	programRepository ProgramRepository
 }
 
-type ProgramRepository interface {
-	GetProgramByName(context.Context, string) (types.Program, error)
-	GetGoalByName(context.Context, string) (types.Goal, error)
-	GetLevelByName(context.Context, string) (types.Level, error)
-	GetPeriodByName(context.Context, string) (types.Period, error)
-	CreateProgramMonth(context.Context, int64, int) (int64, error)
-	GetProgramMonth(context.Context, int64, int) (types.ProgramMonth, error)
+// DriveImporter creates workouts from the videos in the fitness-videos folder of Google Drive
+type DriveImporter interface {
+	ProcessParse(ctx context.Context)
 }
 
 type FileInfo struct {
@@ -81,7 +77,7 @@ func (s *GoogleDriveService) initConfig() error {
 func (s *GoogleDriveService) GetAuthURL(ctx context.Context) string {
 	const op string = "service.GoogleDriveService.GetAuthURL"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 	)
 
@@ -100,7 +96,7 @@ func (s *GoogleDriveService) GetAuthURL(ctx context.Context) string {
 func (s *GoogleDriveService) ExchangeCodeForToken(ctx context.Context, code string) error {
 	const op string = "service.GoogleDriveService.ExchangeCodeForToken"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 	)
 
@@ -136,7 +132,7 @@ func (s *GoogleDriveService) collectVideoData(folder *FileInfo, videoDataList *[
 func (s *GoogleDriveService) ProcessParse(ctx context.Context) {
 	const op string = "service.GoogleDriveService.ProcessParse"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 	)
 
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package service
+
+import "syscall"
+
+// freeDiskSpace returns the bytes an unprivileged user can still write on the disk holding path
+func freeDiskSpace(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+
+	return stat.Bavail * uint64(stat.Bsize), nil
+}
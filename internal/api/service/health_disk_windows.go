@@ -0,0 +1,7 @@
+package service
+
+import "errors"
+
+func freeDiskSpace(string) (uint64, error) {
+	return 0, errors.New("free disk space is not supported on windows")
+}
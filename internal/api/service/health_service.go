@@ -0,0 +1,241 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-fitness/external/config"
+	"go-fitness/external/logger/logctx"
+	"go-fitness/external/logger/sl"
+	"go-fitness/external/tracing"
+	"go-fitness/internal/api/event"
+	"go-fitness/internal/api/types"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+type HealthService struct {
+	log    *slog.Logger
+	cfg    *config.Config
+	db     *sql.DB
+	queue  TranscodeQueue
+	pusher event.Pinger
+}
+
+// TranscodeQueue is the part of the worker pool the readiness check looks at
+type TranscodeQueue interface {
+	Saturation() (queued int, capacity int)
+}
+
+// healthCheck runs one check, detail describes what was found and is reported even when the check fails
+type healthCheck struct {
+	name     string
+	critical bool
+	run      func(ctx context.Context) (detail string, err error)
+}
+
+func NewHealthService(
+	log *slog.Logger,
+	cfg *config.Config,
+	db *sql.DB,
+	queue TranscodeQueue,
+	pusher event.Pinger,
+) *HealthService {
+	return &HealthService{
+		log:    log,
+		cfg:    cfg,
+		db:     db,
+		queue:  queue,
+		pusher: pusher,
+	}
+}
+
+// Live reports that the process serves requests, it checks nothing so a slow dependency never gets it restarted
+func (s *HealthService) Live() types.HealthReport {
+	return types.HealthReport{
+		Status:    types.HealthOK,
+		CheckedAt: time.Now(),
+	}
+}
+
+// Ready runs every check concurrently, each bounded by health.timeout
+func (s *HealthService) Ready(ctx context.Context) types.HealthReport {
+	const op string = "HealthService.Ready"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+	)
+
+	checks := []healthCheck{
+		{name: "database", critical: true, run: s.checkDatabase},
+		{name: "storage", critical: true, run: s.checkStorage},
+		{name: "ffmpeg", critical: true, run: s.checkBinary("ffmpeg")},
+		{name: "ffprobe", critical: true, run: s.checkBinary("ffprobe")},
+		{name: "transcode_queue", critical: true, run: s.checkQueue},
+		{name: "pusher", critical: false, run: s.checkPusher},
+	}
+
+	report := types.HealthReport{
+		Status:    types.HealthOK,
+		CheckedAt: time.Now(),
+		Checks:    make([]types.HealthCheck, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check healthCheck) {
+			defer wg.Done()
+			report.Checks[i] = s.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, check := range report.Checks {
+		if check.Status == types.HealthOK {
+			continue
+		}
+
+		log.Warn("health check failed", sl.String("check", check.Name), sl.String("error", check.Error))
+
+		if check.Critical {
+			report.Status = types.HealthFail
+		} else if report.Status == types.HealthOK {
+			report.Status = types.HealthDegraded
+		}
+	}
+
+	report.DurationMs = milliseconds(time.Since(report.CheckedAt))
+
+	return report
+}
+
+func (s *HealthService) run(ctx context.Context, check healthCheck) types.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Health.Timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	type outcome struct {
+		detail string
+		err    error
+	}
+
+	// a check that ignores the context, like the pusher client, still cannot hold the probe past the timeout
+	done := make(chan outcome, 1)
+	go func() {
+		detail, err := check.run(ctx)
+		done <- outcome{detail: detail, err: err}
+	}()
+
+	var result outcome
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result = outcome{err: fmt.Errorf("timed out after %s", s.cfg.Health.Timeout)}
+	}
+
+	status := types.HealthOK
+	var message string
+	if result.err != nil {
+		status = types.HealthFail
+		message = result.err.Error()
+	}
+
+	return types.HealthCheck{
+		Name:       check.name,
+		Status:     status,
+		Critical:   check.critical,
+		DurationMs: milliseconds(time.Since(start)),
+		Detail:     result.detail,
+		Error:      message,
+	}
+}
+
+func (s *HealthService) checkDatabase(ctx context.Context) (string, error) {
+	if err := s.db.PingContext(ctx); err != nil {
+		return "", err
+	}
+
+	stats := s.db.Stats()
+
+	return fmt.Sprintf("%d open, %d in use", stats.OpenConnections, stats.InUse), nil
+}
+
+// checkStorage writes a file to the storage path and checks the free space left on its disk
+func (s *HealthService) checkStorage(context.Context) (string, error) {
+	path := s.cfg.HTTPServer.StoragePath
+
+	file, err := os.CreateTemp(path, ".readyz-*")
+	if err != nil {
+		return "", fmt.Errorf("storage is not writable: %w", err)
+	}
+	_ = file.Close()
+	_ = os.Remove(file.Name())
+
+	free, err := freeDiskSpace(path)
+	if err != nil {
+		return "", err
+	}
+
+	freeMB := free >> 20
+	detail := fmt.Sprintf("%d MB free", freeMB)
+
+	if freeMB < s.cfg.Health.MinFreeDiskMB {
+		return detail, fmt.Errorf("less than %d MB free", s.cfg.Health.MinFreeDiskMB)
+	}
+
+	return detail, nil
+}
+
+// checkBinary runs name -version and reports the first line of the output
+func (s *HealthService) checkBinary(name string) func(context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		path, err := exec.LookPath(name)
+		if err != nil {
+			return "", err
+		}
+
+		var out bytes.Buffer
+		cmd := exec.CommandContext(ctx, path, "-version")
+		cmd.Stdout = &out
+
+		if err = tracing.Run(ctx, cmd); err != nil {
+			return "", err
+		}
+
+		version, _ := bufio.NewReader(&out).ReadString('\n')
+
+		return strings.TrimSpace(version), nil
+	}
+}
+
+func (s *HealthService) checkQueue(context.Context) (string, error) {
+	queued, capacity := s.queue.Saturation()
+	detail := fmt.Sprintf("%d of %d queued", queued, capacity)
+
+	if capacity > 0 && float64(queued)/float64(capacity) >= s.cfg.Health.QueueSaturation {
+		return detail, errors.New("transcode queue is saturated")
+	}
+
+	return detail, nil
+}
+
+func (s *HealthService) checkPusher(context.Context) (string, error) {
+	if err := s.pusher.Ping(); err != nil {
+		return "", err
+	}
+
+	return s.cfg.WSServer.Host, nil
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-fitness/external/config"
+	"go-fitness/external/db"
+	"go-fitness/internal/api/types"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type fixedQueue struct{ queued, capacity int }
+
+func (q fixedQueue) Saturation() (int, int) { return q.queued, q.capacity }
+
+type pingFunc func() error
+
+func (f pingFunc) Ping() error { return f() }
+
+func newTestHealthService(t *testing.T, queue TranscodeQueue, ping pingFunc) *HealthService {
+	t.Helper()
+
+	conn, err := db.OpenSqlite(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	cfg := &config.Config{}
+	cfg.HTTPServer.StoragePath = t.TempDir()
+	cfg.Health.Timeout = time.Second
+	cfg.Health.QueueSaturation = 0.9
+
+	return NewHealthService(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg, conn, queue, ping)
+}
+
+func checksByName(report types.HealthReport) map[string]types.HealthCheck {
+	checks := make(map[string]types.HealthCheck, len(report.Checks))
+	for _, check := range report.Checks {
+		checks[check.Name] = check
+	}
+	return checks
+}
+
+func TestHealthServiceReadyReportsEveryCheck(t *testing.T) {
+	s := newTestHealthService(t, fixedQueue{queued: 1, capacity: 50}, func() error { return errors.New("unreachable") })
+
+	report := s.Ready(context.Background())
+	checks := checksByName(report)
+
+	for _, name := range []string{"database", "storage", "ffmpeg", "ffprobe", "transcode_queue", "pusher"} {
+		if _, ok := checks[name]; !ok {
+			t.Fatalf("check %s missing from %+v", name, report.Checks)
+		}
+	}
+
+	for _, name := range []string{"database", "storage", "transcode_queue"} {
+		if checks[name].Status != types.HealthOK {
+			t.Errorf("%s: got %+v, want ok", name, checks[name])
+		}
+	}
+
+	if pusher := checks["pusher"]; pusher.Status != types.HealthFail || pusher.Critical {
+		t.Errorf("pusher: got %+v, want a non critical failure", pusher)
+	}
+}
+
+func TestHealthServiceReadyFailsWhenQueueIsSaturated(t *testing.T) {
+	s := newTestHealthService(t, fixedQueue{queued: 45, capacity: 50}, func() error { return nil })
+
+	report := s.Ready(context.Background())
+
+	if queue := checksByName(report)["transcode_queue"]; queue.Status != types.HealthFail {
+		t.Fatalf("transcode_queue: got %+v, want fail", queue)
+	}
+	if report.Status != types.HealthFail {
+		t.Fatalf("got status %s, want %s", report.Status, types.HealthFail)
+	}
+}
+
+func TestHealthServiceReadyTimesOutSlowChecks(t *testing.T) {
+	s := newTestHealthService(t, fixedQueue{capacity: 50}, func() error {
+		time.Sleep(2 * time.Second)
+		return nil
+	})
+	s.cfg.Health.Timeout = 50 * time.Millisecond
+
+	start := time.Now()
+	pusher := checksByName(s.Ready(context.Background()))["pusher"]
+
+	if pusher.Status != types.HealthFail || time.Since(start) > time.Second {
+		t.Fatalf("pusher: got %+v after %s, want a timeout", pusher, time.Since(start))
+	}
+}
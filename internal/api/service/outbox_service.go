@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"go-fitness/external/apperr"
+	"go-fitness/external/config"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/db/query"
+	"go-fitness/external/logger/logctx"
+	"go-fitness/external/logger/sl"
+	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/event"
+	"go-fitness/internal/api/types"
+	"go.uber.org/fx"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+type OutboxService struct {
+	log        *slog.Logger
+	cfg        *config.Config
+	outboxRepo OutboxRepository
+	sinks      []event.Sink
+}
+
+type OutboxRepository interface {
+	Add(context.Context, ...types.OutboxEvent) error
+	Claim(context.Context, string, time.Duration, int) ([]types.OutboxEvent, error)
+	SaveAttempt(context.Context, types.OutboxEvent) error
+	Retry(context.Context, int64) error
+	GetList(context.Context, filter.Filter) ([]types.OutboxEvent, int64, error)
+}
+
+func NewOutboxService(
+	log *slog.Logger,
+	cfg *config.Config,
+	outboxRepo OutboxRepository,
+	sinks []event.Sink,
+) *OutboxService {
+	return &OutboxService{
+		log:        log,
+		cfg:        cfg,
+		outboxRepo: outboxRepo,
+		sinks:      sinks,
+	}
+}
+
+// Dispatch delivers one batch of due events and returns how many were claimed
+func (s *OutboxService) Dispatch(ctx context.Context) (int, error) {
+	const op string = "OutboxService.Dispatch"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+	)
+
+	events, err := s.outboxRepo.Claim(ctx, uuid.New().String(), s.cfg.Outbox.Lease, s.cfg.Outbox.BatchSize)
+	if err != nil {
+		log.Error("failed to claim outbox events", sl.Err(err))
+		return 0, err
+	}
+
+	for _, e := range events {
+		s.deliver(ctx, e)
+	}
+
+	return len(events), nil
+}
+
+// deliver sends the event to every accepting sink it has not reached yet and records the attempt
+func (s *OutboxService) deliver(ctx context.Context, e types.OutboxEvent) {
+	const op string = "OutboxService.deliver"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("event_id", e.ID),
+		sl.String("name", e.Name),
+	)
+
+	var failures []string
+
+	for _, sink := range s.sinks {
+		if !sink.Accepts(e.Name) || slices.Contains(e.DeliveredTo, sink.Name()) {
+			continue
+		}
+
+		if err := sink.Deliver(ctx, e); err != nil {
+			log.Warn("delivery failed", sl.String("sink", sink.Name()), sl.Err(err))
+			failures = append(failures, sink.Name()+": "+err.Error())
+			continue
+		}
+
+		e.DeliveredTo = append(e.DeliveredTo, sink.Name())
+	}
+
+	now := time.Now()
+	e.Attempts++
+
+	if len(failures) == 0 {
+		e.Status = enum.OutboxStatusDelivered
+		e.LastError = nil
+		e.DeliveredAt = &now
+	} else {
+		lastError := strings.Join(failures, "; ")
+		e.LastError = &lastError
+		e.NextAttemptAt = now.Add(s.backoff(e.Attempts))
+
+		if e.Attempts >= s.cfg.Outbox.MaxAttempts {
+			e.Status = enum.OutboxStatusFailed
+			log.Error("giving up on event", sl.Int("attempts", e.Attempts), sl.String("error", lastError))
+		}
+	}
+
+	if err := s.outboxRepo.SaveAttempt(ctx, e); err != nil {
+		log.Error("failed to save delivery attempt", sl.Err(err))
+	}
+}
+
+// backoff doubles the retry delay with every attempt up to the configured maximum
+func (s *OutboxService) backoff(attempts int) time.Duration {
+	delay := s.cfg.Outbox.RetryBackoff
+	for i := 1; i < attempts && delay < s.cfg.Outbox.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, s.cfg.Outbox.MaxBackoff)
+}
+
+func (s *OutboxService) List(ctx context.Context) ([]types.OutboxEvent, int64, error) {
+	const op string = "OutboxService.List"
+
+	f := filter.FromContext(ctx)
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Any("filter", f),
+	)
+
+	events, total, err := s.outboxRepo.GetList(ctx, f)
+	if err != nil {
+		if errors.Is(err, query.ErrUnknownFilter) || errors.Is(err, query.ErrUnknownSort) {
+			log.Warn("invalid filter", sl.Err(err))
+			return nil, 0, apperr.BadRequest("invalid_filter")
+		}
+
+		log.Error("failed to get outbox events", sl.Err(err))
+		return nil, 0, apperr.Internal("failed_to_get_list")
+	}
+
+	return events, total, nil
+}
+
+// Retry queues a failed event for delivery again
+func (s *OutboxService) Retry(ctx context.Context, id int64) error {
+	const op string = "OutboxService.Retry"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("event_id", id),
+	)
+
+	if err := s.outboxRepo.Retry(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn("no failed event to retry")
+			return apperr.NotFound("event_not_found")
+		}
+
+		log.Error("failed to retry event", sl.Err(err))
+		return apperr.Internal("failed_to_retry_event")
+	}
+
+	return nil
+}
+
+// RunOutboxDispatcher polls the outbox for the lifetime of the app, draining full batches without waiting
+func RunOutboxDispatcher(
+	lc fx.Lifecycle,
+	log *slog.Logger,
+	cfg *config.Config,
+	outbox *OutboxService,
+) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			log.Info("Starting outbox dispatcher", sl.String("interval", cfg.Outbox.PollInterval.String()))
+
+			go func() {
+				defer close(done)
+
+				ticker := time.NewTicker(cfg.Outbox.PollInterval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						for ctx.Err() == nil {
+							claimed, err := outbox.Dispatch(ctx)
+							if err != nil || claimed < cfg.Outbox.BatchSize {
+								break
+							}
+						}
+					}
+				}
+			}()
+
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	})
+}
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-fitness/external/apperr"
+	"go-fitness/external/config"
+	"go-fitness/external/logger/logctx"
+	"go-fitness/external/logger/sl"
+	"go-fitness/internal/api/types"
+	"go.uber.org/fx"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type PlaybackService struct {
+	log          *slog.Logger
+	cfg          *config.Config
+	positionRepo PositionRepository
+	videoFinder  VideoFinder
+	completer    WorkoutCompleter
+
+	mu      sync.Mutex
+	pending map[positionKey]types.VideoPosition
+}
+
+type PositionRepository interface {
+	Upsert(context.Context, types.VideoPosition) error
+	GetByUserAndVideo(context.Context, int64, int64) (types.VideoPosition, error)
+	MarkCompleted(context.Context, int64, int64, time.Time) (bool, error)
+	GetContinueWatching(context.Context, int64, int) ([]types.ContinueWatching, error)
+}
+
+type VideoFinder interface {
+	GetByUUID(context.Context, string) (*types.Video, error)
+}
+
+type positionKey struct {
+	userID  int64
+	videoID int64
+}
+
+func NewPlaybackService(
+	log *slog.Logger,
+	cfg *config.Config,
+	positionRepo PositionRepository,
+	videoFinder VideoFinder,
+	completer WorkoutCompleter,
+) *PlaybackService {
+	return &PlaybackService{
+		log:          log,
+		cfg:          cfg,
+		positionRepo: positionRepo,
+		videoFinder:  videoFinder,
+		completer:    completer,
+		pending:      make(map[positionKey]types.VideoPosition),
+	}
+}
+
+// SavePosition buffers the position until the next flush, a completed video is written immediately
+func (s *PlaybackService) SavePosition(ctx context.Context, user types.User, uuid string, position float64) (types.VideoPosition, error) {
+	const op string = "PlaybackService.SavePosition"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("user_id", user.ID),
+		sl.String("uuid", uuid),
+	)
+
+	video, err := s.videoFinder.GetByUUID(ctx, uuid)
+	if err != nil {
+		log.Warn("video not found", sl.Err(err))
+		return types.VideoPosition{}, apperr.NotFound("video_not_found")
+	}
+
+	if video.Duration > 0 && position > video.Duration {
+		position = video.Duration
+	}
+
+	now := time.Now()
+
+	vp := types.VideoPosition{
+		UserID:    user.ID,
+		VideoID:   video.ID,
+		Position:  position,
+		UpdatedAt: now,
+	}
+
+	if video.Duration > 0 && position >= video.Duration*s.cfg.Playback.CompleteRatio {
+		s.mu.Lock()
+		delete(s.pending, positionKey{user.ID, video.ID})
+		s.mu.Unlock()
+
+		if err = s.positionRepo.Upsert(ctx, vp); err != nil {
+			log.Error("failed to save position", sl.Err(err))
+			return types.VideoPosition{}, apperr.Internal("failed_to_save_position")
+		}
+
+		// the conditional write decides which of concurrent saves completed the video
+		completed, err := s.positionRepo.MarkCompleted(ctx, user.ID, video.ID, now)
+		if err != nil {
+			log.Error("failed to complete position", sl.Err(err))
+			return types.VideoPosition{}, apperr.Internal("failed_to_save_position")
+		}
+
+		if !completed {
+			previous, err := s.positionRepo.GetByUserAndVideo(ctx, user.ID, video.ID)
+			if err != nil {
+				log.Error("failed to get position", sl.Err(err))
+				return types.VideoPosition{}, apperr.Internal("failed_to_save_position")
+			}
+
+			vp.CompletedAt = previous.CompletedAt
+			return vp, nil
+		}
+
+		vp.CompletedAt = &now
+
+		// the video was just watched to the end, the workouts playing it count as done
+		if err = s.completer.CompleteByVideo(ctx, user, video.ID); err != nil {
+			log.Error("failed to complete workouts", sl.Err(err))
+		}
+
+		return vp, nil
+	}
+
+	s.mu.Lock()
+	s.pending[positionKey{user.ID, video.ID}] = vp
+	s.mu.Unlock()
+
+	return vp, nil
+}
+
+// GetPosition returns the last known position of the user in the video, including a not yet flushed one
+func (s *PlaybackService) GetPosition(ctx context.Context, user types.User, uuid string) (types.VideoPosition, error) {
+	const op string = "PlaybackService.GetPosition"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("user_id", user.ID),
+		sl.String("uuid", uuid),
+	)
+
+	video, err := s.videoFinder.GetByUUID(ctx, uuid)
+	if err != nil {
+		log.Warn("video not found", sl.Err(err))
+		return types.VideoPosition{}, apperr.NotFound("video_not_found")
+	}
+
+	vp, err := s.positionRepo.GetByUserAndVideo(ctx, user.ID, video.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Error("failed to get position", sl.Err(err))
+		return types.VideoPosition{}, apperr.Internal("failed_to_get_position")
+	}
+
+	s.mu.Lock()
+	pending, ok := s.pending[positionKey{user.ID, video.ID}]
+	s.mu.Unlock()
+
+	if ok {
+		vp.Position = pending.Position
+		vp.UpdatedAt = pending.UpdatedAt
+	}
+
+	return vp, nil
+}
+
+// ContinueWatching flushes the buffered positions of the user and lists the unfinished videos
+func (s *PlaybackService) ContinueWatching(ctx context.Context, user types.User) ([]types.ContinueWatching, error) {
+	const op string = "PlaybackService.ContinueWatching"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("user_id", user.ID),
+	)
+
+	s.flush(ctx, func(key positionKey) bool { return key.userID == user.ID })
+
+	items, err := s.positionRepo.GetContinueWatching(ctx, user.ID, s.cfg.Playback.ContinueLimit)
+	if err != nil {
+		log.Error("failed to get continue watching", sl.Err(err))
+		return nil, apperr.Internal("failed_to_get_list")
+	}
+
+	return items, nil
+}
+
+// Flush writes every buffered position to the database
+func (s *PlaybackService) Flush(ctx context.Context) {
+	s.flush(ctx, func(positionKey) bool { return true })
+}
+
+func (s *PlaybackService) flush(ctx context.Context, match func(positionKey) bool) {
+	const op string = "PlaybackService.flush"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+	)
+
+	s.mu.Lock()
+	batch := make(map[positionKey]types.VideoPosition)
+	for key, vp := range s.pending {
+		if match(key) {
+			batch[key] = vp
+			delete(s.pending, key)
+		}
+	}
+	s.mu.Unlock()
+
+	for key, vp := range batch {
+		if err := s.positionRepo.Upsert(ctx, vp); err != nil {
+			log.Error("failed to flush position",
+				sl.Int64("user_id", key.userID),
+				sl.Int64("video_id", key.videoID),
+				sl.Err(err),
+			)
+
+			// keep it for the next flush unless a newer position arrived meanwhile
+			s.mu.Lock()
+			if _, ok := s.pending[key]; !ok {
+				s.pending[key] = vp
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// RunPlaybackFlusher periodically writes buffered positions and flushes what is left on shutdown
+func RunPlaybackFlusher(
+	lc fx.Lifecycle,
+	log *slog.Logger,
+	cfg *config.Config,
+	playback *PlaybackService,
+) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			log.Info("Starting playback flusher", sl.String("interval", cfg.Playback.FlushInterval.String()))
+
+			go func() {
+				defer close(done)
+
+				ticker := time.NewTicker(cfg.Playback.FlushInterval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						playback.Flush(ctx)
+					}
+				}
+			}()
+
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			<-done
+			playback.Flush(stopCtx)
+			return nil
+		},
+	})
+}
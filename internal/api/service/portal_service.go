@@ -3,57 +3,85 @@ package service
 import (
 	"context"
 	"errors"
+	"go-fitness/external/apperr"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/db/query"
+	"go-fitness/external/logger/logctx"
 	"go-fitness/external/logger/sl"
 	"go-fitness/internal/api/data"
-	"go-fitness/internal/api/service/video"
+	"go-fitness/internal/api/enum"
 	"go-fitness/internal/api/types"
 	"log/slog"
 )
 
 type PortalService struct {
-	log          *slog.Logger
-	videoService *video.VideoService
-	portalRepo   PortalRepository
+	log         *slog.Logger
+	attachments VideoAttacher
+	portalRepo  PortalRepository
 }
 
 type PortalRepository interface {
-	Store(context.Context, types.Portal) error
+	Store(context.Context, types.Portal) (int64, error)
+	GetList(context.Context, filter.Filter) ([]types.Portal, int64, error)
 }
 
 func NewPortalService(
 	log *slog.Logger,
-	videoService *video.VideoService,
+	attachments VideoAttacher,
 	portalRepo PortalRepository,
 ) *PortalService {
 	return &PortalService{
-		log:          log,
-		videoService: videoService,
-		portalRepo:   portalRepo,
+		log:         log,
+		attachments: attachments,
+		portalRepo:  portalRepo,
 	}
 }
 
-func (s *PortalService) Upload(ctx context.Context, videoData *data.VideoData) error {
-	const op = "PortalService.Update"
+// Upload stores a new portal video under the given name
+func (s *PortalService) Upload(ctx context.Context, name string, videoData *data.VideoData) error {
+	const op = "PortalService.Upload"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
+		sl.String("name", name),
 	)
 
-	videoID, err := s.videoService.ProcessUpload(ctx, *videoData)
-	if err != nil {
-		log.Error("failed to process upload", sl.Err(err))
-		return err
-	}
+	_, err := s.attachments.Create(ctx, enum.AttachmentOwnerPortal, videoData, func(ctx context.Context, videoID int64) (int64, error) {
+		portalID, err := s.portalRepo.Store(ctx, types.Portal{
+			VideoID: videoID,
+			Name:    name,
+		})
+		if err != nil {
+			log.Error("failed to store portal video", sl.Err(err))
+			return 0, apperr.Internal("failed_to_store")
+		}
 
-	portal := types.Portal{
-		VideoID: videoID,
-		Name:    "Cine sunt",
-	}
+		return portalID, nil
+	})
+
+	return err
+}
+
+func (s *PortalService) List(ctx context.Context) ([]types.Portal, int64, error) {
+	const op string = "PortalService.List"
+
+	f := filter.FromContext(ctx)
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Any("filter", f),
+	)
+
+	portals, total, err := s.portalRepo.GetList(ctx, f)
+	if err != nil {
+		if errors.Is(err, query.ErrUnknownFilter) || errors.Is(err, query.ErrUnknownSort) {
+			log.Warn("invalid filter", sl.Err(err))
+			return nil, 0, apperr.BadRequest("invalid_filter")
+		}
 
-	if err := s.portalRepo.Store(ctx, portal); err != nil {
-		log.Error("failed to store portal video", sl.Err(err))
-		return errors.New("failed_to_store")
+		log.Error("failed to get portals", sl.Err(err))
+		return nil, 0, apperr.Internal("failed_to_get_list")
 	}
 
-	return nil
+	return portals, total, nil
 }
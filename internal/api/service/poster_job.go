@@ -0,0 +1,15 @@
+package service
+
+import "context"
+
+type PosterBackfiller interface {
+	CreatePosterFromUploadedTsFiles(context.Context) error
+}
+
+// PosterJob registers the creation of missing posters of processed videos with the scheduler
+func PosterJob(posters PosterBackfiller) Job {
+	return Job{
+		Name: "posters",
+		Run:  posters.CreatePosterFromUploadedTsFiles,
+	}
+}
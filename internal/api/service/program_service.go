@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-fitness/external/apperr"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/db/query"
+	"go-fitness/external/logger/logctx"
+	"go-fitness/external/logger/sl"
+	"go-fitness/internal/api/data"
+	"go-fitness/internal/api/types"
+	"log/slog"
+)
+
+type ProgramService struct {
+	log         *slog.Logger
+	programRepo ProgramRepository
+	workoutRepo WorkoutRepository
+}
+
+type ProgramRepository interface {
+	GetProgramByName(context.Context, string) (types.Program, error)
+	GetGoalByName(context.Context, string) (types.Goal, error)
+	GetLevelByName(context.Context, string) (types.Level, error)
+	GetPeriodByName(context.Context, string) (types.Period, error)
+	CreateProgramMonth(context.Context, int64, int) (int64, error)
+	GetProgramMonth(context.Context, int64, int) (types.ProgramMonth, error)
+	GetList(context.Context, filter.Filter) ([]types.Program, int64, error)
+	GetByID(context.Context, int64) (types.Program, error)
+	Create(context.Context, types.Program) (int64, error)
+	Update(context.Context, types.Program) error
+	CheckIfNameExists(context.Context, string) bool
+	CheckIfReferencesExist(context.Context, types.Program) bool
+	DeleteProgramMonth(context.Context, int64) error
+	CheckIfWorkoutAttached(context.Context, int64, int64) bool
+	AttachWorkout(context.Context, int64, int64) error
+	DetachWorkout(context.Context, int64, int64) error
+	ReorderWorkouts(context.Context, int64, []int64) error
+	GetTree(context.Context, int64) (types.ProgramTree, error)
+}
+
+func NewProgramService(
+	log *slog.Logger,
+	programRepo ProgramRepository,
+	workoutRepo WorkoutRepository,
+) *ProgramService {
+	return &ProgramService{
+		log:         log,
+		programRepo: programRepo,
+		workoutRepo: workoutRepo,
+	}
+}
+
+func (s *ProgramService) List(ctx context.Context) ([]types.Program, int64, error) {
+	const op string = "ProgramService.List"
+
+	f := filter.FromContext(ctx)
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Any("filter", f),
+	)
+
+	programs, total, err := s.programRepo.GetList(ctx, f)
+	if err != nil {
+		if errors.Is(err, query.ErrUnknownFilter) || errors.Is(err, query.ErrUnknownSort) {
+			log.Warn("invalid filter", sl.Err(err))
+			return nil, 0, apperr.BadRequest("invalid_filter")
+		}
+
+		log.Error("failed to get programs", sl.Err(err))
+		return nil, 0, apperr.Internal("failed_to_get_list")
+	}
+
+	return programs, total, nil
+}
+
+func (s *ProgramService) Store(ctx context.Context, data data.ProgramData) (int64, error) {
+	const op string = "ProgramService.Store"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.String("name", data.ProgramName),
+	)
+
+	program := types.Program{
+		Name:     data.ProgramName,
+		GoalID:   data.GoalID,
+		LevelID:  data.LevelID,
+		PeriodID: data.PeriodID,
+	}
+
+	if s.programRepo.CheckIfNameExists(ctx, program.Name) {
+		log.Warn("program already exists")
+		return 0, apperr.Conflict("program_already_exists")
+	}
+
+	if !s.programRepo.CheckIfReferencesExist(ctx, program) {
+		log.Warn("goal, level or period does not exist")
+		return 0, apperr.BadRequest("invalid_program_references")
+	}
+
+	programID, err := s.programRepo.Create(ctx, program)
+	if err != nil {
+		log.Error("failed to create program", sl.Err(err))
+		return 0, apperr.Internal("failed_to_store_program")
+	}
+
+	return programID, nil
+}
+
+func (s *ProgramService) Update(ctx context.Context, data data.ProgramData) error {
+	const op string = "ProgramService.Update"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("program_id", data.ProgramID),
+	)
+
+	program, err := s.getProgram(ctx, data.ProgramID)
+	if err != nil {
+		return err
+	}
+
+	if program.Name != data.ProgramName && s.programRepo.CheckIfNameExists(ctx, data.ProgramName) {
+		log.Warn("program name already taken", sl.String("name", data.ProgramName))
+		return apperr.Conflict("program_already_exists")
+	}
+
+	program.Name = data.ProgramName
+	program.GoalID = data.GoalID
+	program.LevelID = data.LevelID
+	program.PeriodID = data.PeriodID
+
+	if !s.programRepo.CheckIfReferencesExist(ctx, program) {
+		log.Warn("goal, level or period does not exist")
+		return apperr.BadRequest("invalid_program_references")
+	}
+
+	if err = s.programRepo.Update(ctx, program); err != nil {
+		log.Error("failed to update program", sl.Err(err))
+		return apperr.Internal("failed_to_update_program")
+	}
+
+	return nil
+}
+
+// GetTree returns the whole program with months and workouts in one structure
+func (s *ProgramService) GetTree(ctx context.Context, programID int64) (types.ProgramTree, error) {
+	const op string = "ProgramService.GetTree"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("program_id", programID),
+	)
+
+	tree, err := s.programRepo.GetTree(ctx, programID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn("program not found")
+			return tree, apperr.NotFound("program_not_found")
+		}
+
+		log.Error("failed to get program tree", sl.Err(err))
+		return tree, apperr.Internal("failed_to_get_program")
+	}
+
+	return tree, nil
+}
+
+func (s *ProgramService) AddMonth(ctx context.Context, programID int64, month int) error {
+	const op string = "ProgramService.AddMonth"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("program_id", programID),
+		sl.Int("month", month),
+	)
+
+	if _, err := s.getProgram(ctx, programID); err != nil {
+		return err
+	}
+
+	if _, err := s.programRepo.GetProgramMonth(ctx, programID, month); err == nil {
+		log.Warn("program month already exists")
+		return apperr.Conflict("program_month_already_exists")
+	}
+
+	if _, err := s.programRepo.CreateProgramMonth(ctx, programID, month); err != nil {
+		log.Error("failed to create program month", sl.Err(err))
+		return apperr.Internal("failed_to_store_program_month")
+	}
+
+	return nil
+}
+
+func (s *ProgramService) DeleteMonth(ctx context.Context, programID int64, month int) error {
+	const op string = "ProgramService.DeleteMonth"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("program_id", programID),
+		sl.Int("month", month),
+	)
+
+	programMonth, err := s.getProgramMonth(ctx, programID, month)
+	if err != nil {
+		return err
+	}
+
+	if err = s.programRepo.DeleteProgramMonth(ctx, programMonth.ID); err != nil {
+		log.Error("failed to delete program month", sl.Err(err))
+		return apperr.Internal("failed_to_delete")
+	}
+
+	return nil
+}
+
+func (s *ProgramService) AttachWorkout(ctx context.Context, programID int64, month int, workoutID int64) error {
+	const op string = "ProgramService.AttachWorkout"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("program_id", programID),
+		sl.Int("month", month),
+		sl.Int64("workout_id", workoutID),
+	)
+
+	programMonth, err := s.getProgramMonth(ctx, programID, month)
+	if err != nil {
+		return err
+	}
+
+	if _, err = s.workoutRepo.GetByID(ctx, workoutID); err != nil {
+		log.Warn("workout not found", sl.Err(err))
+		return apperr.NotFound("workout_not_found")
+	}
+
+	if s.programRepo.CheckIfWorkoutAttached(ctx, programMonth.ID, workoutID) {
+		log.Warn("workout already attached")
+		return apperr.Conflict("workout_already_attached")
+	}
+
+	if err = s.programRepo.AttachWorkout(ctx, programMonth.ID, workoutID); err != nil {
+		log.Error("failed to attach workout", sl.Err(err))
+		return apperr.Internal("failed_to_add_workout_to_program_month")
+	}
+
+	return nil
+}
+
+func (s *ProgramService) DetachWorkout(ctx context.Context, programID int64, month int, workoutID int64) error {
+	const op string = "ProgramService.DetachWorkout"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("program_id", programID),
+		sl.Int("month", month),
+		sl.Int64("workout_id", workoutID),
+	)
+
+	programMonth, err := s.getProgramMonth(ctx, programID, month)
+	if err != nil {
+		return err
+	}
+
+	if err = s.programRepo.DetachWorkout(ctx, programMonth.ID, workoutID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn("workout is not attached")
+			return apperr.Conflict("workout_not_attached")
+		}
+
+		log.Error("failed to detach workout", sl.Err(err))
+		return apperr.Internal("failed_to_delete")
+	}
+
+	return nil
+}
+
+func (s *ProgramService) ReorderWorkouts(ctx context.Context, programID int64, month int, workoutIDs []int64) error {
+	const op string = "ProgramService.ReorderWorkouts"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("program_id", programID),
+		sl.Int("month", month),
+	)
+
+	programMonth, err := s.getProgramMonth(ctx, programID, month)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[int64]bool, len(workoutIDs))
+	for _, id := range workoutIDs {
+		if seen[id] {
+			log.Warn("duplicate workout id", sl.Int64("workout_id", id))
+			return apperr.BadRequest("invalid_request")
+		}
+		seen[id] = true
+	}
+
+	if err = s.programRepo.ReorderWorkouts(ctx, programMonth.ID, workoutIDs); err != nil {
+		log.Error("failed to reorder workouts", sl.Err(err))
+		return apperr.Internal("failed_to_reorder")
+	}
+
+	return nil
+}
+
+func (s *ProgramService) getProgram(ctx context.Context, programID int64) (types.Program, error) {
+	program, err := s.programRepo.GetByID(ctx, programID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return program, apperr.NotFound("program_not_found")
+		}
+
+		s.log.Error("failed to get program", sl.Int64("program_id", programID), sl.Err(err))
+		return program, apperr.Internal("failed_to_get_program")
+	}
+
+	return program, nil
+}
+
+func (s *ProgramService) getProgramMonth(ctx context.Context, programID int64, month int) (types.ProgramMonth, error) {
+	programMonth, err := s.programRepo.GetProgramMonth(ctx, programID, month)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return programMonth, apperr.NotFound("program_month_not_found")
+		}
+
+		s.log.Error("failed to get program month", sl.Int64("program_id", programID), sl.Int("month", month), sl.Err(err))
+		return programMonth, apperr.Internal("failed_to_get_program")
+	}
+
+	return programMonth, nil
+}
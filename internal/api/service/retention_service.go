@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"go-fitness/external/config"
+	"go-fitness/external/logger/logctx"
+	"go-fitness/external/logger/sl"
+	"go-fitness/internal/api/enum"
+	"log/slog"
+	"time"
+)
+
+type RetentionService struct {
+	log         *slog.Logger
+	cfg         *config.Config
+	workoutRepo WorkoutRepository
+	tabRepo     TabRepository
+	goalRepo    GoalRepository
+	attachRepo  AttachmentRepository
+	videoPurger VideoPurger
+}
+
+type VideoPurger interface {
+	PurgeVideo(context.Context, int64) (bool, error)
+	PurgeTrashed(context.Context, time.Time) (int, error)
+}
+
+func NewRetentionService(
+	log *slog.Logger,
+	cfg *config.Config,
+	workoutRepo WorkoutRepository,
+	tabRepo TabRepository,
+	goalRepo GoalRepository,
+	attachRepo AttachmentRepository,
+	videoPurger VideoPurger,
+) *RetentionService {
+	return &RetentionService{
+		log:         log,
+		cfg:         cfg,
+		workoutRepo: workoutRepo,
+		tabRepo:     tabRepo,
+		goalRepo:    goalRepo,
+		attachRepo:  attachRepo,
+		videoPurger: videoPurger,
+	}
+}
+
+// PurgeExpired hard deletes content that has been soft deleted for longer than the grace period
+func (s *RetentionService) PurgeExpired(ctx context.Context) {
+	const op string = "RetentionService.PurgeExpired"
+
+	before := time.Now().Add(-s.cfg.Retention.GracePeriod)
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.String("before", before.Format(time.RFC3339)),
+	)
+
+	log.Info("purging expired content")
+
+	var videoIDs []int64
+
+	workouts, err := s.workoutRepo.GetTrashedBefore(ctx, before)
+	if err != nil {
+		log.Error("failed to get trashed workouts", sl.Err(err))
+	}
+
+	for _, workout := range workouts {
+		if err = s.workoutRepo.ForceDelete(ctx, workout.ID); err != nil {
+			log.Error("failed to delete workout", sl.Int64("workout_id", workout.ID), sl.Err(err))
+			continue
+		}
+
+		videoIDs = append(videoIDs, workout.VideoID)
+		videoIDs = append(videoIDs, s.detachAll(ctx, log, enum.AttachmentOwnerWorkout, workout.ID)...)
+	}
+
+	tabs, err := s.tabRepo.GetTrashedBefore(ctx, before)
+	if err != nil {
+		log.Error("failed to get trashed tabs", sl.Err(err))
+	}
+
+	for _, tab := range tabs {
+		if err = s.tabRepo.ForceDelete(ctx, tab.ID); err != nil {
+			log.Error("failed to delete tab", sl.Int("tab_id", tab.ID), sl.Err(err))
+			continue
+		}
+
+		if tab.VideoID != nil {
+			videoIDs = append(videoIDs, *tab.VideoID)
+		}
+		videoIDs = append(videoIDs, s.detachAll(ctx, log, enum.AttachmentOwnerTab, int64(tab.ID))...)
+	}
+
+	goals, err := s.goalRepo.GetTrashedBefore(ctx, before)
+	if err != nil {
+		log.Error("failed to get trashed goals", sl.Err(err))
+	}
+
+	for _, goal := range goals {
+		if err = s.goalRepo.ForceDelete(ctx, goal.ID); err != nil {
+			log.Error("failed to delete goal", sl.Int("goal_id", goal.ID), sl.Err(err))
+			continue
+		}
+
+		if goal.VideoID != nil {
+			videoIDs = append(videoIDs, *goal.VideoID)
+		}
+		videoIDs = append(videoIDs, s.detachAll(ctx, log, enum.AttachmentOwnerGoal, int64(goal.ID))...)
+	}
+
+	for _, videoID := range videoIDs {
+		if videoID == 0 {
+			continue
+		}
+
+		if _, err = s.videoPurger.PurgeVideo(ctx, videoID); err != nil {
+			log.Error("failed to purge video", sl.Int64("video_id", videoID), sl.Err(err))
+		}
+	}
+
+	purged, err := s.videoPurger.PurgeTrashed(ctx, before)
+	if err != nil {
+		log.Error("failed to purge trashed videos", sl.Err(err))
+	}
+
+	log.Info("expired content purged",
+		sl.Int("workouts", len(workouts)),
+		sl.Int("tabs", len(tabs)),
+		sl.Int("goals", len(goals)),
+		sl.Int("videos", purged),
+	)
+}
+
+// detachAll drops the attachments of a purged owner and returns their videos so they can be purged too
+func (s *RetentionService) detachAll(ctx context.Context, log *slog.Logger, owner enum.AttachmentOwner, ownerID int64) []int64 {
+	videoIDs, err := s.attachRepo.DeleteByOwner(ctx, owner, ownerID)
+	if err != nil {
+		log.Error("failed to delete attachments",
+			sl.String("owner_type", string(owner)),
+			sl.Int64("owner_id", ownerID),
+			sl.Err(err),
+		)
+	}
+
+	return videoIDs
+}
+
+// RetentionJob registers the purge of expired content with the scheduler
+func RetentionJob(retention *RetentionService) Job {
+	return Job{
+		Name: "retention",
+		Run: func(ctx context.Context) error {
+			retention.PurgeExpired(ctx)
+			return nil
+		},
+	}
+}
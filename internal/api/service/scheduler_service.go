@@ -0,0 +1,359 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-fitness/external/apperr"
+	"go-fitness/external/config"
+	"go-fitness/external/cron"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/db/query"
+	"go-fitness/external/logger/logctx"
+	"go-fitness/external/logger/sl"
+	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/types"
+	"go.uber.org/fx"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Job is a maintenance task the scheduler runs by its cron expression or on demand.
+// Jobs are registered through the scheduler_jobs fx group
+type Job struct {
+	Name string
+	Run  func(context.Context) error
+}
+
+type SchedulerService struct {
+	log     *slog.Logger
+	jobRepo JobRepository
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	jobs map[string]*scheduledJob
+}
+
+type scheduledJob struct {
+	Job
+	expr     string
+	schedule *cron.Schedule
+	next     time.Time
+	running  bool
+}
+
+// JobScheduler is the part of the SchedulerService the background loop needs
+type JobScheduler interface {
+	Tick(time.Time)
+	Stop(context.Context) error
+}
+
+type JobRepository interface {
+	WithLock(context.Context, string, func(context.Context) error) (bool, error)
+	StartRun(context.Context, string, enum.JobTrigger) (int64, error)
+	FinishRun(context.Context, int64, enum.JobRunStatus, *string) error
+	GetLastRuns(context.Context) (map[string]types.JobRun, error)
+	GetRuns(context.Context, filter.Filter) ([]types.JobRun, int64, error)
+}
+
+func NewSchedulerService(
+	log *slog.Logger,
+	cfg *config.Config,
+	jobRepo JobRepository,
+	jobs []Job,
+) (*SchedulerService, error) {
+	const op string = "SchedulerService.New"
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s := &SchedulerService{
+		log:     log,
+		jobRepo: jobRepo,
+		ctx:     ctx,
+		cancel:  cancel,
+		jobs:    make(map[string]*scheduledJob, len(jobs)),
+	}
+
+	for _, job := range jobs {
+		scheduled := &scheduledJob{Job: job}
+
+		if expr, ok := cfg.Scheduler.Jobs[job.Name]; ok && expr != "" {
+			schedule, err := cron.Parse(expr)
+			if err != nil {
+				cancel()
+				return nil, fmt.Errorf("%s: job %s: %w", op, job.Name, err)
+			}
+
+			scheduled.expr = expr
+			scheduled.schedule = schedule
+			scheduled.next = schedule.Next(time.Now())
+		}
+
+		s.jobs[job.Name] = scheduled
+	}
+
+	for name := range cfg.Scheduler.Jobs {
+		if _, ok := s.jobs[name]; !ok {
+			cancel()
+			return nil, fmt.Errorf("%s: unknown job %s", op, name)
+		}
+	}
+
+	return s, nil
+}
+
+// Tick starts every scheduled job whose time has come
+func (s *SchedulerService) Tick(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, job := range s.jobs {
+		if job.schedule == nil || job.next.IsZero() || job.next.After(now) {
+			continue
+		}
+
+		job.next = job.schedule.Next(now)
+
+		if job.running {
+			s.log.Warn("job is still running, skipping", sl.String("job", job.Name))
+			continue
+		}
+
+		s.start(job, enum.JobTriggerSchedule)
+	}
+}
+
+// Trigger starts the job right away in the background
+func (s *SchedulerService) Trigger(ctx context.Context, name string) error {
+	const op string = "SchedulerService.Trigger"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.String("job", name),
+	)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[name]
+	if !ok {
+		log.Warn("job not found")
+		return apperr.NotFound("job_not_found")
+	}
+
+	if job.running {
+		log.Warn("job is already running")
+		return apperr.Conflict("job_already_running")
+	}
+
+	s.start(job, enum.JobTriggerManual)
+
+	return nil
+}
+
+// start runs the job in its own goroutine, the caller must hold s.mu
+func (s *SchedulerService) start(job *scheduledJob, trigger enum.JobTrigger) {
+	job.running = true
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			s.mu.Lock()
+			job.running = false
+			s.mu.Unlock()
+		}()
+
+		s.run(s.ctx, job.Job, trigger)
+	}()
+}
+
+// run executes the job under its database lock and records the run
+func (s *SchedulerService) run(ctx context.Context, job Job, trigger enum.JobTrigger) {
+	const op string = "SchedulerService.run"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.String("job", job.Name),
+		sl.String("trigger", string(trigger)),
+	)
+
+	locked, err := s.jobRepo.WithLock(ctx, job.Name, func(ctx context.Context) error {
+		log.Info("job started")
+
+		runID, err := s.jobRepo.StartRun(ctx, job.Name, trigger)
+		if err != nil {
+			return err
+		}
+
+		status := enum.JobRunStatusSucceeded
+
+		var runErr *string
+
+		started := time.Now()
+
+		if err = s.safeRun(ctx, job); err != nil {
+			log.Error("job failed", sl.Err(err))
+
+			status = enum.JobRunStatusFailed
+			msg := err.Error()
+			runErr = &msg
+		}
+
+		log.Info("job finished", sl.String("status", string(status)), sl.String("duration", time.Since(started).String()))
+
+		return s.jobRepo.FinishRun(context.WithoutCancel(ctx), runID, status, runErr)
+	})
+	if err != nil {
+		log.Error("failed to run job", sl.Err(err))
+		return
+	}
+
+	if !locked {
+		log.Info("job is running on another instance, skipping")
+	}
+}
+
+// safeRun keeps a panicking job from taking the app down with it
+func (s *SchedulerService) safeRun(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	return job.Run(ctx)
+}
+
+// List returns the registered jobs with their schedule and latest run
+func (s *SchedulerService) List(ctx context.Context) ([]types.Job, error) {
+	const op string = "SchedulerService.List"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+	)
+
+	lastRuns, err := s.jobRepo.GetLastRuns(ctx)
+	if err != nil {
+		log.Error("failed to get last job runs", sl.Err(err))
+		return nil, apperr.Internal("failed_to_get_list")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]types.Job, 0, len(s.jobs))
+	for _, scheduled := range s.jobs {
+		job := types.Job{
+			Name:     scheduled.Name,
+			Schedule: scheduled.expr,
+			Running:  scheduled.running,
+		}
+
+		if !scheduled.next.IsZero() {
+			next := scheduled.next
+			job.NextRun = &next
+		}
+
+		if run, ok := lastRuns[scheduled.Name]; ok {
+			job.LastRun = &run
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Name < jobs[j].Name
+	})
+
+	return jobs, nil
+}
+
+// Runs returns a page of the job run history matching the filter stored in the context
+func (s *SchedulerService) Runs(ctx context.Context) ([]types.JobRun, int64, error) {
+	const op string = "SchedulerService.Runs"
+
+	f := filter.FromContext(ctx)
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Any("filter", f),
+	)
+
+	runs, total, err := s.jobRepo.GetRuns(ctx, f)
+	if err != nil {
+		if errors.Is(err, query.ErrUnknownFilter) || errors.Is(err, query.ErrUnknownSort) {
+			log.Warn("invalid filter", sl.Err(err))
+			return nil, 0, apperr.BadRequest("invalid_filter")
+		}
+
+		log.Error("failed to get job runs", sl.Err(err))
+		return nil, 0, apperr.Internal("failed_to_get_list")
+	}
+
+	return runs, total, nil
+}
+
+// Stop cancels the running jobs and waits for them to return
+func (s *SchedulerService) Stop(ctx context.Context) error {
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RunScheduler checks the job schedules every tick for the lifetime of the app
+func RunScheduler(
+	lc fx.Lifecycle,
+	log *slog.Logger,
+	cfg *config.Config,
+	scheduler JobScheduler,
+) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			if !cfg.Scheduler.Enabled {
+				log.Info("Scheduler is disabled, jobs only run manually")
+				return nil
+			}
+
+			log.Info("Starting scheduler", sl.String("tick", cfg.Scheduler.Tick.String()))
+
+			go func() {
+				ticker := time.NewTicker(cfg.Scheduler.Tick)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case now := <-ticker.C:
+						scheduler.Tick(now)
+					}
+				}
+			}()
+
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			return scheduler.Stop(stopCtx)
+		},
+	})
+}
@@ -0,0 +1,199 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"go-fitness/external/apperr"
+	"go-fitness/external/config"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/db"
+	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/migrations"
+	"go-fitness/internal/api/repository"
+	"go-fitness/internal/api/service"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// newTestScheduler returns a scheduler with the given jobs, recording its runs in a migrated in-memory SQLite database
+func newTestScheduler(t *testing.T, jobs ...service.Job) (*service.SchedulerService, *repository.JobRepository) {
+	t.Helper()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	conn, err := db.OpenSqlite(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	database := db.New(conn, db.SQLite)
+
+	migrator, err := migrations.NewMigrator(log, conn, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	jobRepo := repository.NewJobRepository(database)
+
+	scheduler, err := service.NewSchedulerService(log, &config.Config{}, jobRepo, jobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = scheduler.Stop(context.Background()) })
+
+	return scheduler, jobRepo
+}
+
+// waitIdle waits until no job of the scheduler is running
+func waitIdle(t *testing.T, scheduler *service.SchedulerService) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for time.Now().Before(deadline) {
+		jobs, err := scheduler.List(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		running := false
+		for _, job := range jobs {
+			running = running || job.Running
+		}
+
+		if !running {
+			return
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Fatal("jobs still running")
+}
+
+func TestSchedulerServiceRecordsRunHistory(t *testing.T) {
+	scheduler, jobRepo := newTestScheduler(t,
+		service.Job{Name: "ok", Run: func(context.Context) error { return nil }},
+		service.Job{Name: "fails", Run: func(context.Context) error { return errors.New("disk full") }},
+		service.Job{Name: "panics", Run: func(context.Context) error { panic("boom") }},
+	)
+	ctx := context.Background()
+
+	for _, name := range []string{"ok", "fails", "panics"} {
+		if err := scheduler.Trigger(ctx, name); err != nil {
+			t.Fatal(err)
+		}
+		waitIdle(t, scheduler)
+	}
+
+	runs, total, err := jobRepo.GetRuns(ctx, filter.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 3 {
+		t.Fatalf("got %d runs, want 3", total)
+	}
+
+	byJob := make(map[string]enum.JobRunStatus)
+	for _, run := range runs {
+		if run.Trigger != enum.JobTriggerManual || run.FinishedAt == nil {
+			t.Fatalf("unexpected run %+v", run)
+		}
+		byJob[run.Job] = run.Status
+
+		if run.Job == "fails" && (run.Error == nil || *run.Error != "disk full") {
+			t.Fatalf("got error %v, want disk full", run.Error)
+		}
+	}
+
+	want := map[string]enum.JobRunStatus{
+		"ok":     enum.JobRunStatusSucceeded,
+		"fails":  enum.JobRunStatusFailed,
+		"panics": enum.JobRunStatusFailed,
+	}
+	for job, status := range want {
+		if byJob[job] != status {
+			t.Fatalf("job %s got %q, want %q", job, byJob[job], status)
+		}
+	}
+
+	jobs, err := scheduler.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, job := range jobs {
+		if job.LastRun == nil || job.LastRun.Status != want[job.Name] {
+			t.Fatalf("job %s has last run %+v", job.Name, job.LastRun)
+		}
+	}
+}
+
+func TestSchedulerServiceSkipsJobLockedByAnotherInstance(t *testing.T) {
+	ran := make(chan struct{}, 1)
+
+	scheduler, jobRepo := newTestScheduler(t,
+		service.Job{Name: "gc", Run: func(context.Context) error { ran <- struct{}{}; return nil }},
+	)
+	ctx := context.Background()
+
+	// another instance holds the lock of the job while this one triggers it
+	locked, err := jobRepo.WithLock(ctx, "gc", func(context.Context) error {
+		if err := scheduler.Trigger(ctx, "gc"); err != nil {
+			return err
+		}
+		waitIdle(t, scheduler)
+
+		return nil
+	})
+	if err != nil || !locked {
+		t.Fatalf("got locked %v, %v", locked, err)
+	}
+
+	select {
+	case <-ran:
+		t.Fatal("job ran while another instance held its lock")
+	default:
+	}
+
+	if _, total, err := jobRepo.GetRuns(ctx, filter.Filter{}); err != nil || total != 0 {
+		t.Fatalf("got %d runs, %v, want none", total, err)
+	}
+}
+
+func TestSchedulerServiceRejectsTriggerWhileRunning(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	scheduler, _ := newTestScheduler(t,
+		service.Job{Name: "gc", Run: func(context.Context) error {
+			close(started)
+			<-release
+			return nil
+		}},
+	)
+	ctx := context.Background()
+
+	if err := scheduler.Trigger(ctx, "gc"); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	var appErr *apperr.Error
+	if err := scheduler.Trigger(ctx, "gc"); !errors.As(err, &appErr) || appErr.Status != http.StatusConflict {
+		t.Fatalf("got %v, want a conflict", err)
+	}
+
+	close(release)
+	waitIdle(t, scheduler)
+
+	if err := scheduler.Trigger(ctx, "missing"); !errors.As(err, &appErr) || appErr.Status != http.StatusNotFound {
+		t.Fatalf("got %v, want not found", err)
+	}
+}
@@ -2,43 +2,54 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"go-fitness/external/apperr"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/db/query"
+	"go-fitness/external/logger/logctx"
 	"go-fitness/external/logger/sl"
 	"go-fitness/internal/api/data"
-	"go-fitness/internal/api/service/video"
+	"go-fitness/internal/api/enum"
 	"go-fitness/internal/api/types"
 	"log/slog"
+	"time"
 )
 
 type TabService struct {
-	log          *slog.Logger
-	videoService *video.VideoService
-	tabRepo      TabRepository
+	log         *slog.Logger
+	attachments VideoAttacher
+	tabRepo     TabRepository
 }
 
 type TabRepository interface {
-	Store(context.Context, types.Tab) error
+	Store(context.Context, types.Tab) (int64, error)
 	CheckIfNameExists(context.Context, string) bool
 	GetByID(context.Context, int) (types.Tab, error)
 	Update(context.Context, types.Tab) error
+	SoftDelete(context.Context, int) error
+	Restore(context.Context, int) error
+	GetTrashedBefore(context.Context, time.Time) ([]types.Tab, error)
+	ForceDelete(context.Context, int) error
+	GetList(context.Context, filter.Filter) ([]types.Tab, int64, error)
 }
 
 func NewTabService(
 	log *slog.Logger,
-	videoService *video.VideoService,
+	attachments VideoAttacher,
 	tabRepo TabRepository,
 ) *TabService {
 	return &TabService{
-		log:          log,
-		videoService: videoService,
-		tabRepo:      tabRepo,
+		log:         log,
+		attachments: attachments,
+		tabRepo:     tabRepo,
 	}
 }
 
 func (s *TabService) Store(ctx context.Context, data data.TabData) error {
 	const op = "TabService.Store"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 	)
 
@@ -47,59 +58,114 @@ func (s *TabService) Store(ctx context.Context, data data.TabData) error {
 	ok := s.tabRepo.CheckIfNameExists(ctx, data.Name)
 	if ok {
 		log.Error("tab already exists")
-		return errors.New("tab_already_exists")
+		return apperr.Conflict("tab_already_exists")
 	}
 
-	videoID, err := s.videoService.ProcessUpload(ctx, *data.VideoData)
-	if err != nil {
-		log.Error("failed to process upload", sl.Err(err))
-		return err
-	}
+	_, err := s.attachments.Create(ctx, enum.AttachmentOwnerTab, data.VideoData, func(ctx context.Context, videoID int64) (int64, error) {
+		tabID, err := s.tabRepo.Store(ctx, types.Tab{
+			Name:        data.Name,
+			Description: data.Description,
+			VideoID:     &videoID,
+		})
+		if err != nil {
+			log.Error("failed to store tab", sl.Err(err))
+			return 0, apperr.Internal("failed_to_store_tab")
+		}
 
-	if err := s.tabRepo.Store(ctx, types.Tab{
-		Name:        data.Name,
-		Description: data.Description,
-		VideoID:     &videoID,
-	}); err != nil {
-		log.Error("failed to store tab", sl.Err(err))
-		return errors.New("failed_to_store_tab")
-	}
+		return tabID, nil
+	})
 
-	return nil
+	return err
 }
 
-func (s *TabService) UpdateVideo(ctx context.Context, tabID int, videoData *data.VideoData) error {
-	const op = "TabService.Update"
+// UpdateVideo attaches the video to the tab in the role, replacing the previous one
+func (s *TabService) UpdateVideo(ctx context.Context, tabID int, role enum.AttachmentRole, videoData *data.VideoData) error {
+	const op = "TabService.UpdateVideo"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
-		sl.Int("tabID", tabID),
+		sl.Int("tab_id", tabID),
 	)
 
 	log.Info("updating")
 
-	tab, err := s.tabRepo.GetByID(ctx, tabID)
-	if err != nil {
+	if _, err := s.tabRepo.GetByID(ctx, tabID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn("tab not found")
+			return apperr.NotFound("tab_not_found")
+		}
+
 		log.Error("failed to get tab", sl.Err(err))
-		return errors.New("failed_to_get_tab")
+		return apperr.Internal("failed_to_get_tab")
 	}
 
-	var videoID int64
+	_, err := s.attachments.Attach(ctx, enum.AttachmentOwnerTab, int64(tabID), role, videoData)
 
-	if videoData != nil {
-		videoID, err = s.videoService.ProcessUpload(ctx, *videoData)
-		if err != nil {
-			log.Error("failed to process upload", sl.Err(err))
-			return err
+	return err
+}
+
+func (s *TabService) SoftDelete(ctx context.Context, id int) error {
+	const op string = "TabService.SoftDelete"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int("tab_id", id),
+	)
+
+	if err := s.tabRepo.SoftDelete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn("tab not found or already deleted")
+			return apperr.NotFound("tab_not_found")
 		}
+
+		log.Error("failed to soft delete tab", sl.Err(err))
+		return apperr.Internal("failed_to_delete")
 	}
 
-	tab.VideoID = &videoID
+	return nil
+}
 
-	if err := s.tabRepo.Update(ctx, tab); err != nil {
-		log.Error("failed to update tab", sl.Err(err))
-		return errors.New("failed_to_update_tab")
+func (s *TabService) Restore(ctx context.Context, id int) error {
+	const op string = "TabService.Restore"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int("tab_id", id),
+	)
+
+	if err := s.tabRepo.Restore(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn("tab not found or not deleted")
+			return apperr.NotFound("tab_not_found")
+		}
+
+		log.Error("failed to restore tab", sl.Err(err))
+		return apperr.Internal("failed_to_restore")
 	}
 
 	return nil
 }
+
+func (s *TabService) List(ctx context.Context) ([]types.Tab, int64, error) {
+	const op string = "TabService.List"
+
+	f := filter.FromContext(ctx)
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Any("filter", f),
+	)
+
+	tabs, total, err := s.tabRepo.GetList(ctx, f)
+	if err != nil {
+		if errors.Is(err, query.ErrUnknownFilter) || errors.Is(err, query.ErrUnknownSort) {
+			log.Warn("invalid filter", sl.Err(err))
+			return nil, 0, apperr.BadRequest("invalid_filter")
+		}
+
+		log.Error("failed to get tabs", sl.Err(err))
+		return nil, 0, apperr.Internal("failed_to_get_list")
+	}
+
+	return tabs, total, nil
+}
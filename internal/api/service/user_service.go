@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"go-fitness/external/logger/logctx"
 	"go-fitness/external/logger/sl"
 	"go-fitness/internal/api/types"
 	"log/slog"
@@ -15,6 +16,7 @@ type UserService struct {
 type UserRepository interface {
 	GetUserByUUID(ctx context.Context, uuid string) (types.User, error)
 	GetRoleByUserID(ctx context.Context, userID int64) (types.Role, error)
+	GetLocale(ctx context.Context, userID int64) (string, error)
 }
 
 func NewUserService(
@@ -30,7 +32,7 @@ func NewUserService(
 func (s *UserService) GetUserByUUID(ctx context.Context, uuid string) (types.User, error) {
 	const op = "UserService.GetUserByUUID"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 		sl.String("uuid", uuid),
 	)
@@ -47,7 +49,7 @@ func (s *UserService) GetUserByUUID(ctx context.Context, uuid string) (types.Use
 func (s *UserService) GetRoleByUserID(ctx context.Context, userID int64) (types.Role, error) {
 	const op = "UserService.GetRoleByUserID"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 		sl.Int64("user_id", userID),
 	)
@@ -60,3 +62,22 @@ func (s *UserService) GetRoleByUserID(ctx context.Context, userID int64) (types.
 
 	return role, nil
 }
+
+// GetLocale returns the language of the user profile, empty when there is none or it can not be read,
+// a missing language never fails the request
+func (s *UserService) GetLocale(ctx context.Context, userID int64) string {
+	const op = "UserService.GetLocale"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("user_id", userID),
+	)
+
+	locale, err := s.userRepo.GetLocale(ctx, userID)
+	if err != nil {
+		log.Debug("no profile language", sl.Err(err))
+		return ""
+	}
+
+	return locale
+}
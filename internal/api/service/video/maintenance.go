@@ -0,0 +1,221 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go-fitness/external/apperr"
+	"go-fitness/external/logger/logctx"
+	"go-fitness/external/logger/sl"
+	"go-fitness/external/tracing"
+	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/event"
+	"go-fitness/internal/api/types"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Reprocess transcodes the video again from the file it was uploaded with,
+// or from its best rendition when the upload is gone
+func (s *VideoService) Reprocess(ctx context.Context, uuid string) error {
+	const op string = "Video.Reprocess"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.String("uuid", uuid),
+	)
+
+	video, err := s.videoRepo.GetByUUIDWithTrashed(ctx, uuid)
+	if err != nil {
+		log.Error("failed to get video by uuid", sl.Err(err))
+		return apperr.NotFound("video_not_found")
+	}
+
+	return s.reprocess(ctx, video)
+}
+
+// ReprocessByStatus reprocesses every video in the status and returns how many were queued
+func (s *VideoService) ReprocessByStatus(ctx context.Context, status enum.VideoStatus) (int, error) {
+	const op string = "Video.ReprocessByStatus"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.String("status", status.String()),
+	)
+
+	if status == enum.VideoStatusProcessing {
+		log.Warn("videos in processing are still being transcoded")
+		return 0, apperr.Conflict("video_is_processing")
+	}
+
+	videos, err := s.videoRepo.GetByStatus(ctx, status)
+	if err != nil {
+		log.Error("failed to get videos", sl.Err(err))
+		return 0, apperr.Internal("failed_to_get_list")
+	}
+
+	queued := 0
+	for i := range videos {
+		if err = s.reprocess(ctx, &videos[i]); err != nil {
+			log.Warn("failed to reprocess video", sl.String("uuid", videos[i].UUID), sl.Err(err))
+			continue
+		}
+
+		queued++
+	}
+
+	return queued, nil
+}
+
+func (s *VideoService) reprocess(ctx context.Context, video *types.Video) error {
+	const op string = "Video.reprocess"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.String("uuid", video.UUID),
+	)
+
+	if video.Status == enum.VideoStatusProcessing {
+		log.Warn("video is still being transcoded")
+		return apperr.Conflict("video_is_processing")
+	}
+
+	folder := filepath.Join(s.cfg.HTTPServer.StoragePath, s.cfg.Video.VideoPath, video.HashName)
+
+	source, err := s.findSource(ctx, folder)
+	if err != nil {
+		log.Error("failed to find video source", sl.Err(err))
+		return apperr.NotFound("video_source_not_found")
+	}
+
+	video.Status = enum.VideoStatusProcessing
+
+	processing, err := videoEvent(event.VideoProcessing, video, false)
+	if err != nil {
+		log.Error("failed to build video event", sl.Err(err))
+		return apperr.Internal("failed_to_reprocess_video")
+	}
+
+	if err = s.videoRepo.UpdateStatus(ctx, video.ID, enum.VideoStatusProcessing, processing); err != nil {
+		log.Error("failed to update video status", sl.Err(err))
+		return apperr.Internal("failed_to_reprocess_video")
+	}
+
+	log.Info("reprocessing video", sl.String("source", source))
+
+	s.worker.AddTask(TranscodeTask{
+		UploadPath: folder,
+		VideoID:    video.ID,
+		VideoUUID:  video.UUID,
+		DstPath:    source,
+		ChunkHash:  video.HashName,
+		Link:       trace.SpanContextFromContext(ctx),
+	})
+
+	return nil
+}
+
+// findSource returns the uploaded file kept in the folder, or remuxes the highest rendition into one
+func (s *VideoService) findSource(ctx context.Context, folder string) (string, error) {
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		return "", err
+	}
+
+	var renditions []int
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+
+		switch filepath.Ext(name) {
+		case ".ts", ".jpg":
+		case ".m3u8":
+			if height, err := strconv.Atoi(strings.TrimSuffix(name, ".m3u8")); err == nil {
+				renditions = append(renditions, height)
+			}
+		default:
+			return filepath.Join(folder, name), nil
+		}
+	}
+
+	if len(renditions) == 0 {
+		return "", fmt.Errorf("no source or rendition in %s", folder)
+	}
+
+	sort.Ints(renditions)
+
+	playlist := filepath.Join(folder, fmt.Sprintf("%d.m3u8", renditions[len(renditions)-1]))
+	source := filepath.Join(folder, "source.mp4")
+
+	var out bytes.Buffer
+	cmd := exec.Command("ffmpeg", "-y", "-i", playlist, "-c", "copy", source)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := tracing.Run(ctx, cmd); err != nil {
+		return "", fmt.Errorf("remux %s: %w: %s", playlist, err, out.String())
+	}
+
+	return source, nil
+}
+
+// Verify checks that every processed video has its folder, playlists, first segments and poster on disk
+func (s *VideoService) Verify(ctx context.Context) ([]types.VideoIssue, error) {
+	const op string = "Video.Verify"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+	)
+
+	videos, err := s.videoRepo.GetByStatus(ctx, enum.VideoStatusProcessed)
+	if err != nil {
+		log.Error("failed to get videos", sl.Err(err))
+		return nil, apperr.Internal("failed_to_get_list")
+	}
+
+	issues := make([]types.VideoIssue, 0)
+
+	for _, video := range videos {
+		folder := filepath.Join(s.cfg.HTTPServer.StoragePath, s.cfg.Video.VideoPath, video.HashName)
+
+		issue := func(problem string) {
+			issues = append(issues, types.VideoIssue{
+				VideoID:  video.ID,
+				UUID:     video.UUID,
+				HashName: video.HashName,
+				Problem:  problem,
+			})
+		}
+
+		if _, err = os.Stat(folder); err != nil {
+			issue("folder is missing")
+			continue
+		}
+
+		files := []string{"playlist.m3u8"}
+		for _, res := range s.cfg.Video.Resolutions {
+			files = append(files, res+".m3u8", res+"_000.ts")
+		}
+
+		if video.Poster != nil {
+			files = append(files, *video.Poster)
+		}
+
+		for _, file := range files {
+			if _, err = os.Stat(filepath.Join(folder, file)); err != nil {
+				issue(file + " is missing")
+			}
+		}
+	}
+
+	log.Info("videos verified", sl.Int("videos", len(videos)), sl.Int("issues", len(issues)))
+
+	return issues, nil
+}
@@ -6,38 +6,62 @@ import (
 	"errors"
 	"fmt"
 	"go-fitness/external/config"
+	"go-fitness/external/logger/logctx"
 	"go-fitness/external/logger/sl"
+	"go-fitness/external/metrics"
+	"go-fitness/external/tracing"
 	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/event"
+	"go-fitness/internal/api/types"
 	"log/slog"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type UpdateVideoStatus interface {
-	UpdateStatus(context.Context, int64, enum.VideoStatus) error
+	UpdateStatus(context.Context, int64, enum.VideoStatus, ...types.OutboxEvent) error
 }
 
 type TranscodeService struct {
-	log   *slog.Logger
-	cfg   *config.Config
-	video UpdateVideoStatus
+	log     *slog.Logger
+	cfg     *config.Config
+	metrics *metrics.Metrics
+	video   UpdateVideoStatus
 }
 
 func NewTranscodeService(
 	log *slog.Logger,
 	cfg *config.Config,
+	metrics *metrics.Metrics,
 	video UpdateVideoStatus,
 ) *TranscodeService {
 	return &TranscodeService{
-		log:   log,
-		cfg:   cfg,
-		video: video,
+		log:     log,
+		cfg:     cfg,
+		metrics: metrics,
+		video:   video,
 	}
 }
 
+// updateStatus changes the video status and records the matching domain event with it
+func (s *TranscodeService) updateStatus(ctx context.Context, transcode TranscodeTask, status enum.VideoStatus, name string) error {
+	e, err := event.NewOutboxEvent(name, event.VideoPayload{
+		VideoID: transcode.VideoID,
+		UUID:    transcode.VideoUUID,
+		Status:  status.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.video.UpdateStatus(ctx, transcode.VideoID, status, e)
+}
+
 // ProcessTranscode is a method to process video transcoding and chunking
 func (s *TranscodeService) ProcessTranscode(
 	ctx context.Context,
@@ -45,7 +69,7 @@ func (s *TranscodeService) ProcessTranscode(
 ) error {
 	const op = "TranscodeService.processTranscode"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 	)
 
@@ -53,27 +77,28 @@ func (s *TranscodeService) ProcessTranscode(
 
 	defer func() {
 		if !uploadSuccessful {
-			if updateErr := s.video.UpdateStatus(ctx, transcode.VideoID, enum.VideoStatusFailed); updateErr != nil {
+			if updateErr := s.updateStatus(ctx, transcode, enum.VideoStatusFailed, event.VideoFailed); updateErr != nil {
 				log.Error("failed to update video status to failed", sl.Err(updateErr))
 			}
 
-			if rmErr := os.RemoveAll(transcode.UploadPath); rmErr != nil {
-				log.Error("failed to remove folder", sl.Err(rmErr))
+			// the source stays so the video can be reprocessed
+			if rmErr := removeRenditions(transcode.UploadPath); rmErr != nil {
+				log.Error("failed to remove renditions", sl.Err(rmErr))
 			}
 		}
 	}()
 
-	if err := s.transcodeAndChunk(transcode.UploadPath, transcode.DstPath); err != nil {
+	if err := s.transcodeAndChunk(ctx, transcode.UploadPath, transcode.DstPath); err != nil {
 		log.Error("failed to transcode and chunk video", sl.Err(err))
 		return errors.New("failed to transcode and chunk video")
 	}
 
-	if err := s.createMasterM8U3PlayList(transcode.UploadPath, transcode.ChunkHash); err != nil {
+	if err := s.createMasterM8U3PlayList(ctx, transcode.UploadPath, transcode.ChunkHash); err != nil {
 		log.Error("failed to create master m8u3 playlist", sl.Err(err))
 		return errors.New("failed to create master m8u3 playlist")
 	}
 
-	if err := s.video.UpdateStatus(ctx, transcode.VideoID, enum.VideoStatusProcessed); err != nil {
+	if err := s.updateStatus(ctx, transcode, enum.VideoStatusProcessed, event.VideoProcessed); err != nil {
 		log.Error("failed to update video status to processed", sl.Err(err))
 		return errors.New("failed to update video status to processed")
 	}
@@ -83,11 +108,36 @@ func (s *TranscodeService) ProcessTranscode(
 	return nil
 }
 
+// removeRenditions deletes the playlists and segments of a folder, keeping the source and the poster
+func removeRenditions(folder string) error {
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !isRendition(entry.Name()) {
+			continue
+		}
+
+		if err = os.Remove(filepath.Join(folder, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isRendition(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".m3u8" || ext == ".ts"
+}
+
 // transcodeAndChunk is a method to transcode and chunk video into smaller segments using ffmpeg
-func (s *TranscodeService) transcodeAndChunk(uploadPath string, videoPath string) error {
+func (s *TranscodeService) transcodeAndChunk(ctx context.Context, uploadPath string, videoPath string) error {
 	const op = "TranscodeService.transcodeAndChunk"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 		sl.String("upload_path", uploadPath),
 		sl.String("video_path", videoPath),
@@ -97,9 +147,10 @@ func (s *TranscodeService) transcodeAndChunk(uploadPath string, videoPath string
 
 	resolutions := s.cfg.Video.Resolutions
 
-	videoDimensions, err := s.getVideoDimensions(videoPath)
+	videoDimensions, err := s.getVideoDimensions(ctx, videoPath)
 	if err != nil {
 		log.Error("failed to get video dimensions", sl.Err(err))
+		s.metrics.FFmpegFailures.WithLabelValues("probe").Inc()
 		return err
 	}
 
@@ -109,16 +160,18 @@ func (s *TranscodeService) transcodeAndChunk(uploadPath string, videoPath string
 		return heightI < heightJ
 	})
 
-	videoCodec, err := s.getVideoCodec(videoPath)
+	videoCodec, err := s.getVideoCodec(ctx, videoPath)
 	if err != nil {
 		log.Error("failed to get video codec", sl.Err(err))
+		s.metrics.FFmpegFailures.WithLabelValues("probe").Inc()
 		return err
 	}
 
 	if videoCodec == "hevc" || videoCodec == "h265" {
-		outputPath, err := s.h265ToH264(videoPath, uploadPath)
+		outputPath, err := s.h265ToH264(ctx, videoPath, uploadPath)
 		if err != nil {
 			log.Error("failed to transcode video from hevc to h264", sl.Err(err))
+			s.metrics.FFmpegFailures.WithLabelValues("h264").Inc()
 			return err
 		}
 
@@ -132,10 +185,15 @@ func (s *TranscodeService) transcodeAndChunk(uploadPath string, videoPath string
 			scaleParam = fmt.Sprintf("scale=%s:-2", res)
 		}
 
-		if err = s.transcodeVideoCMD(videoPath, uploadPath, res, scaleParam); err != nil {
+		start := time.Now()
+
+		if err = s.transcodeVideoCMD(ctx, videoPath, uploadPath, res, scaleParam); err != nil {
 			log.Error("failed to transcode video", sl.Err(err))
+			s.metrics.FFmpegFailures.WithLabelValues("transcode").Inc()
 			return err
 		}
+
+		s.metrics.TranscodeDuration.WithLabelValues(res).Observe(time.Since(start).Seconds())
 	}
 
 	go func(deleteVideo string) {
@@ -147,10 +205,10 @@ func (s *TranscodeService) transcodeAndChunk(uploadPath string, videoPath string
 	return nil
 }
 
-func (s *TranscodeService) h265ToH264(videoPath, uploadPath string) (string, error) {
+func (s *TranscodeService) h265ToH264(ctx context.Context, videoPath, uploadPath string) (string, error) {
 	const op = "TranscodeService.h265ToH264"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 		sl.String("uploadPath", uploadPath),
 	)
@@ -172,7 +230,7 @@ func (s *TranscodeService) h265ToH264(videoPath, uploadPath string) (string, err
 	cmd.Stdout = &out
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
+	if err := tracing.Run(ctx, cmd); err != nil {
 		log.Error("failed to transcode video from hevc to h264",
 			sl.Err(err),
 			sl.String("stdout", out.String()),
@@ -190,10 +248,10 @@ func (s *TranscodeService) h265ToH264(videoPath, uploadPath string) (string, err
 }
 
 // getVideoCodec is a method to get the codec of the video
-func (s *TranscodeService) getVideoCodec(videoPath string) (string, error) {
+func (s *TranscodeService) getVideoCodec(ctx context.Context, videoPath string) (string, error) {
 	const op string = "VideoService.getVideoCodec"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 		sl.String("video_path", videoPath),
 	)
@@ -209,7 +267,7 @@ func (s *TranscodeService) getVideoCodec(videoPath string) (string, error) {
 
 	var out bytes.Buffer
 	cmd.Stdout = &out
-	if err := cmd.Run(); err != nil {
+	if err := tracing.Run(ctx, cmd); err != nil {
 		log.Error("failed to get video codec", sl.Err(err))
 		return "", errors.New("failed to get video codec")
 	}
@@ -220,10 +278,10 @@ func (s *TranscodeService) getVideoCodec(videoPath string) (string, error) {
 }
 
 // createMasterM8U3PlayList is a method to create master m8u3 playlist
-func (s *TranscodeService) createMasterM8U3PlayList(uploadPath string, chunkHash string) error {
+func (s *TranscodeService) createMasterM8U3PlayList(ctx context.Context, uploadPath string, chunkHash string) error {
 	const op = "TranscodeService.createMasterM8U3PlayList"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 		sl.String("upload_path", uploadPath),
 	)
@@ -264,10 +322,10 @@ func (s *TranscodeService) createMasterM8U3PlayList(uploadPath string, chunkHash
 }
 
 // getVideoDimensions is a method to get the dimensions of the video
-func (s *TranscodeService) getVideoDimensions(videoPath string) (map[string]int, error) {
+func (s *TranscodeService) getVideoDimensions(ctx context.Context, videoPath string) (map[string]int, error) {
 	const op string = "TranscodeService.getVideoDimensions"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 		sl.String("video_path", videoPath),
 	)
@@ -285,15 +343,18 @@ func (s *TranscodeService) getVideoDimensions(videoPath string) (map[string]int,
 		videoPath,
 	)
 
-	output, err := cmd.Output()
-	if err != nil {
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := tracing.Run(ctx, cmd); err != nil {
 		log.Error("failed to get video dimensions", sl.Err(err))
 		return nil, errors.New("failed to get video dimensions")
 	}
 
-	dimensions := strings.Split(strings.TrimSpace(string(output)), ",")
+	output := out.String()
+
+	dimensions := strings.Split(strings.TrimSpace(output), ",")
 	if len(dimensions) != 2 {
-		log.Error("invalid video dimensions", sl.String("dimensions", string(output)))
+		log.Error("invalid video dimensions", sl.String("dimensions", output))
 		return nil, errors.New("invalid video dimensions")
 	}
 
@@ -314,6 +375,7 @@ func (s *TranscodeService) getVideoDimensions(videoPath string) (map[string]int,
 
 // transcodeVideoCMD is a method to transcode video using ffmpeg
 func (s *TranscodeService) transcodeVideoCMD(
+	ctx context.Context,
 	videoPath,
 	uploadPath,
 	resolution,
@@ -321,7 +383,7 @@ func (s *TranscodeService) transcodeVideoCMD(
 ) error {
 	const op string = "transcodeVideoCMD.transcodeVideoCMD"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 		sl.String("video_path", videoPath),
 		sl.String("upload_path", uploadPath),
@@ -354,7 +416,7 @@ func (s *TranscodeService) transcodeVideoCMD(
 	cmd.Stdout = &out
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
+	if err := tracing.Run(ctx, cmd); err != nil {
 		log.Error("failed to transcode video",
 			sl.String("resolution", resolution),
 			sl.String("stdout", out.String()),
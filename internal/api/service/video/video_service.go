@@ -2,23 +2,42 @@ package video
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"crypto/sha256"
+	"database/sql"
 	"errors"
 	"fmt"
 	ffmpeg "github.com/u2takey/ffmpeg-go"
+	"go-fitness/external/apperr"
 	"go-fitness/external/config"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/db"
+	"go-fitness/external/db/query"
+	"go-fitness/external/logger/logctx"
 	"go-fitness/external/logger/sl"
+	"go-fitness/external/metrics"
+	"go-fitness/external/tracing"
 	"go-fitness/internal/api/data"
 	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/event"
 	"go-fitness/internal/api/types"
+	"go.opentelemetry.io/otel/trace"
 	"io"
+	"io/fs"
 	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
+)
+
+const (
+	ReplacePolicyKeep   = "keep"
+	ReplacePolicyTrash  = "trash"
+	ReplacePolicyDelete = "delete"
 )
 
 type TaskQueue interface {
@@ -28,40 +47,50 @@ type TaskQueue interface {
 type VideoService struct {
 	log       *slog.Logger
 	cfg       *config.Config
+	metrics   *metrics.Metrics
 	videoRepo VideoRepository
 	worker    TaskQueue
 }
 
 type VideoRepository interface {
-	Create(context.Context, types.Video) (int64, error)
-	UpdateStatus(context.Context, int64, enum.VideoStatus) error
+	Create(context.Context, types.Video, func(int64, string) ([]types.OutboxEvent, error)) (int64, error)
+	UpdateStatus(context.Context, int64, enum.VideoStatus, ...types.OutboxEvent) error
 	GetByUUID(context.Context, string) (*types.Video, error)
-	GetList(context.Context, map[string]interface{}) ([]types.Video, error)
-	Delete(context.Context, int64) error
+	GetList(context.Context, filter.Filter) ([]types.Video, int64, error)
+	Delete(context.Context, int64, ...types.OutboxEvent) error
 	UpdatePoster(context.Context, int64, string) error
 	GetListWhereStatusProcessedAndPosterIsNull(context.Context) ([]types.Video, error)
 	CheckIfVideoExistByHashName(context.Context, string) bool
+	GetByUUIDWithTrashed(context.Context, string) (*types.Video, error)
+	GetByIDWithTrashed(context.Context, int64) (*types.Video, error)
+	SoftDelete(context.Context, int64, ...types.OutboxEvent) error
+	Restore(context.Context, int64) error
+	GetTrashedBefore(context.Context, time.Time) ([]types.Video, error)
+	IsReferenced(context.Context, int64) (bool, error)
+	GetByStatus(context.Context, enum.VideoStatus) ([]types.Video, error)
 }
 
 func NewVideoService(
 	log *slog.Logger,
 	cfg *config.Config,
+	metrics *metrics.Metrics,
 	videoRepo VideoRepository,
 	worker TaskQueue,
 ) *VideoService {
 	return &VideoService{
 		log:       log,
 		cfg:       cfg,
+		metrics:   metrics,
 		videoRepo: videoRepo,
 		worker:    worker,
 	}
 }
 
 // readFile is a method to read file from the storage path
-func (s *VideoService) readFile(path string) ([]byte, error) {
+func (s *VideoService) readFile(ctx context.Context, path string) ([]byte, error) {
 	const op = "Video.readFile"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 		sl.String("path", path),
 	)
@@ -79,7 +108,7 @@ func (s *VideoService) readFile(path string) ([]byte, error) {
 func (s *VideoService) ProcessGetVideoPlayListByUUID(ctx context.Context, uuid string) ([]byte, error) {
 	const op = "Video.ProcessGetVideoM3U8ByUUID"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 		sl.String("uuid", uuid),
 	)
@@ -96,21 +125,21 @@ func (s *VideoService) ProcessGetVideoPlayListByUUID(ctx context.Context, uuid s
 		video.HashName,
 	)
 
-	return s.readFile(playListPath)
+	return s.readFile(ctx, playListPath)
 }
 
 // ProcessGetVideoM3U8 is a method to process video M3U8 and return the video file
-func (s *VideoService) ProcessGetVideoM3U8(url string) ([]byte, error) {
+func (s *VideoService) ProcessGetVideoM3U8(ctx context.Context, url string) ([]byte, error) {
 	const op = "Video.ProcessGetVideoM3U8"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 		sl.String("url", url),
 	)
 
 	//var lastError error
 
-	hashName, resolution, err := s.parseURL(url)
+	hashName, resolution, err := s.parseURL(ctx, url)
 	if err != nil {
 		log.Error("failed to parse hash", sl.Err(err))
 		return nil, errors.New("failed to parse hash")
@@ -123,7 +152,7 @@ func (s *VideoService) ProcessGetVideoM3U8(url string) ([]byte, error) {
 		resolution,
 	)
 
-	video, err := s.readFile(videoPath)
+	video, err := s.readFile(ctx, videoPath)
 	if err != nil {
 		log.Error("failed to read file", sl.Err(err))
 		//TODO: Implement this
@@ -136,7 +165,7 @@ func (s *VideoService) ProcessGetVideoM3U8(url string) ([]byte, error) {
 
 			res = fmt.Sprintf("%s.m3u8", res)
 			videoPath = fmt.Sprintf("%s/%s/%s/%s", s.cfg.HTTPServer.StoragePath, s.cfg.Video.VideoPath, hashName, res)
-			video, err = s.readFile(videoPath)
+			video, err = s.readFile(ctx, videoPath)
 			if err == nil {
 				return video, nil
 			}
@@ -154,12 +183,12 @@ func (s *VideoService) ProcessGetVideoM3U8(url string) ([]byte, error) {
 func (s *VideoService) ProcessGetVideoTS(ctx context.Context, url string) ([]byte, error) {
 	const op = "Video.ProcessGetVideoTS"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 		sl.String("url", url),
 	)
 
-	hashName, resolution, err := s.parseURL(url)
+	hashName, resolution, err := s.parseURL(ctx, url)
 	if err != nil {
 		log.Error("failed to parse hash", sl.Err(err))
 		return nil, errors.New("failed to parse hash")
@@ -172,14 +201,14 @@ func (s *VideoService) ProcessGetVideoTS(ctx context.Context, url string) ([]byt
 		resolution,
 	)
 
-	return s.readFile(videoPath)
+	return s.readFile(ctx, videoPath)
 }
 
 // parseURL is a method to parse the URL and return the hash and resolution
-func (s *VideoService) parseURL(path string) (string, string, error) {
+func (s *VideoService) parseURL(ctx context.Context, path string) (string, string, error) {
 	const op string = "Video.parseURL"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 	)
 
@@ -193,38 +222,84 @@ func (s *VideoService) parseURL(path string) (string, string, error) {
 	return paths[len(paths)-2], paths[len(paths)-1], nil
 }
 
-// ProcessUpload is a method to process video upload and store it in the storage path
+// ProcessUpload is a method to process video upload and store it in the storage path.
+// Called in a transaction the files are removed when it rolls back and the transcode is queued only once it commits
 func (s *VideoService) ProcessUpload(
 	ctx context.Context,
 	data data.VideoData,
 ) (int64, error) {
-	const op string = "Video.ProcessUpload"
+	upload, err := s.PrepareUpload(ctx, data)
+	if err != nil {
+		return 0, err
+	}
+
+	videoID, err := s.StoreUpload(ctx, upload)
+	if err != nil {
+		s.DiscardUpload(ctx, upload)
+		return 0, err
+	}
 
-	log := s.log.With(
+	return videoID, nil
+}
+
+// PrepareUpload writes the upload to its folder, probes its duration and creates the poster.
+// It touches no transaction, callers keep the slow file work out of theirs and only StoreUpload in it
+func (s *VideoService) PrepareUpload(
+	ctx context.Context,
+	videoData data.VideoData,
+) (data.PreparedUpload, error) {
+	const op string = "Video.PrepareUpload"
+
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 	)
 
-	s.generateHash(&data)
+	s.generateHash(ctx, &videoData)
 
-	if ok := s.checkIfExistVideoInTableAndInFolderByHashName(ctx, *data.Hash); ok {
+	// any row holds the hash, a video still processing or a failed one too, and videos_hash_name_unique would reject the insert
+	if s.videoRepo.CheckIfVideoExistByHashName(ctx, *videoData.Hash) {
 		log.Warn("video already exists")
-		return 0, errors.New("video_already_exists")
+		return data.PreparedUpload{}, apperr.Conflict("video_already_exists")
 	}
 
-	uploadPath := fmt.Sprintf("%s/%s/%s", s.cfg.HTTPServer.StoragePath, s.cfg.Video.VideoPath, *data.Hash)
+	uploadPath := fmt.Sprintf("%s/%s/%s", s.cfg.HTTPServer.StoragePath, s.cfg.Video.VideoPath, *videoData.Hash)
+
+	videoData.UploadPath = uploadPath
 
-	data.UploadPath = uploadPath
+	// the folder is claimed before anything is written, so a concurrent upload of the same file
+	// gets a conflict and the cleanup only ever removes a folder this call created
+	if err := s.claimUploadPath(uploadPath); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			log.Warn("upload folder already exists", sl.String("path", uploadPath))
+			return data.PreparedUpload{}, apperr.Conflict("video_already_exists")
+		}
 
-	dstPath, err := s.uploadFile(data)
+		log.Error("failed to create upload directory", sl.Err(err))
+		return data.PreparedUpload{}, apperr.Internal("failed_to_upload_file")
+	}
+
+	prepared := false
+	defer func() {
+		if !prepared {
+			s.removeUpload(log, uploadPath)
+		}
+	}()
+
+	dstPath, err := s.uploadFile(ctx, videoData)
 	if err != nil {
 		log.Error("failed to upload file", sl.Err(err))
-		return 0, errors.New("failed_to_upload_file")
+		return data.PreparedUpload{}, apperr.Internal("failed_to_upload_file")
+	}
+
+	if info, err := os.Stat(dstPath); err == nil {
+		s.metrics.UploadBytes.Observe(float64(info.Size()))
 	}
 
-	duration, err := s.getVideoDuration(dstPath)
+	duration, err := s.getVideoDuration(ctx, dstPath)
 	if err != nil {
 		log.Error("failed to get video duration", sl.Err(err))
-		return 0, errors.New("failed_to_get_video_duration")
+		s.metrics.FFmpegFailures.WithLabelValues("probe").Inc()
+		return data.PreparedUpload{}, apperr.Internal("failed_to_get_video_duration")
 	}
 
 	posterTime := s.posterTime(duration)
@@ -232,37 +307,116 @@ func (s *VideoService) ProcessUpload(
 
 	posterPath := filepath.Join(uploadPath, posterTitle)
 
-	if err = s.createPoster(dstPath, posterPath, posterTime); err != nil {
+	if err = s.createPoster(ctx, dstPath, posterPath, posterTime); err != nil {
 		log.Error("failed to create poster", sl.Err(err))
-		return 0, errors.New("failed_to_create_poster")
+		s.metrics.FFmpegFailures.WithLabelValues("poster").Inc()
+		return data.PreparedUpload{}, apperr.Internal("failed_to_create_poster")
 	}
 
+	prepared = true
+
+	return data.PreparedUpload{
+		Hash:       *videoData.Hash,
+		UploadPath: uploadPath,
+		DstPath:    dstPath,
+		Poster:     posterTitle,
+		Duration:   duration,
+	}, nil
+}
+
+// StoreUpload writes the video row of a prepared upload. Called in a transaction the files are removed
+// when it rolls back and the transcode is queued only once it commits. When it fails the caller discards the upload
+func (s *VideoService) StoreUpload(ctx context.Context, upload data.PreparedUpload) (int64, error) {
+	const op string = "Video.StoreUpload"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.String("hash", upload.Hash),
+	)
+
+	var videoUUID string
+
+	poster := upload.Poster
+
 	videoID, err := s.videoRepo.Create(ctx, types.Video{
-		HashName: *data.Hash,
+		HashName: upload.Hash,
 		Status:   enum.VideoStatusProcessing,
-		Duration: duration,
-		Poster:   &posterTitle,
+		Duration: upload.Duration,
+		Poster:   &poster,
+	}, func(id int64, uuid string) ([]types.OutboxEvent, error) {
+		videoUUID = uuid
+
+		video := &types.Video{ID: id, UUID: uuid, Status: enum.VideoStatusProcessing}
+
+		uploaded, err := videoEvent(event.VideoUploaded, video, false)
+		if err != nil {
+			return nil, err
+		}
+
+		processing, err := videoEvent(event.VideoProcessing, video, false)
+		if err != nil {
+			return nil, err
+		}
+
+		return []types.OutboxEvent{uploaded, processing}, nil
 	})
 	if err != nil {
 		log.Error("failed to create video", sl.Err(err))
-		return 0, errors.New("failed_to_create_workout")
+		return 0, apperr.Internal("failed_to_create_video")
 	}
 
-	s.worker.AddTask(TranscodeTask{
-		UploadPath: uploadPath,
-		VideoID:    videoID,
-		DstPath:    dstPath,
-		ChunkHash:  *data.Hash,
+	db.OnRollback(ctx, func() {
+		log.Warn("upload rolled back", sl.Int64("video_id", videoID))
+		s.removeUpload(log, upload.UploadPath)
+	})
+
+	db.AfterCommit(ctx, func() {
+		s.worker.AddTask(TranscodeTask{
+			UploadPath: upload.UploadPath,
+			VideoID:    videoID,
+			VideoUUID:  videoUUID,
+			DstPath:    upload.DstPath,
+			ChunkHash:  upload.Hash,
+			Link:       trace.SpanContextFromContext(ctx),
+		})
 	})
 
 	return videoID, nil
 }
 
+// DiscardUpload removes the files of a prepared upload whose video row was never written
+func (s *VideoService) DiscardUpload(ctx context.Context, upload data.PreparedUpload) {
+	const op string = "Video.DiscardUpload"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.String("hash", upload.Hash),
+	)
+
+	s.removeUpload(log, upload.UploadPath)
+}
+
+// claimUploadPath creates the folder of an upload, it fails with fs.ErrExist when the folder is already there
+func (s *VideoService) claimUploadPath(uploadPath string) error {
+	if err := os.MkdirAll(filepath.Dir(uploadPath), 0755); err != nil {
+		return err
+	}
+
+	return os.Mkdir(uploadPath, 0755)
+}
+
+// removeUpload deletes the folder of an upload whose video row was not kept
+func (s *VideoService) removeUpload(log *slog.Logger, uploadPath string) {
+	if err := os.RemoveAll(uploadPath); err != nil {
+		log.Error("failed to remove upload", sl.String("path", uploadPath), sl.Err(err))
+	}
+}
+
 // GetPosterByUUID is a method to get the poster by UUID
 func (s *VideoService) GetPosterByUUID(ctx context.Context, uuid string) (string, error) {
 	const op = "Video.GetPosterByUUID"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 	)
 
@@ -287,39 +441,11 @@ func (s *VideoService) GetPosterByUUID(ctx context.Context, uuid string) (string
 	return posterPath, nil
 }
 
-// checkIfExistVideoInTableAndInFolderByHashName is a method to check if the video exists in the table and folder by hash name
-func (s *VideoService) checkIfExistVideoInTableAndInFolderByHashName(ctx context.Context, hash string) bool {
-	const op = "Video.checkIfExistVideoInTableAndInFolderByHashName"
-
-	log := s.log.With(
-		sl.String("op", op),
-		sl.String("hash", hash),
-	)
-
-	if !s.videoRepo.CheckIfVideoExistByHashName(ctx, hash) {
-		log.Warn("video does not exist in database")
-		return false
-	}
-
-	playlistPath := fmt.Sprintf("%s/%s/%s/playlist.m3u8",
-		s.cfg.HTTPServer.StoragePath,
-		s.cfg.Video.VideoPath,
-		hash,
-	)
-
-	if _, err := os.Stat(playlistPath); os.IsNotExist(err) {
-		log.Warn("playlist does not exist in folder", sl.String("path", playlistPath))
-		return false
-	}
-
-	return true
-}
-
 // getVideoDuration is a method to get the duration of the video
-func (s *VideoService) getVideoDuration(filePath string) (float64, error) {
+func (s *VideoService) getVideoDuration(ctx context.Context, filePath string) (float64, error) {
 	const op = "Video.getVideoDuration"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 		sl.String("file_path", filePath),
 	)
@@ -335,13 +461,14 @@ func (s *VideoService) getVideoDuration(filePath string) (float64, error) {
 		filePath,
 	)
 
-	out, err := cmd.Output()
-	if err != nil {
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := tracing.Run(ctx, cmd); err != nil {
 		log.Error("error running ffprobe", sl.Err(err))
 		return 0, errors.New("error running ffprobe")
 	}
 
-	durationStr := strings.TrimSpace(string(out))
+	durationStr := strings.TrimSpace(out.String())
 
 	duration, err := strconv.ParseFloat(durationStr, 64)
 	if err != nil {
@@ -356,7 +483,7 @@ func (s *VideoService) getVideoDuration(filePath string) (float64, error) {
 func (s *VideoService) CreatePosterFromUploadedTsFiles(ctx context.Context) error {
 	const op = "Video.CreatePosterFromUploadedTsFiles"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 	)
 
@@ -375,7 +502,7 @@ func (s *VideoService) CreatePosterFromUploadedTsFiles(ctx context.Context) erro
 			"1080.m3u8",
 		)
 
-		tsFilePath, posterTime, err := s.findTsFileForTime(m3u8Path, posterTime)
+		tsFilePath, posterTime, err := s.findTsFileForTime(ctx, m3u8Path, posterTime)
 		if err != nil {
 			log.Error("failed to find ts file for time", sl.Err(err))
 			return errors.New("failed to find ts file for time")
@@ -387,9 +514,9 @@ func (s *VideoService) CreatePosterFromUploadedTsFiles(ctx context.Context) erro
 
 		log.Info("Creating poster", sl.String("tsFilePath", tsFilePath), sl.Float64("posterTime", posterTime))
 
-		if err = s.createPoster(tsFilePath, posterPath, posterTime); err != nil {
+		if err = s.createPoster(ctx, tsFilePath, posterPath, posterTime); err != nil {
 			log.Error("failed to create poster", sl.Err(err))
-			return errors.New("failed_to_create_poster")
+			return apperr.Internal("failed_to_create_poster")
 		}
 
 		if err := s.videoRepo.UpdatePoster(ctx, video.ID, posterTitle); err != nil {
@@ -416,10 +543,10 @@ func (s *VideoService) posterTitle(posterTime float64) string {
 }
 
 // findTsFileForTime is a method to find the TS file for the time
-func (s *VideoService) findTsFileForTime(m3u8Path string, globalTime float64) (string, float64, error) {
+func (s *VideoService) findTsFileForTime(ctx context.Context, m3u8Path string, globalTime float64) (string, float64, error) {
 	const op = "Video.findTsFileForTime"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 		sl.String("m3u8Path", m3u8Path),
 		sl.Float64("globalTime", globalTime),
@@ -471,10 +598,10 @@ func (s *VideoService) findTsFileForTime(m3u8Path string, globalTime float64) (s
 }
 
 // createPoster is a method to create poster for the video
-func (s *VideoService) createPoster(videoPath string, posterPath string, posterTime float64) error {
+func (s *VideoService) createPoster(ctx context.Context, videoPath string, posterPath string, posterTime float64) error {
 	const op string = "Video.createPoster"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 		sl.String("video_path", videoPath),
 		sl.String("poster_path", posterPath),
@@ -488,36 +615,23 @@ func (s *VideoService) createPoster(videoPath string, posterPath string, posterT
 		Output(posterPath, ffmpeg.KwArgs{"vframes": 1, "format": "image2", "vcodec": "mjpeg", "y": ""}).
 		ErrorToStdOut()
 
-	if err := cmd.Run(); err != nil {
+	if err := tracing.Run(ctx, cmd.Compile()); err != nil {
 		log.Error("failed to create poster", sl.Err(err))
-		return errors.New("failed_to_create_poster")
+		return apperr.Internal("failed_to_create_poster")
 	}
 
 	return nil
 }
 
 // uploadFile is a method to upload file to the storage path
-func (s *VideoService) uploadFile(data data.VideoData) (string, error) {
+func (s *VideoService) uploadFile(ctx context.Context, data data.VideoData) (string, error) {
 	const op string = "Video.uploadFile"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
-		sl.Any("data", data),
+		sl.String("upload_path", data.UploadPath),
 	)
 
-	if err := os.MkdirAll(data.UploadPath, 0755); err != nil {
-		log.Error("failed to create upload directory", sl.Err(err))
-		return "", errors.New("failed to create upload directory")
-	}
-
-	if _, err := os.Stat(data.UploadPath); os.IsNotExist(err) {
-		err := os.Mkdir(data.UploadPath, os.ModePerm)
-		if err != nil {
-			log.Error("failed to create upload directory", sl.Err(err))
-			return "", errors.New("failed to create upload directory")
-		}
-	}
-
 	dstPath := filepath.Join(data.UploadPath, data.Header.Filename)
 
 	dst, err := os.Create(dstPath)
@@ -539,50 +653,10 @@ func (s *VideoService) uploadFile(data data.VideoData) (string, error) {
 	return dstPath, nil
 }
 
-// DeleteAllVideoFilesIfDoestExistInTable is a method to delete all video files if does not exist in table
-func (s *VideoService) DeleteAllVideoFilesIfDoestExistInTable(ctx context.Context) {
-	const op string = "Video.DeleteAllVideoFilesIfDoestExistInTable"
-
-	log := s.log.With("op", op)
-
-	videos, err := s.videoRepo.GetList(ctx, nil)
-	if err != nil {
-		log.Error("failed to get videos", sl.Err(err))
-		return
-	}
-
-	videoMap := make(map[string]bool)
-	for _, video := range videos {
-		videoMap[video.HashName] = true
-	}
-
-	videoFilesPath := filepath.Join(s.cfg.HTTPServer.StoragePath, s.cfg.Video.VideoPath)
-	entries, err := os.ReadDir(videoFilesPath)
-	if err != nil {
-		log.Error("failed to read video directory", sl.Err(err))
-		return
-	}
-
-	for _, entry := range entries {
-		if entry.IsDir() {
-			log.Info("found video file", entry.Name())
-			if !videoMap[entry.Name()] {
-				videoPath := filepath.Join(videoFilesPath, entry.Name())
-				err := os.RemoveAll(videoPath)
-				if err != nil {
-					log.Error("failed to delete video file", entry.Name(), sl.Err(err))
-				} else {
-					log.Info("deleted video file", entry.Name())
-				}
-			}
-		}
-	}
-}
-
-func (s *VideoService) generateHash(data *data.VideoData) {
+func (s *VideoService) generateHash(ctx context.Context, data *data.VideoData) {
 	const op string = "Video.generateHash"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 	)
 
@@ -603,7 +677,7 @@ func (s *VideoService) generateHash(data *data.VideoData) {
 func (s *VideoService) UpdateStatus(ctx context.Context, videoID int64, status enum.VideoStatus) error {
 	const op string = "Video.UpdateStatus"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 		sl.Int64("video_id", videoID),
 		sl.String("status", status.String()),
@@ -616,3 +690,235 @@ func (s *VideoService) UpdateStatus(ctx context.Context, videoID int64, status e
 
 	return nil
 }
+
+// List is a method to get a page of videos matching the filter stored in the context
+func (s *VideoService) List(ctx context.Context) ([]types.Video, int64, error) {
+	const op string = "Video.List"
+
+	f := filter.FromContext(ctx)
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Any("filter", f),
+	)
+
+	videos, total, err := s.videoRepo.GetList(ctx, f)
+	if err != nil {
+		if errors.Is(err, query.ErrUnknownFilter) || errors.Is(err, query.ErrUnknownSort) {
+			log.Warn("invalid filter", sl.Err(err))
+			return nil, 0, apperr.BadRequest("invalid_filter")
+		}
+
+		log.Error("failed to get videos", sl.Err(err))
+		return nil, 0, apperr.Internal("failed_to_get_list")
+	}
+
+	return videos, total, nil
+}
+
+// SoftDelete is a method to hide the video from clients without removing its files
+func (s *VideoService) SoftDelete(ctx context.Context, uuid string) error {
+	const op string = "Video.SoftDelete"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.String("uuid", uuid),
+	)
+
+	video, err := s.videoRepo.GetByUUIDWithTrashed(ctx, uuid)
+	if err != nil {
+		log.Error("failed to get video by uuid", sl.Err(err))
+		return apperr.NotFound("video_not_found")
+	}
+
+	if video.DeletedAt != nil {
+		log.Warn("video is already deleted")
+		return apperr.Conflict("video_already_deleted")
+	}
+
+	deleted, err := videoEvent(event.VideoDeleted, video, false)
+	if err != nil {
+		log.Error("failed to build video event", sl.Err(err))
+		return apperr.Internal("failed_to_delete")
+	}
+
+	if err = s.videoRepo.SoftDelete(ctx, video.ID, deleted); err != nil {
+		log.Error("failed to soft delete video", sl.Err(err))
+		return apperr.Internal("failed_to_delete")
+	}
+
+	return nil
+}
+
+// Restore is a method to make a soft deleted video visible again
+func (s *VideoService) Restore(ctx context.Context, uuid string) error {
+	const op string = "Video.Restore"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.String("uuid", uuid),
+	)
+
+	video, err := s.videoRepo.GetByUUIDWithTrashed(ctx, uuid)
+	if err != nil {
+		log.Error("failed to get video by uuid", sl.Err(err))
+		return apperr.NotFound("video_not_found")
+	}
+
+	if video.DeletedAt == nil {
+		log.Warn("video is not deleted")
+		return apperr.Conflict("video_not_deleted")
+	}
+
+	if err = s.videoRepo.Restore(ctx, video.ID); err != nil {
+		log.Error("failed to restore video", sl.Err(err))
+		return apperr.Internal("failed_to_restore")
+	}
+
+	return nil
+}
+
+// PurgeVideo is a method to permanently remove the video row once nothing references it, and its files after the commit
+func (s *VideoService) PurgeVideo(ctx context.Context, videoID int64) (bool, error) {
+	const op string = "Video.PurgeVideo"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("video_id", videoID),
+	)
+
+	referenced, err := s.videoRepo.IsReferenced(ctx, videoID)
+	if err != nil {
+		log.Error("failed to check video references", sl.Err(err))
+		return false, err
+	}
+
+	if referenced {
+		log.Info("video is still referenced, skipping")
+		return false, nil
+	}
+
+	video, err := s.videoRepo.GetByIDWithTrashed(ctx, videoID)
+	if err != nil {
+		log.Error("failed to get video by id", sl.Err(err))
+		return false, err
+	}
+
+	deleted, err := videoEvent(event.VideoDeleted, video, true)
+	if err != nil {
+		log.Error("failed to build video event", sl.Err(err))
+		return false, err
+	}
+
+	if err = s.videoRepo.Delete(ctx, video.ID, deleted); err != nil {
+		log.Error("failed to delete video", sl.Err(err))
+		return false, err
+	}
+
+	// the files go only once the row is gone for good, a failed delete keeps a playable video
+	videoPath := filepath.Join(s.cfg.HTTPServer.StoragePath, s.cfg.Video.VideoPath, video.HashName)
+
+	db.AfterCommit(ctx, func() {
+		if err := os.RemoveAll(videoPath); err != nil {
+			log.Error("failed to remove video folder", sl.String("path", videoPath), sl.Err(err))
+			return
+		}
+
+		log.Info("video purged", sl.String("hash_name", video.HashName))
+	})
+
+	return true, nil
+}
+
+// Release is a method to dispose of a video its owner no longer uses, according to the replace policy
+func (s *VideoService) Release(ctx context.Context, videoID int64) error {
+	const op string = "Video.Release"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("video_id", videoID),
+		sl.String("policy", s.cfg.Video.ReplacePolicy),
+	)
+
+	switch s.cfg.Video.ReplacePolicy {
+	case ReplacePolicyKeep:
+		return nil
+	case ReplacePolicyDelete:
+		if _, err := s.PurgeVideo(ctx, videoID); err != nil {
+			log.Error("failed to purge released video", sl.Err(err))
+			return err
+		}
+	default:
+		referenced, err := s.videoRepo.IsReferenced(ctx, videoID)
+		if err != nil {
+			log.Error("failed to check video references", sl.Err(err))
+			return err
+		}
+
+		if referenced {
+			return nil
+		}
+
+		video, err := s.videoRepo.GetByIDWithTrashed(ctx, videoID)
+		if err != nil {
+			log.Error("failed to get video by id", sl.Err(err))
+			return err
+		}
+
+		deleted, err := videoEvent(event.VideoDeleted, video, false)
+		if err != nil {
+			log.Error("failed to build video event", sl.Err(err))
+			return err
+		}
+
+		if err = s.videoRepo.SoftDelete(ctx, videoID, deleted); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			log.Error("failed to trash released video", sl.Err(err))
+			return err
+		}
+	}
+
+	log.Info("video released")
+
+	return nil
+}
+
+// PurgeTrashed is a method to permanently remove videos soft deleted before the given time
+func (s *VideoService) PurgeTrashed(ctx context.Context, before time.Time) (int, error) {
+	const op string = "Video.PurgeTrashed"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+	)
+
+	videos, err := s.videoRepo.GetTrashedBefore(ctx, before)
+	if err != nil {
+		log.Error("failed to get trashed videos", sl.Err(err))
+		return 0, err
+	}
+
+	purged := 0
+
+	for _, video := range videos {
+		ok, err := s.PurgeVideo(ctx, video.ID)
+		if err != nil {
+			log.Error("failed to purge video", sl.Int64("video_id", video.ID), sl.Err(err))
+			continue
+		}
+
+		if ok {
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// videoEvent builds the outbox event announcing a change of the video to webhooks and admins
+func videoEvent(name string, video *types.Video, permanent bool) (types.OutboxEvent, error) {
+	return event.NewOutboxEvent(name, event.VideoPayload{
+		VideoID:   video.ID,
+		UUID:      video.UUID,
+		Status:    video.Status.String(),
+		Permanent: permanent,
+	})
+}
@@ -4,15 +4,23 @@ import (
 	"context"
 	"fmt"
 	"go-fitness/external/logger/sl"
+	"go-fitness/external/metrics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"log/slog"
 )
 
+const tracerName = "go-fitness/internal/api/service/video"
+
 type Transcoder interface {
 	ProcessTranscode(ctx context.Context, task TranscodeTask) error
 }
 
 type WorkerPool struct {
 	log              *slog.Logger
+	metrics          *metrics.Metrics
 	taskQueue        chan TranscodeTask
 	transcodeService Transcoder
 }
@@ -21,16 +29,21 @@ type WorkerPool struct {
 type TranscodeTask struct {
 	UploadPath string
 	VideoID    int64
+	VideoUUID  string
 	DstPath    string
 	ChunkHash  string
+	// Link is the span that queued the task, the transcode trace points back to the upload request through it
+	Link trace.SpanContext
 }
 
 func NewWorkerPool(
 	log *slog.Logger,
+	metrics *metrics.Metrics,
 	transcodeService Transcoder,
 ) *WorkerPool {
 	pool := &WorkerPool{
 		log:              log,
+		metrics:          metrics,
 		taskQueue:        make(chan TranscodeTask, 50),
 		transcodeService: transcodeService,
 	}
@@ -53,14 +66,47 @@ func (p *WorkerPool) worker(id int) {
 	)
 
 	for task := range p.taskQueue {
+		p.metrics.TranscodeQueue.Dec()
+		p.metrics.TranscodesInFlight.Inc()
+
 		log.Info("Processing task", sl.String("task", fmt.Sprintf("%+v", task)))
-		if err := p.transcodeService.ProcessTranscode(context.Background(), task); err != nil {
-			log.Error("Failed to process transcode", sl.Err(err))
-		}
+		p.process(log, task)
+
+		p.metrics.TranscodesInFlight.Dec()
+	}
+}
+
+// process runs the task in a trace of its own, linked to the request that queued it rather than a child of it,
+// since the request is long over when the transcode ends
+func (p *WorkerPool) process(log *slog.Logger, task TranscodeTask) {
+	opts := []trace.SpanStartOption{
+		trace.WithNewRoot(),
+		trace.WithAttributes(
+			attribute.Int64("video.id", task.VideoID),
+			attribute.String("video.uuid", task.VideoUUID),
+		),
 	}
+	if task.Link.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: task.Link}))
+	}
+
+	ctx, span := otel.Tracer(tracerName).Start(context.Background(), "transcode", opts...)
+	defer span.End()
+
+	if err := p.transcodeService.ProcessTranscode(ctx, task); err != nil {
+		log.Error("Failed to process transcode", sl.Err(err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// Saturation reports the tasks waiting for a worker and how many the queue holds before AddTask blocks
+func (p *WorkerPool) Saturation() (queued int, capacity int) {
+	return len(p.taskQueue), cap(p.taskQueue)
 }
 
 // AddTask adds a task to the task queue
 func (p *WorkerPool) AddTask(task TranscodeTask) {
+	p.metrics.TranscodeQueue.Inc()
 	p.taskQueue <- task
 }
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-fitness/external/apperr"
+	"go-fitness/external/config"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/db/query"
+	"go-fitness/external/logger/logctx"
+	"go-fitness/external/logger/sl"
+	"go-fitness/internal/api/event"
+	"go-fitness/internal/api/types"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookIDHeader        = "X-Webhook-ID"
+
+	defaultWebhookTimeout = 10 * time.Second
+)
+
+type WebhookRepository interface {
+	LogDelivery(context.Context, types.WebhookDelivery) error
+	GetDeliveries(context.Context, filter.Filter) ([]types.WebhookDelivery, int64, error)
+}
+
+// WebhookBody is the JSON posted to subscribers, ID is the outbox event id and stays the same across retries
+type WebhookBody struct {
+	ID        int64           `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// SignWebhook returns the hex HMAC-SHA256 of "timestamp.body" with the subscription secret
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookSink delivers the subscribed domain events of one webhook, retries are left to the outbox dispatcher
+type WebhookSink struct {
+	log    *slog.Logger
+	hook   config.Webhook
+	client *http.Client
+	repo   WebhookRepository
+}
+
+func NewWebhookSink(
+	log *slog.Logger,
+	hook config.Webhook,
+	client *http.Client,
+	repo WebhookRepository,
+) *WebhookSink {
+	if hook.Timeout <= 0 {
+		hook.Timeout = defaultWebhookTimeout
+	}
+
+	return &WebhookSink{
+		log:    log,
+		hook:   hook,
+		client: client,
+		repo:   repo,
+	}
+}
+
+// NewWebhookSinks creates one sink per configured webhook so each subscription is retried on its own
+func NewWebhookSinks(
+	log *slog.Logger,
+	cfg *config.Config,
+	repo WebhookRepository,
+) []event.Sink {
+	sinks := make([]event.Sink, 0, len(cfg.Webhooks))
+	for _, hook := range cfg.Webhooks {
+		sinks = append(sinks, NewWebhookSink(log, hook, &http.Client{}, repo))
+	}
+
+	return sinks
+}
+
+func (s *WebhookSink) Name() string {
+	return "webhook:" + s.hook.Name
+}
+
+func (s *WebhookSink) Accepts(name string) bool {
+	return slices.Contains(s.hook.Events, name) || slices.Contains(s.hook.Events, "*")
+}
+
+func (s *WebhookSink) Deliver(ctx context.Context, e types.OutboxEvent) error {
+	const op string = "WebhookSink.Deliver"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.String("webhook", s.hook.Name),
+		sl.Int64("event_id", e.ID),
+		sl.String("event", e.Name),
+	)
+
+	body, err := json.Marshal(WebhookBody{
+		ID:        e.ID,
+		Event:     e.Name,
+		CreatedAt: e.CreatedAt,
+		Data:      e.Payload,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	started := time.Now()
+
+	statusCode, err := s.post(ctx, e, body, started.Unix())
+
+	delivery := types.WebhookDelivery{
+		EventID:    e.ID,
+		Webhook:    s.hook.Name,
+		URL:        s.hook.URL,
+		Event:      e.Name,
+		DurationMS: time.Since(started).Milliseconds(),
+		CreatedAt:  started,
+	}
+
+	if statusCode != 0 {
+		delivery.StatusCode = &statusCode
+	}
+
+	if err != nil {
+		message := err.Error()
+		delivery.Error = &message
+	}
+
+	if logErr := s.repo.LogDelivery(ctx, delivery); logErr != nil {
+		log.Error("failed to log webhook delivery", sl.Err(logErr))
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("webhook delivered", sl.Int("status_code", statusCode))
+
+	return nil
+}
+
+func (s *WebhookSink) post(ctx context.Context, e types.OutboxEvent, body []byte, timestamp int64) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.hook.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, e.Name)
+	req.Header.Set(WebhookIDHeader, strconv.FormatInt(e.ID, 10))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(s.hook.Secret, timestamp, body))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	// drain so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
+
+type WebhookService struct {
+	log  *slog.Logger
+	cfg  *config.Config
+	repo WebhookRepository
+}
+
+func NewWebhookService(
+	log *slog.Logger,
+	cfg *config.Config,
+	repo WebhookRepository,
+) *WebhookService {
+	return &WebhookService{
+		log:  log,
+		cfg:  cfg,
+		repo: repo,
+	}
+}
+
+// Webhooks lists the configured subscriptions without their secrets
+func (s *WebhookService) Webhooks() []types.Webhook {
+	hooks := make([]types.Webhook, 0, len(s.cfg.Webhooks))
+	for _, hook := range s.cfg.Webhooks {
+		hooks = append(hooks, types.Webhook{
+			Name:   hook.Name,
+			URL:    hook.URL,
+			Events: hook.Events,
+		})
+	}
+
+	return hooks
+}
+
+func (s *WebhookService) Deliveries(ctx context.Context) ([]types.WebhookDelivery, int64, error) {
+	const op string = "WebhookService.Deliveries"
+
+	f := filter.FromContext(ctx)
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Any("filter", f),
+	)
+
+	deliveries, total, err := s.repo.GetDeliveries(ctx, f)
+	if err != nil {
+		if errors.Is(err, query.ErrUnknownFilter) || errors.Is(err, query.ErrUnknownSort) {
+			log.Warn("invalid filter", sl.Err(err))
+			return nil, 0, apperr.BadRequest("invalid_filter")
+		}
+
+		log.Error("failed to get webhook deliveries", sl.Err(err))
+		return nil, 0, apperr.Internal("failed_to_get_list")
+	}
+
+	return deliveries, total, nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"go-fitness/external/config"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/internal/api/event"
+	"go-fitness/internal/api/types"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type deliveryLog struct {
+	mu         sync.Mutex
+	deliveries []types.WebhookDelivery
+}
+
+func (l *deliveryLog) LogDelivery(_ context.Context, delivery types.WebhookDelivery) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.deliveries = append(l.deliveries, delivery)
+	return nil
+}
+
+func (l *deliveryLog) GetDeliveries(context.Context, filter.Filter) ([]types.WebhookDelivery, int64, error) {
+	return l.deliveries, int64(len(l.deliveries)), nil
+}
+
+func newTestEvent(t *testing.T) types.OutboxEvent {
+	t.Helper()
+
+	e, err := event.NewOutboxEvent(event.VideoProcessed, event.VideoPayload{VideoID: 7, UUID: "abc", Status: "processed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.ID = 42
+
+	return e
+}
+
+func TestWebhookSinkDeliversSignedBody(t *testing.T) {
+	const secret = "s3cret"
+
+	var (
+		gotBody    []byte
+		gotHeaders http.Header
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotHeaders = r.Header.Clone()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	logs := &deliveryLog{}
+	sink := NewWebhookSink(slog.New(slog.NewTextHandler(io.Discard, nil)), config.Webhook{
+		Name:   "backend",
+		URL:    server.URL,
+		Secret: secret,
+		Events: []string{event.VideoProcessed},
+	}, server.Client(), logs)
+
+	if !sink.Accepts(event.VideoProcessed) || sink.Accepts(event.VideoFailed) {
+		t.Fatal("sink must only accept subscribed events")
+	}
+
+	if err := sink.Deliver(context.Background(), newTestEvent(t)); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+
+	timestamp, err := strconv.ParseInt(gotHeaders.Get(WebhookTimestampHeader), 10, 64)
+	if err != nil {
+		t.Fatalf("timestamp header: %v", err)
+	}
+
+	want := "sha256=" + SignWebhook(secret, timestamp, gotBody)
+	if got := gotHeaders.Get(WebhookSignatureHeader); got != want {
+		t.Fatalf("signature = %q, want %q", got, want)
+	}
+
+	if got := gotHeaders.Get(WebhookEventHeader); got != event.VideoProcessed {
+		t.Fatalf("event header = %q", got)
+	}
+
+	var body WebhookBody
+	if err = json.Unmarshal(gotBody, &body); err != nil {
+		t.Fatal(err)
+	}
+
+	if body.ID != 42 || body.Event != event.VideoProcessed || !strings.Contains(string(body.Data), `"uuid":"abc"`) {
+		t.Fatalf("unexpected body %s", gotBody)
+	}
+
+	if len(logs.deliveries) != 1 || logs.deliveries[0].StatusCode == nil || *logs.deliveries[0].StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected delivery log %+v", logs.deliveries)
+	}
+}
+
+func TestWebhookSinkFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	logs := &deliveryLog{}
+	sink := NewWebhookSink(slog.New(slog.NewTextHandler(io.Discard, nil)), config.Webhook{
+		Name:    "backend",
+		URL:     server.URL,
+		Events:  []string{"*"},
+		Timeout: time.Second,
+	}, server.Client(), logs)
+
+	if err := sink.Deliver(context.Background(), newTestEvent(t)); err == nil {
+		t.Fatal("expected an error for a 502 response")
+	}
+
+	if len(logs.deliveries) != 1 || logs.deliveries[0].Error == nil {
+		t.Fatalf("failed delivery must be logged with its error, got %+v", logs.deliveries)
+	}
+}
@@ -2,104 +2,259 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"go-fitness/external/apperr"
+	"go-fitness/external/ctx/filter"
+	"go-fitness/external/db/query"
+	"go-fitness/external/logger/logctx"
 	"go-fitness/external/logger/sl"
 	"go-fitness/internal/api/data"
+	"go-fitness/internal/api/enum"
 	"go-fitness/internal/api/types"
 	"log/slog"
+	"time"
 )
 
 type WorkoutService struct {
-	log          *slog.Logger
-	workoutRepo  WorkoutRepository
-	videoService VideoServiceInterface
+	log         *slog.Logger
+	workoutRepo WorkoutRepository
+	attachments VideoAttacher
 }
 
 type VideoServiceInterface interface {
-	ProcessUpload(ctx context.Context, data data.VideoData) (int64, error)
+	PrepareUpload(ctx context.Context, data data.VideoData) (data.PreparedUpload, error)
+	StoreUpload(ctx context.Context, upload data.PreparedUpload) (int64, error)
+	DiscardUpload(ctx context.Context, upload data.PreparedUpload)
+	Release(ctx context.Context, videoID int64) error
 }
 
 type WorkoutRepository interface {
-	GetWorkoutByName(context.Context, string) (types.Workout, error)
+	GetByID(context.Context, int64) (types.Workout, error)
 	Create(context.Context, types.Workout) (int64, error)
 	AddWorkoutToProgramMonth(context.Context, int64, int64) error
 	CheckIfNameExists(context.Context, string) bool
 	Update(context.Context, int64, types.Workout) error
+	SoftDelete(context.Context, int64) error
+	Restore(context.Context, int64) error
+	GetTrashedBefore(context.Context, time.Time) ([]types.Workout, error)
+	ForceDelete(context.Context, int64) error
+	GetList(context.Context, filter.Filter) ([]types.Workout, int64, error)
+	Reorder(context.Context, []int64) error
+	GetIDsByVideoID(context.Context, int64) ([]int64, error)
 }
 
 func NewWorkoutService(
 	log *slog.Logger,
 	workoutRepo WorkoutRepository,
-	videoService VideoServiceInterface,
+	attachments VideoAttacher,
 ) *WorkoutService {
 	return &WorkoutService{
-		log:          log,
-		workoutRepo:  workoutRepo,
-		videoService: videoService,
+		log:         log,
+		workoutRepo: workoutRepo,
+		attachments: attachments,
 	}
 }
 
 func (s *WorkoutService) ProcessWorkout(ctx context.Context, data data.WorkoutData) error {
 	const op string = "WorkoutService.ProcessWorkout"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
 	)
 
 	if ok := s.workoutRepo.CheckIfNameExists(ctx, data.Name); ok {
 		log.Warn("workout already exists")
-		return errors.New("workout_already_exists")
+		return apperr.Conflict("workout_already_exists")
 	}
 
-	if data.VideoData == nil {
-		log.Error("video data is nil")
-		return errors.New("invalid_video_data")
-	}
-
-	videoID, err := s.videoService.ProcessUpload(ctx, *data.VideoData)
-	if err != nil {
-		log.Error("failed to process upload", sl.Err(err))
-		return err
-	}
+	_, err := s.attachments.Create(ctx, enum.AttachmentOwnerWorkout, data.VideoData, func(ctx context.Context, videoID int64) (int64, error) {
+		return s.Store(ctx, data, videoID)
+	})
 
-	return s.StoreOrUpdate(ctx, data, videoID)
+	return err
 }
 
-func (s *WorkoutService) StoreOrUpdate(ctx context.Context, data data.WorkoutData, videoID int64) error {
-	const op string = "WorkoutService.StoreOrUpdate"
+func (s *WorkoutService) Store(ctx context.Context, data data.WorkoutData, videoID int64) (int64, error) {
+	const op string = "WorkoutService.Store"
 
-	log := s.log.With(
+	log := logctx.Logger(ctx, s.log).With(
 		sl.String("op", op),
-		sl.Any("data", data),
+		sl.String("name", data.Name),
 		sl.Int64("video_id", videoID),
 	)
 
-	workout := types.Workout{
+	workoutID, err := s.workoutRepo.Create(ctx, types.Workout{
 		Name:        data.Name,
 		Description: data.Description,
 		VideoID:     videoID,
+	})
+	if err != nil {
+		log.Error("failed to create workout", sl.Err(err))
+		return 0, apperr.Internal("failed_to_create_workout")
 	}
 
-	w, err := s.workoutRepo.GetWorkoutByName(ctx, data.Name)
-	if err == nil {
-		if err = s.workoutRepo.Update(ctx, w.ID, workout); err != nil {
-			log.Error("failed to update workout", sl.Err(err))
-			return errors.New("failed_to_update_workout")
+	if data.ProgramMonthID != nil {
+		if err = s.workoutRepo.AddWorkoutToProgramMonth(ctx, workoutID, *data.ProgramMonthID); err != nil {
+			log.Error("failed to add workout to program month", sl.Err(err))
+			return 0, apperr.Internal("failed_to_add_workout_to_program_month")
 		}
-	} else {
-		workoutID, err := s.workoutRepo.Create(ctx, workout)
-		if err != nil {
-			log.Error("failed to create workout", sl.Err(err))
-			return errors.New("failed_to_create_workout")
+	}
+
+	return workoutID, nil
+}
+
+func (s *WorkoutService) Get(ctx context.Context, id int64) (types.Workout, error) {
+	const op string = "WorkoutService.Get"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("workout_id", id),
+	)
+
+	workout, err := s.workoutRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn("workout not found")
+			return workout, apperr.NotFound("workout_not_found")
 		}
 
-		if data.ProgramMonthID != nil {
-			if err = s.workoutRepo.AddWorkoutToProgramMonth(ctx, workoutID, *data.ProgramMonthID); err != nil {
-				log.Error("failed to add workout to program month", sl.Err(err))
-				return errors.New("failed_to_add_workout_to_program_month")
-			}
+		log.Error("failed to get workout", sl.Err(err))
+		return workout, apperr.Internal("failed_to_get_workout")
+	}
+
+	return workout, nil
+}
+
+// Update changes the name and description of the workout, the video is kept
+func (s *WorkoutService) Update(ctx context.Context, id int64, data data.WorkoutData) error {
+	const op string = "WorkoutService.Update"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("workout_id", id),
+	)
+
+	workout, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if workout.Name != data.Name && s.workoutRepo.CheckIfNameExists(ctx, data.Name) {
+		log.Warn("workout name already taken", sl.String("name", data.Name))
+		return apperr.Conflict("workout_already_exists")
+	}
+
+	workout.Name = data.Name
+	workout.Description = data.Description
+
+	if err = s.workoutRepo.Update(ctx, id, workout); err != nil {
+		log.Error("failed to update workout", sl.Err(err))
+		return apperr.Internal("failed_to_update_workout")
+	}
+
+	return nil
+}
+
+// ReplaceVideo uploads a new video for the workout in the role and releases the previous one
+func (s *WorkoutService) ReplaceVideo(ctx context.Context, id int64, role enum.AttachmentRole, videoData *data.VideoData) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+
+	_, err := s.attachments.Attach(ctx, enum.AttachmentOwnerWorkout, id, role, videoData)
+
+	return err
+}
+
+// Reorder stores the given order of workouts, the first id gets position 0
+func (s *WorkoutService) Reorder(ctx context.Context, ids []int64) error {
+	const op string = "WorkoutService.Reorder"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int("count", len(ids)),
+	)
+
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			log.Warn("duplicate workout id", sl.Int64("workout_id", id))
+			return apperr.BadRequest("invalid_request")
+		}
+		seen[id] = true
+	}
+
+	if err := s.workoutRepo.Reorder(ctx, ids); err != nil {
+		log.Error("failed to reorder workouts", sl.Err(err))
+		return apperr.Internal("failed_to_reorder")
+	}
+
+	return nil
+}
+
+func (s *WorkoutService) SoftDelete(ctx context.Context, id int64) error {
+	const op string = "WorkoutService.SoftDelete"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("workout_id", id),
+	)
+
+	if err := s.workoutRepo.SoftDelete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn("workout not found or already deleted")
+			return apperr.NotFound("workout_not_found")
+		}
+
+		log.Error("failed to soft delete workout", sl.Err(err))
+		return apperr.Internal("failed_to_delete")
+	}
+
+	return nil
+}
+
+func (s *WorkoutService) Restore(ctx context.Context, id int64) error {
+	const op string = "WorkoutService.Restore"
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Int64("workout_id", id),
+	)
+
+	if err := s.workoutRepo.Restore(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn("workout not found or not deleted")
+			return apperr.NotFound("workout_not_found")
 		}
+
+		log.Error("failed to restore workout", sl.Err(err))
+		return apperr.Internal("failed_to_restore")
 	}
 
 	return nil
 }
+
+func (s *WorkoutService) List(ctx context.Context) ([]types.Workout, int64, error) {
+	const op string = "WorkoutService.List"
+
+	f := filter.FromContext(ctx)
+
+	log := logctx.Logger(ctx, s.log).With(
+		sl.String("op", op),
+		sl.Any("filter", f),
+	)
+
+	workouts, total, err := s.workoutRepo.GetList(ctx, f)
+	if err != nil {
+		if errors.Is(err, query.ErrUnknownFilter) || errors.Is(err, query.ErrUnknownSort) {
+			log.Warn("invalid filter", sl.Err(err))
+			return nil, 0, apperr.BadRequest("invalid_filter")
+		}
+
+		log.Error("failed to get workouts", sl.Err(err))
+		return nil, 0, apperr.Internal("failed_to_get_list")
+	}
+
+	return workouts, total, nil
+}
@@ -0,0 +1,17 @@
+package types
+
+import (
+	"go-fitness/internal/api/enum"
+	"time"
+)
+
+// VideoAttachment links a video to a piece of content in a role, an owner has at most one video per role
+type VideoAttachment struct {
+	ID        int64                `json:"id"`
+	VideoID   int64                `json:"video_id"`
+	OwnerType enum.AttachmentOwner `json:"owner_type"`
+	OwnerID   int64                `json:"owner_id"`
+	Role      enum.AttachmentRole  `json:"role"`
+	CreatedAt time.Time            `json:"created_at"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
@@ -0,0 +1,24 @@
+package types
+
+import (
+	"go-fitness/internal/api/enum"
+	"time"
+)
+
+type WorkoutCompletion struct {
+	ID        int64                 `json:"id"`
+	UserID    int64                 `json:"-"`
+	WorkoutID int64                 `json:"workout_id"`
+	Source    enum.CompletionSource `json:"source"`
+	CreatedAt time.Time             `json:"created_at"`
+}
+
+// ExpEntry is one row of the experience ledger, the total of a user is the sum of its entries
+type ExpEntry struct {
+	ID           int64     `json:"id"`
+	UserID       int64     `json:"-"`
+	Amount       int       `json:"amount"`
+	Reason       string    `json:"reason"`
+	CompletionID *int64    `json:"completion_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,30 @@
+package types
+
+import (
+	"go-fitness/internal/api/enum"
+	"time"
+)
+
+// GCVideo is a video row as seen by the garbage collector
+type GCVideo struct {
+	ID         int64            `json:"id"`
+	UUID       string           `json:"uuid"`
+	HashName   string           `json:"hash_name"`
+	Status     enum.VideoStatus `json:"status"`
+	Referenced bool             `json:"-"`
+	DeletedAt  *time.Time       `json:"deleted_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+}
+
+// GCReport describes one garbage collection run, in dry run nothing listed in it was removed
+type GCReport struct {
+	DryRun         bool      `json:"dry_run"`
+	StartedAt      time.Time `json:"started_at"`
+	FinishedAt     time.Time `json:"finished_at"`
+	OrphanFolders  []string  `json:"orphan_folders"`
+	MissingFolders []GCVideo `json:"missing_folders"`
+	Unreferenced   []GCVideo `json:"unreferenced"`
+	RemovedFolders int       `json:"removed_folders"`
+	PurgedVideos   int       `json:"purged_videos"`
+	Errors         []string  `json:"errors"`
+}
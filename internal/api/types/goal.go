@@ -3,9 +3,10 @@ package types
 import "time"
 
 type Goal struct {
-	ID        int
-	Name      string
-	VideoID   *int64
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	VideoID   *int64     `json:"video_id"`
+	DeletedAt *time.Time `json:"deleted_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
 }
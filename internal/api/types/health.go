@@ -0,0 +1,27 @@
+package types
+
+import "time"
+
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+	HealthFail     = "fail"
+)
+
+// HealthCheck is the result of one readiness check, a failed check that is not Critical only degrades the service
+type HealthCheck struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	Critical   bool    `json:"critical"`
+	DurationMs float64 `json:"duration_ms"`
+	Detail     string  `json:"detail,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// HealthReport is ok when every check passed, degraded when only non critical ones failed and fail otherwise
+type HealthReport struct {
+	Status     string        `json:"status"`
+	CheckedAt  time.Time     `json:"checked_at"`
+	DurationMs float64       `json:"duration_ms"`
+	Checks     []HealthCheck `json:"checks,omitempty"`
+}
@@ -0,0 +1,26 @@
+package types
+
+import (
+	"go-fitness/internal/api/enum"
+	"time"
+)
+
+// JobRun is one run of a scheduled job, kept as history
+type JobRun struct {
+	ID         int64             `json:"id"`
+	Job        string            `json:"job"`
+	Trigger    enum.JobTrigger   `json:"trigger"`
+	Status     enum.JobRunStatus `json:"status"`
+	Error      *string           `json:"error"`
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt *time.Time        `json:"finished_at"`
+}
+
+// Job is a registered job as shown to admins, Schedule is empty for jobs that only run manually
+type Job struct {
+	Name     string     `json:"name"`
+	Schedule string     `json:"schedule"`
+	NextRun  *time.Time `json:"next_run"`
+	Running  bool       `json:"running"`
+	LastRun  *JobRun    `json:"last_run"`
+}
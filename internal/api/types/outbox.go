@@ -0,0 +1,22 @@
+package types
+
+import (
+	"encoding/json"
+	"go-fitness/internal/api/enum"
+	"time"
+)
+
+// OutboxEvent is a domain event written together with the state change it describes,
+// DeliveredTo lists the sinks that already received it so a retry only goes to the others
+type OutboxEvent struct {
+	ID            int64             `json:"id"`
+	Name          string            `json:"name"`
+	Payload       json.RawMessage   `json:"payload"`
+	Status        enum.OutboxStatus `json:"status"`
+	Attempts      int               `json:"attempts"`
+	NextAttemptAt time.Time         `json:"next_attempt_at"`
+	LastError     *string           `json:"last_error"`
+	DeliveredTo   []string          `json:"delivered_to"`
+	DeliveredAt   *time.Time        `json:"delivered_at"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
@@ -3,9 +3,9 @@ package types
 import "time"
 
 type Portal struct {
-	ID        int
-	Name      string
-	VideoID   int64
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	VideoID   int64     `json:"video_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -1,25 +1,40 @@
 package types
 
 type Program struct {
-	ID       int64
-	Name     string
-	GoalID   int64
-	LevelID  int64
-	PeriodID int64
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	GoalID   int64  `json:"goal_id"`
+	LevelID  int64  `json:"level_id"`
+	PeriodID int64  `json:"period_id"`
 }
 
 type Level struct {
-	ID   int64
-	Name string
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
 }
 
 type Period struct {
-	ID   int64
-	Name string
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
 }
 
 type ProgramMonth struct {
-	ID        int64
-	ProgramID int64
-	Month     int
+	ID        int64 `json:"id"`
+	ProgramID int64 `json:"program_id"`
+	Month     int   `json:"month"`
+}
+
+// ProgramTree is a program with its goal, level, period and every month with its workouts
+type ProgramTree struct {
+	Program
+	GoalName   string             `json:"goal_name"`
+	LevelName  string             `json:"level_name"`
+	PeriodName string             `json:"period_name"`
+	Months     []ProgramMonthTree `json:"months"`
+}
+
+// ProgramMonthTree holds the workouts of a month, Workout.Position is the position within the month
+type ProgramMonthTree struct {
+	ProgramMonth
+	Workouts []Workout `json:"workouts"`
 }
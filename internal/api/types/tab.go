@@ -3,10 +3,11 @@ package types
 import "time"
 
 type Tab struct {
-	ID          int
-	Name        string
-	Description string
-	VideoID     *int64
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	VideoID     *int64     `json:"video_id"`
+	DeletedAt   *time.Time `json:"deleted_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
 }
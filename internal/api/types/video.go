@@ -6,31 +6,54 @@ import (
 )
 
 type Video struct {
-	ID        int64
-	UUID      string
-	HashName  string
-	Status    enum.VideoStatus
-	Duration  float64
-	Poster    *string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID        int64            `json:"id"`
+	UUID      string           `json:"uuid"`
+	HashName  string           `json:"hash_name"`
+	Status    enum.VideoStatus `json:"status"`
+	Duration  float64          `json:"duration"`
+	Poster    *string          `json:"poster"`
+	DeletedAt *time.Time       `json:"deleted_at"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
 }
 
 type VideoPosition struct {
-	ID        int64
-	UserID    int64
-	VideoID   int64
-	Position  float64
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID          int64      `json:"-"`
+	UserID      int64      `json:"-"`
+	VideoID     int64      `json:"-"`
+	Position    float64    `json:"position"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CreatedAt   time.Time  `json:"-"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// ContinueWatching is an unfinished video of a user with the workout that owns it, if any
+type ContinueWatching struct {
+	VideoUUID   string    `json:"video_uuid"`
+	Poster      *string   `json:"poster"`
+	Duration    float64   `json:"duration"`
+	Position    float64   `json:"position"`
+	WorkoutID   *int64    `json:"workout_id"`
+	WorkoutName *string   `json:"workout_name"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 type Workout struct {
-	ID          int64
-	Name        string
-	Description string
-	VideoID     int64
-	DeletedAt   *time.Time
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	VideoID     int64      `json:"video_id"`
+	VideoUUID   string     `json:"video_uuid,omitempty"`
+	Position    int        `json:"position"`
+	DeletedAt   *time.Time `json:"deleted_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// VideoIssue is something wrong with the files of a processed video
+type VideoIssue struct {
+	VideoID  int64  `json:"video_id"`
+	UUID     string `json:"uuid"`
+	HashName string `json:"hash_name"`
+	Problem  string `json:"problem"`
 }
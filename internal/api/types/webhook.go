@@ -0,0 +1,23 @@
+package types
+
+import "time"
+
+// WebhookDelivery is one attempt to POST a domain event to a webhook subscription
+type WebhookDelivery struct {
+	ID         int64     `json:"id"`
+	EventID    int64     `json:"event_id"`
+	Webhook    string    `json:"webhook"`
+	URL        string    `json:"url"`
+	Event      string    `json:"event"`
+	StatusCode *int      `json:"status_code"`
+	Error      *string   `json:"error"`
+	DurationMS int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Webhook is a configured subscription as shown to admins, without its secret
+type Webhook struct {
+	Name   string   `json:"name"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+}
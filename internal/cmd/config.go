@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"go-fitness/external/config"
+	"gopkg.in/yaml.v3"
+)
+
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration the service would start with",
+	}
+
+	var redacted bool
+
+	printCmd := &cobra.Command{
+		Use:   "print",
+		Short: "Print the loaded configuration as yaml with the defaults and environment applied",
+		Long: "Print loads .env and the config file the way the server does and prints the result,\n" +
+			"then reports the validation problems and fails when there are any.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			// validated first, it normalizes values like the resolution list
+			validateErr := cfg.Validate()
+
+			printed := cfg
+			if redacted {
+				printed = cfg.Redacted()
+			}
+
+			enc := yaml.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent(2)
+			if err = enc.Encode(printed); err != nil {
+				return err
+			}
+
+			return validateErr
+		},
+	}
+
+	printCmd.Flags().BoolVar(&redacted, "redacted", false, "mask the secrets, for sharing the output")
+
+	cmd.AddCommand(printCmd, &cobra.Command{
+		Use:   "validate",
+		Short: "Check the configuration and list every problem",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := config.NewConfig(); err != nil {
+				return err
+			}
+
+			cmd.Println("config is valid")
+
+			return nil
+		},
+	})
+
+	return cmd
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"github.com/spf13/cobra"
+	"go-fitness/internal/api/service"
+	"go.uber.org/fx"
+)
+
+func newGCCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Remove orphaned video folders and videos nothing references, and report rows whose folder is missing",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var gc service.GarbageCollector
+
+			return run(cmd, func(ctx context.Context) error {
+				report, err := gc.Run(ctx, dryRun)
+				if err != nil {
+					return err
+				}
+
+				return printJSON(cmd, report)
+			}, fx.Populate(&gc))
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", true, "only report what would be removed")
+
+	return cmd
+}
+
+func newPostersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "posters",
+		Short: "Manage video posters",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "backfill",
+		Short: "Create the missing posters of processed videos from their segments",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var posters service.PosterBackfiller
+
+			return run(cmd, func(ctx context.Context) error {
+				return posters.CreatePosterFromUploadedTsFiles(ctx)
+			}, fx.Populate(&posters))
+		},
+	})
+
+	return cmd
+}
+
+func newDriveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "drive",
+		Short: "Work with the Google Drive video folder",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "import",
+		Short: "Create workouts from the videos in the fitness-videos folder, token.json must hold an authorized token",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var drive service.DriveImporter
+
+			queue := &syncQueue{}
+
+			return run(cmd, func(ctx context.Context) error {
+				drive.ProcessParse(ctx)
+
+				cmd.Printf("import finished, %d videos failed to transcode\n", queue.failed)
+
+				return nil
+			}, fx.Populate(&drive), withSyncQueue(queue))
+		},
+	})
+
+	return cmd
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"github.com/spf13/cobra"
+	"go-fitness/external/db/migrate"
+	"go.uber.org/fx"
+)
+
+func newMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply the pending database migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var migrator *migrate.Migrator
+
+			return run(cmd, func(ctx context.Context) error {
+				applied, err := migrator.Up(ctx)
+				if err != nil {
+					return err
+				}
+
+				cmd.Printf("%d migrations applied\n", applied)
+
+				return nil
+			}, fx.Populate(&migrator))
+		},
+	}
+
+	var steps int
+
+	down := &cobra.Command{
+		Use:   "down",
+		Short: "Roll back the latest applied migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var migrator *migrate.Migrator
+
+			return run(cmd, func(ctx context.Context) error {
+				rolledBack, err := migrator.Down(ctx, steps)
+				if err != nil {
+					return err
+				}
+
+				cmd.Printf("%d migrations rolled back\n", rolledBack)
+
+				return nil
+			}, fx.Populate(&migrator))
+		},
+	}
+
+	down.Flags().IntVar(&steps, "steps", 1, "how many migrations to roll back")
+
+	cmd.AddCommand(down, &cobra.Command{
+		Use:   "status",
+		Short: "List the migrations and when they were applied",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var migrator *migrate.Migrator
+
+			return run(cmd, func(ctx context.Context) error {
+				statuses, err := migrator.Status(ctx)
+				if err != nil {
+					return err
+				}
+
+				return printJSON(cmd, statuses)
+			}, fx.Populate(&migrator))
+		},
+	})
+
+	return cmd
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/spf13/cobra"
+	"go-fitness/internal/api"
+	"go.uber.org/fx"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// NewRootCmd builds the fitness command line, every command runs on the same fx wiring as the server
+func NewRootCmd() *cobra.Command {
+	var configPath string
+
+	root := &cobra.Command{
+		Use:          "fitness",
+		Short:        "Video and workout service of go-fitness",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if configPath != "" {
+				return os.Setenv("CONFIG_PATH", configPath)
+			}
+
+			return nil
+		},
+	}
+
+	root.PersistentFlags().StringVar(&configPath, "config", "", "path to the config file, overrides CONFIG_PATH")
+
+	root.AddCommand(
+		newServeCmd(),
+		newMigrateCmd(),
+		newTranscodeCmd(),
+		newReprocessCmd(),
+		newGCCmd(),
+		newPostersCmd(),
+		newDriveCmd(),
+		newVideosCmd(),
+		newConfigCmd(),
+	)
+
+	return root
+}
+
+// Execute runs the command line and exits with 1 when the command fails
+func Execute() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := NewRootCmd().ExecuteContext(ctx); err != nil {
+		stop()
+		os.Exit(1)
+	}
+}
+
+// run starts the app without the http server and the background workers,
+// calls do once the lifecycle hooks ran and stops the app afterwards.
+// Dependencies reach do through fx.Populate in opts
+func run(cmd *cobra.Command, do func(ctx context.Context) error, opts ...fx.Option) (err error) {
+	app := fx.New(
+		api.Options(),
+		fx.Options(opts...),
+		fx.NopLogger,
+	)
+	if err = app.Err(); err != nil {
+		return err
+	}
+
+	startCtx, cancel := context.WithTimeout(cmd.Context(), app.StartTimeout())
+	defer cancel()
+
+	if err = app.Start(startCtx); err != nil {
+		return err
+	}
+
+	defer func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), app.StopTimeout())
+		defer cancel()
+
+		if stopErr := app.Stop(stopCtx); stopErr != nil && err == nil {
+			err = stopErr
+		}
+	}()
+
+	return do(cmd.Context())
+}
+
+// printJSON writes v to the standard output of the command as indented json
+func printJSON(cmd *cobra.Command, v any) error {
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"go-fitness/internal/api"
+)
+
+func newServeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Run the http server with the transcoding workers, outbox dispatcher and scheduler",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app := api.NewApp()
+			if err := app.Err(); err != nil {
+				return err
+			}
+
+			app.Run()
+
+			return nil
+		},
+	}
+}
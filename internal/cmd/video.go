@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"go-fitness/internal/api/data"
+	"go-fitness/internal/api/enum"
+	"go-fitness/internal/api/service/video"
+	"go.uber.org/fx"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+)
+
+// syncQueue transcodes every task as soon as it is queued, so a command returns once the videos are done
+type syncQueue struct {
+	transcoder video.Transcoder
+	failed     int
+}
+
+func (q *syncQueue) AddTask(task video.TranscodeTask) {
+	if err := q.transcoder.ProcessTranscode(context.Background(), task); err != nil {
+		q.failed++
+	}
+}
+
+// withSyncQueue replaces the worker pool of the video service with q
+func withSyncQueue(q *syncQueue) fx.Option {
+	return fx.Decorate(func(transcoder video.Transcoder) video.TaskQueue {
+		q.transcoder = transcoder
+		return q
+	})
+}
+
+func newTranscodeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "transcode <file|uuid>",
+		Short: "Upload and transcode a local file as a new video, or transcode an existing video again",
+		Long: "Transcode uploads the file at the given path as a new video, or transcodes the video with the given uuid again.\n" +
+			"A new video belongs to nothing, so gc removes it after its grace period unless it gets attached.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var videos *video.VideoService
+
+			queue := &syncQueue{}
+
+			return run(cmd, func(ctx context.Context) error {
+				if info, err := os.Stat(args[0]); err == nil && !info.IsDir() {
+					file, err := os.Open(args[0])
+					if err != nil {
+						return err
+					}
+					defer file.Close()
+
+					videoID, err := videos.ProcessUpload(ctx, data.VideoData{
+						File: file,
+						Header: &multipart.FileHeader{
+							Filename: filepath.Base(args[0]),
+							Size:     info.Size(),
+						},
+					})
+					if err != nil {
+						return err
+					}
+
+					cmd.Printf("video %d uploaded\n", videoID)
+				} else if err = videos.Reprocess(ctx, args[0]); err != nil {
+					return err
+				}
+
+				if queue.failed > 0 {
+					return errors.New("transcoding failed")
+				}
+
+				cmd.Println("transcoded")
+
+				return nil
+			}, fx.Populate(&videos), withSyncQueue(queue))
+		},
+	}
+}
+
+func newReprocessCmd() *cobra.Command {
+	var status string
+
+	cmd := &cobra.Command{
+		Use:   "reprocess",
+		Short: "Transcode again every video in a status",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			videoStatus, ok := enum.ParseVideoStatus(status)
+			if !ok {
+				return fmt.Errorf("unknown status %q", status)
+			}
+
+			var videos *video.VideoService
+
+			queue := &syncQueue{}
+
+			return run(cmd, func(ctx context.Context) error {
+				queued, err := videos.ReprocessByStatus(ctx, videoStatus)
+				if err != nil {
+					return err
+				}
+
+				cmd.Printf("%d videos reprocessed, %d failed\n", queued-queue.failed, queue.failed)
+
+				if queue.failed > 0 {
+					return errors.New("transcoding failed")
+				}
+
+				return nil
+			}, fx.Populate(&videos), withSyncQueue(queue))
+		},
+	}
+
+	cmd.Flags().StringVar(&status, "status", enum.VideoStatusFailed.String(), "status of the videos to reprocess")
+
+	return cmd
+}
+
+func newVideosCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "videos",
+		Short: "Inspect the stored videos",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "verify",
+		Short: "Check that every processed video has its playlists, segments and poster on disk",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var videos *video.VideoService
+
+			return run(cmd, func(ctx context.Context) error {
+				issues, err := videos.Verify(ctx)
+				if err != nil {
+					return err
+				}
+
+				if err = printJSON(cmd, issues); err != nil {
+					return err
+				}
+
+				if len(issues) > 0 {
+					return fmt.Errorf("%d issues found", len(issues))
+				}
+
+				return nil
+			}, fx.Populate(&videos))
+		},
+	})
+
+	return cmd
+}